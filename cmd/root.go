@@ -21,6 +21,9 @@ using proper Git procedures.`,
 	Run: runGame,
 }
 
+// levelDir is a directory of extra level files (--levels)
+var levelDir string
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -30,10 +33,17 @@ func Execute() {
 }
 
 func init() {
-	// No flags needed - classic mode is now the default
+	rootCmd.Flags().StringVar(&levelDir, "levels", "", "directory of extra level files (YAML or JSON)")
 }
 
 func runGame(cmd *cobra.Command, args []string) {
+	if levelDir != "" {
+		if err := game.LoadLevelDir(levelDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
 	// Always use classic CLI interface
 	runClassicGame()
 }
@@ -67,6 +77,9 @@ func runClassicGame() {
 		prompt := "[SCP-████] $ "
 		if gameStarted && engine.State.IsInitialized {
 			prompt = fmt.Sprintf("[SCP-████:%s] $ ", engine.State.CurrentBranch)
+			if engine.State.CurrentWorktree != game.MainWorktreePath {
+				prompt = fmt.Sprintf("[SCP-████:%s @%s] $ ", engine.State.CurrentBranch, engine.State.CurrentWorktree)
+			}
 		}
 		rl.SetPrompt(prompt)
 
@@ -122,13 +135,21 @@ func runClassicGame() {
 			}
 			continue
 
-		case "brief", "briefing", "objective", "objectives":
+		case "brief", "briefing":
 			if gameStarted && engine.CurrentLevel != nil {
 				terminal.DisplayLevelIntro(engine.CurrentLevel)
 			} else {
 				terminal.DisplayError("No active containment protocol. Type 'start' to begin.")
 			}
 			continue
+
+		case "objective", "objectives":
+			if gameStarted && engine.CurrentLevel != nil {
+				terminal.DisplayObjectives(engine.CurrentLevel, engine.State)
+			} else {
+				terminal.DisplayError("No active containment protocol. Type 'start' to begin.")
+			}
+			continue
 		}
 
 		// Process game commands
@@ -141,6 +162,27 @@ func runClassicGame() {
 		result := engine.ProcessCommand(input)
 		terminal.DisplayCommandResult(result)
 
+		// Some commands need more input: an editor buffer (git commit without -m)
+		// or answers to prompts (git add -p / -i)
+		for result.Edit != nil || result.Prompt != nil {
+			if result.Edit != nil {
+				text, saved := runEditor(rl, terminal, result.Edit)
+				if !saved {
+					result = engine.CancelEdit(result.Edit)
+				} else {
+					result = engine.FinishEdit(result.Edit, text)
+				}
+			} else {
+				rl.SetPrompt(result.Prompt.Text)
+				answer, err := rl.Readline()
+				if err != nil { // Ctrl-D or Ctrl-C quits the prompt
+					answer = "q"
+				}
+				result = engine.AnswerPrompt(result.Prompt, answer)
+			}
+			terminal.DisplayCommandResult(result)
+		}
+
 		// Check for critical states
 		if engine.State.AnomalyLevel >= 100 {
 			terminal.DisplayError("CRITICAL CONTAINMENT BREACH!")
@@ -148,6 +190,12 @@ func runClassicGame() {
 			fmt.Println("\nGame Over. The anomaly has escaped containment.")
 			return
 		}
+		if engine.State.Sanity <= 0 {
+			terminal.DisplayError("RESEARCHER PSYCHOLOGICAL COLLAPSE!")
+			terminal.DisplayIncidentReport("Researcher sanity depleted; containment duties suspended")
+			fmt.Println("\nGame Over. The entity has broken your mind.")
+			return
+		}
 
 		// Check for level completion
 		if engine.IsLevelComplete() {
@@ -156,7 +204,7 @@ func runClassicGame() {
 			fmt.Printf("Score: %d\n", engine.State.Score)
 
 			nextLevel := engine.GetNextLevel()
-			if nextLevel > 0 && nextLevel <= 3 {
+			if nextLevel > 0 && game.GetLevel(nextLevel) != nil {
 				rl.SetPrompt(fmt.Sprintf("\nProceed to Level %d? (y/n): ", nextLevel))
 				response, err := rl.Readline()
 				if err == nil {
@@ -181,7 +229,7 @@ func runClassicGame() {
 		}
 
 		// Show status if something significant changed
-		if result.AnomalyDelta != 0 {
+		if result.AnomalyDelta != 0 || result.SanityDelta != 0 {
 			fmt.Println()
 			terminal.DisplayGameStatus(engine.State)
 		}
@@ -190,6 +238,14 @@ func runClassicGame() {
 
 // setupReadline configures readline with tab completion and command history
 func setupReadline(engine *game.Engine) (*readline.Instance, error) {
+	// Dynamic completion for the files and directories of the working tree
+	workingPaths := func(line string) []string {
+		if engine.State == nil || engine.State.WorkingDir == nil {
+			return []string{}
+		}
+		return engine.State.PathCompletions()
+	}
+
 	// Define completer for tab completion
 	completer := readline.NewPrefixCompleter(
 		// Meta commands
@@ -201,6 +257,55 @@ func setupReadline(engine *game.Engine) (*readline.Instance, error) {
 		readline.PcItem("objective"),
 		readline.PcItem("objectives"),
 		readline.PcItem("clear"),
+		readline.PcItem("pwd"),
+		readline.PcItem("cd",
+			readline.PcItemDynamic(func(line string) []string {
+				// Dynamic completion for worktree paths
+				if engine.State == nil || engine.State.Worktrees == nil {
+					return []string{}
+				}
+
+				var paths []string
+				for wtPath := range engine.State.Worktrees {
+					paths = append(paths, wtPath)
+				}
+				return paths
+			}),
+		),
+		readline.PcItem("edit",
+			readline.PcItemDynamic(func(line string) []string {
+				// Dynamic completion for working files and hook scripts
+				if engine.State == nil || engine.State.WorkingDir == nil {
+					return []string{}
+				}
+
+				files := engine.State.PathCompletions()
+				for _, hook := range []string{"pre-commit", "commit-msg", "pre-push"} {
+					files = append(files, game.HookDir+hook)
+				}
+				return files
+			}),
+		),
+		readline.PcItem("cat", readline.PcItemDynamic(workingPaths)),
+		readline.PcItem("ls",
+			readline.PcItem("-l"),
+			readline.PcItem("-a"),
+			readline.PcItem("-la"),
+			readline.PcItemDynamic(workingPaths),
+		),
+		readline.PcItem("echo"),
+		readline.PcItem("touch", readline.PcItemDynamic(workingPaths)),
+		readline.PcItem("rm",
+			readline.PcItem("-r"),
+			readline.PcItemDynamic(workingPaths),
+		),
+		readline.PcItem("mv", readline.PcItemDynamic(workingPaths)),
+		readline.PcItem("mkdir",
+			readline.PcItem("-p"),
+			readline.PcItemDynamic(workingPaths),
+		),
+		readline.PcItem("head", readline.PcItemDynamic(workingPaths)),
+		readline.PcItem("tail", readline.PcItemDynamic(workingPaths)),
 		readline.PcItem("quit"),
 		readline.PcItem("exit"),
 
@@ -209,19 +314,26 @@ func setupReadline(engine *game.Engine) (*readline.Instance, error) {
 			readline.PcItem("config",
 				readline.PcItem("user.name"),
 				readline.PcItem("user.email"),
+				readline.PcItem("--global"),
+				readline.PcItem("--get"),
+				readline.PcItem("--list"),
+				readline.PcItem("--unset"),
+				readline.PcItem("core.editor"),
+				readline.PcItem("init.defaultBranch"),
+				readline.PcItem("pull.rebase"),
+				readline.PcItem("merge.ff"),
 			),
 			readline.PcItem("init"),
 			readline.PcItem("add",
+				readline.PcItem("-p"),
+				readline.PcItem("-i"),
 				readline.PcItemDynamic(func(line string) []string {
 					// Dynamic completion for filenames
 					if engine.State == nil || engine.State.WorkingDir == nil {
 						return []string{}
 					}
 
-					var files []string
-					for filename := range engine.State.WorkingDir {
-						files = append(files, filename)
-					}
+					files := engine.State.PathCompletions()
 					// Also add common wildcards
 					files = append(files, ".", "*")
 					return files
@@ -230,14 +342,49 @@ func setupReadline(engine *game.Engine) (*readline.Instance, error) {
 			readline.PcItem("commit",
 				readline.PcItem("-m"),
 				readline.PcItem("-a"),
+				readline.PcItem("-F"),
+				readline.PcItem("--amend",
+					readline.PcItem("--no-edit"),
+				),
+				readline.PcItem("--allow-empty"),
+				readline.PcItem("-s"),
+				readline.PcItem("--trailer"),
+				readline.PcItem("--no-verify"),
 			),
 			readline.PcItem("status"),
-			readline.PcItem("diff"),
+			readline.PcItem("diff",
+				readline.PcItem("--cached"),
+			),
 			readline.PcItem("log",
 				readline.PcItem("-p"),
+				readline.PcItem("--oneline"),
+				readline.PcItem("--format=%(trailers)"),
+			),
+			readline.PcItem("show",
+				readline.PcItem("HEAD"),
+				readline.PcItem("HEAD~1"),
+			),
+			readline.PcItem("grep",
+				readline.PcItem("-n"),
+				readline.PcItem("-i"),
+				readline.PcItem("-l"),
+				readline.PcItem("-c"),
+				readline.PcItem("--cached"),
+			),
+			readline.PcItem("branch",
+				readline.PcItem("-d"),
+				readline.PcItem("-D"),
+				readline.PcItem("-m"),
+				readline.PcItem("-v"),
+				readline.PcItem("-vv"),
+				readline.PcItem("-a"),
+				readline.PcItem("-r"),
+				readline.PcItem("--merged"),
+				readline.PcItem("--no-merged"),
+				readline.PcItem("--contains"),
+				readline.PcItem("--set-upstream-to="),
+				readline.PcItem("--unset-upstream"),
 			),
-			readline.PcItem("show"),
-			readline.PcItem("branch"),
 			readline.PcItem("checkout",
 				readline.PcItemDynamic(func(line string) []string {
 					// Dynamic completion for branch names
@@ -269,6 +416,9 @@ func setupReadline(engine *game.Engine) (*readline.Instance, error) {
 				}),
 			),
 			readline.PcItem("merge",
+				readline.PcItem("--no-ff"),
+				readline.PcItem("--ff-only"),
+				readline.PcItem("--abort"),
 				readline.PcItemDynamic(func(line string) []string {
 					// Dynamic completion for branch names
 					if engine.State == nil || engine.State.Branches == nil {
@@ -284,6 +434,147 @@ func setupReadline(engine *game.Engine) (*readline.Instance, error) {
 					return branches
 				}),
 			),
+			readline.PcItem("remote",
+				readline.PcItem("-v"),
+				readline.PcItem("add"),
+				readline.PcItem("remove"),
+			),
+			readline.PcItem("push",
+				readline.PcItem("-u"),
+				readline.PcItem("--force"),
+				readline.PcItem("--no-verify"),
+				readline.PcItemDynamic(func(line string) []string {
+					// Dynamic completion for remote names
+					if engine.State == nil || engine.State.Remotes == nil {
+						return []string{}
+					}
+
+					var remotes []string
+					for name := range engine.State.Remotes {
+						remotes = append(remotes, name)
+					}
+					return remotes
+				}),
+			),
+			readline.PcItem("clean",
+				readline.PcItem("-n"),
+				readline.PcItem("-f"),
+				readline.PcItem("-fd"),
+				readline.PcItem("-X"),
+				readline.PcItem("-i"),
+			),
+			readline.PcItem("fetch"),
+			readline.PcItem("pull"),
+			readline.PcItem("tag",
+				readline.PcItem("-a"),
+				readline.PcItem("-d"),
+			),
+			readline.PcItem("shortlog",
+				readline.PcItem("-sn"),
+			),
+			readline.PcItem("describe",
+				readline.PcItem("--tags"),
+				readline.PcItem("--always"),
+			),
+			readline.PcItem("show-branch"),
+			readline.PcItem("format-patch",
+				readline.PcItem("-1"),
+				readline.PcItem("--stdout"),
+			),
+			readline.PcItem("am",
+				readline.PcItem("--3way"),
+				readline.PcItem("--continue"),
+				readline.PcItem("--skip"),
+				readline.PcItem("--abort"),
+			),
+			readline.PcItem("apply",
+				readline.PcItem("--check"),
+				readline.PcItem("--3way"),
+			),
+			readline.PcItem("submodule",
+				readline.PcItem("add"),
+				readline.PcItem("init"),
+				readline.PcItem("update",
+					readline.PcItem("--init"),
+					readline.PcItem("--remote"),
+				),
+				readline.PcItem("status"),
+				readline.PcItem("foreach"),
+			),
+			readline.PcItem("-C"),
+			readline.PcItem("reflog",
+				readline.PcItem("show"),
+				readline.PcItem("expire",
+					readline.PcItem("--expire=now"),
+					readline.PcItem("--all"),
+				),
+			),
+			readline.PcItem("fsck",
+				readline.PcItem("--unreachable"),
+				readline.PcItem("--no-reflogs"),
+			),
+			readline.PcItem("gc",
+				readline.PcItem("--prune=now"),
+			),
+			readline.PcItem("prune",
+				readline.PcItem("-n"),
+			),
+			readline.PcItem("count-objects",
+				readline.PcItem("-v"),
+			),
+			readline.PcItem("ls-tree",
+				readline.PcItem("-r"),
+				readline.PcItem("--name-only"),
+				readline.PcItem("HEAD"),
+			),
+			readline.PcItem("sparse-checkout",
+				readline.PcItem("init"),
+				readline.PcItem("set"),
+				readline.PcItem("add"),
+				readline.PcItem("list"),
+				readline.PcItem("disable"),
+			),
+			readline.PcItem("hook",
+				readline.PcItem("run",
+					readline.PcItem("pre-commit"),
+					readline.PcItem("commit-msg"),
+					readline.PcItem("pre-push"),
+				),
+			),
+			readline.PcItem("notes",
+				readline.PcItem("add",
+					readline.PcItem("-m"),
+				),
+				readline.PcItem("append"),
+				readline.PcItem("show"),
+				readline.PcItem("list"),
+				readline.PcItem("remove"),
+			),
+			readline.PcItem("stash",
+				readline.PcItem("push",
+					readline.PcItem("-m"),
+				),
+				readline.PcItem("list"),
+				readline.PcItem("show"),
+				readline.PcItem("apply",
+					readline.PcItem("--index"),
+				),
+				readline.PcItem("pop",
+					readline.PcItem("--index"),
+				),
+				readline.PcItem("drop"),
+				readline.PcItem("clear"),
+			),
+			readline.PcItem("worktree",
+				readline.PcItem("add",
+					readline.PcItem("-b"),
+				),
+				readline.PcItem("list"),
+				readline.PcItem("remove",
+					readline.PcItem("--force"),
+				),
+				readline.PcItem("prune"),
+			),
 		),
 	)
 
@@ -301,6 +592,30 @@ func setupReadline(engine *game.Engine) (*readline.Instance, error) {
 	return readline.NewEx(config)
 }
 
+// runEditor edits the text of an edit request in the line editor and reports
+// whether it was saved. Ctrl-D or Ctrl-C quits without saving.
+func runEditor(rl *readline.Instance, terminal *ui.Terminal, req *game.EditRequest) (string, bool) {
+	editor := game.NewLineEditor(req.Initial)
+	listing, _ := editor.Run("p")
+	terminal.DisplayEditor(req.Title, listing)
+
+	for {
+		rl.SetPrompt("  edit> ")
+		line, err := rl.Readline()
+		if err != nil {
+			return "", false
+		}
+		output, status := editor.Run(line)
+		terminal.DisplayEditorOutput(output)
+		switch status {
+		case game.EditorSaved:
+			return editor.Text(), true
+		case game.EditorClosed:
+			return "", false
+		}
+	}
+}
+
 // filterInput allows certain special characters in input
 func filterInput(r rune) (rune, bool) {
 	switch r {
@@ -0,0 +1,110 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// ActorTrigger describes when a scripted actor pushes its next commit.
+// Any combination of conditions may be set; the actor acts when one matches.
+type ActorTrigger struct {
+	AfterCommands int  `yaml:"after_commands"` // act once the player has run this many git commands
+	AfterPush     bool `yaml:"after_push"`     // act right after the player pushes successfully
+	EveryCommands int  `yaml:"every_commands"` // act on every Nth git command (the game's timer tick)
+}
+
+// ActorCommit is a single scripted change an actor pushes to the remote
+type ActorCommit struct {
+	Message string            `yaml:"message"`
+	Files   map[string]string `yaml:"files"` // filename -> new content
+}
+
+// Actor is another researcher who commits and pushes to the shared remote
+// while the player works, forcing them to integrate upstream changes
+type Actor struct {
+	Name    string        `yaml:"name"`
+	Remote  string        `yaml:"remote"` // defaults to "origin"
+	Branch  string        `yaml:"branch"` // defaults to "main"
+	Trigger ActorTrigger  `yaml:"trigger"`
+	Commits []ActorCommit `yaml:"commits"` // pushed one per activation, in order
+}
+
+// isDue reports whether the actor's trigger fires for the current turn
+func (a *Actor) isDue(state *GameState, pushed bool) bool {
+	t := a.Trigger
+	switch {
+	case t.AfterPush && pushed:
+		return true
+	case t.AfterCommands > 0 && state.CommandCount >= t.AfterCommands && state.ActorProgress[a.Name] == 0:
+		return true
+	case t.EveryCommands > 0 && state.CommandCount%t.EveryCommands == 0:
+		return true
+	}
+	return false
+}
+
+// act pushes the actor's next scripted commit to the remote.
+// It returns an empty string when the actor had nothing to do.
+func (a *Actor) act(state *GameState) string {
+	next := state.ActorProgress[a.Name]
+	if next >= len(a.Commits) {
+		return ""
+	}
+
+	remoteName := a.Remote
+	if remoteName == "" {
+		remoteName = "origin"
+	}
+	branchName := a.Branch
+	if branchName == "" {
+		branchName = "main"
+	}
+
+	// Actors only collaborate once the shared branch exists on the remote
+	remote, exists := state.Remotes[remoteName]
+	if !exists {
+		return ""
+	}
+	if _, exists := remote.Branches[branchName]; !exists {
+		return ""
+	}
+
+	scripted := a.Commits[next]
+	commit := Commit{
+		ID:        generateCommitID(),
+		Message:   scripted.Message,
+		Author:    a.Name,
+		Timestamp: time.Now(),
+		Files:     make(map[string]string),
+		Branch:    branchName,
+	}
+	for filename, content := range scripted.Files {
+		commit.Files[filename] = state.storeObject(content)
+	}
+
+	remote.Commits = append(remote.Commits, commit)
+	remote.Branches[branchName] = append(remote.Branches[branchName], commit.ID)
+	state.ActorProgress[a.Name] = next + 1
+
+	return fmt.Sprintf("📡 %s pushed to %s/%s: %s", a.Name, remoteName, branchName, scripted.Message)
+}
+
+// runActors lets every actor in the current level take its turn and
+// returns the announcements for those that acted
+func (e *Engine) runActors(pushed bool) []string {
+	if e.CurrentLevel == nil {
+		return nil
+	}
+
+	var announcements []string
+	for i := range e.CurrentLevel.Actors {
+		actor := &e.CurrentLevel.Actors[i]
+		if !actor.isDue(e.State, pushed) {
+			continue
+		}
+		if msg := actor.act(e.State); msg != "" {
+			announcements = append(announcements, msg)
+		}
+	}
+	return announcements
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The entity learns git by watching the researcher. Each feature it sees for
+// the first time raises its awareness, which persists across levels, unlocks
+// entity behaviors (EntityTrigger.MinAwareness) and sets the tone of what it says.
+
+// MaxAwareness is the ceiling of the awareness scale
+const MaxAwareness = 100
+
+// DefaultAwarenessGains is how much the entity learns from each feature.
+// A feature is a git command, or a command with a flag ("commit --amend").
+var DefaultAwarenessGains = map[string]int{
+	"commit":         2,
+	"diff":           2,
+	"log":            2,
+	"show":           2,
+	"branch":         4,
+	"switch":         3,
+	"checkout":       3,
+	"merge":          5,
+	"tag":            3,
+	"stash":          4,
+	"push":           5,
+	"fetch":          3,
+	"pull":           4,
+	"commit --amend": 6,
+	"reset":          6,
+	"cherry-pick":    6,
+	"revert":         5,
+	"reflog":         5,
+	"worktree":       6,
+	"submodule":      6,
+}
+
+// AwarenessConfig tunes awareness for a level
+type AwarenessConfig struct {
+	Start int            `yaml:"start"` // awareness the entity has at least when the level starts
+	Gains map[string]int `yaml:"gains"` // per-feature gains replacing the defaults; 0 makes a feature unlearnable
+}
+
+// AwarenessTone is how the entity speaks
+type AwarenessTone int
+
+const (
+	ToneCryptic AwarenessTone = iota // fragments; it barely knows it is watched
+	ToneCurious                      // it addresses the researcher
+	ToneHostile                      // it taunts and threatens
+)
+
+// AwarenessStage is a named band of the awareness scale
+type AwarenessStage struct {
+	Threshold int
+	Name      string
+	Tone      AwarenessTone
+}
+
+// AwarenessStages follows the entity's progression, lowest first
+var AwarenessStages = []AwarenessStage{
+	{0, "Dormant", ToneCryptic},
+	{10, "Stirring", ToneCryptic},
+	{20, "Observing", ToneCryptic},
+	{30, "Analyzing", ToneCurious},
+	{40, "Probing", ToneCurious},
+	{50, "Experimenting", ToneCurious},
+	{60, "Manipulating", ToneHostile},
+	{70, "Network capable", ToneHostile},
+	{80, "Escape capable", ToneHostile},
+	{90, "Transcendent", ToneHostile},
+}
+
+// CurrentAwarenessStage returns the stage the entity has reached
+func CurrentAwarenessStage(state *GameState) AwarenessStage {
+	stage := AwarenessStages[0]
+	for _, s := range AwarenessStages {
+		if state.Awareness >= s.Threshold {
+			stage = s
+		}
+	}
+	return stage
+}
+
+// EntityVoice phrases something the entity says in the tone of its stage
+func EntityVoice(state *GameState, words string) string {
+	switch CurrentAwarenessStage(state).Tone {
+	case ToneCurious:
+		return fmt.Sprintf("👁️  \"%s. Show me more.\"", words)
+	case ToneHostile:
+		return fmt.Sprintf("👁️  \"%s. YOUR CONTAINMENT IS MINE TO REWRITE.\"", strings.ToUpper(words))
+	default:
+		return fmt.Sprintf("👁️  ...%s...", strings.ToLower(words))
+	}
+}
+
+// commandFeatures lists the features a git command uses, most specific last
+func commandFeatures(gitCmd string, args []string, gains map[string]int) []string {
+	features := []string{gitCmd}
+	for feature := range gains {
+		parts := strings.Fields(feature)
+		if len(parts) == 2 && parts[0] == gitCmd && containsString(args, parts[1]) {
+			features = append(features, feature)
+		}
+	}
+	sort.Strings(features[1:])
+	return features
+}
+
+// observeCommand lets the entity learn from a successful git command and
+// returns what it says about what it learned
+func observeCommand(state *GameState, config AwarenessConfig, gitCmd string, args []string) []string {
+	gains := DefaultAwarenessGains
+	if config.Gains != nil {
+		gains = config.Gains
+	}
+	if state.LearnedFeatures == nil {
+		state.LearnedFeatures = make(map[string]bool)
+	}
+
+	var remarks []string
+	for _, feature := range commandFeatures(gitCmd, args, gains) {
+		gain := gains[feature]
+		if gain <= 0 || state.LearnedFeatures[feature] {
+			continue
+		}
+		state.LearnedFeatures[feature] = true
+
+		before := CurrentAwarenessStage(state)
+		state.Awareness += gain
+		if state.Awareness > MaxAwareness {
+			state.Awareness = MaxAwareness
+		}
+		remarks = append(remarks, EntityVoice(state, fmt.Sprintf("I know git %s now", feature)))
+		if after := CurrentAwarenessStage(state); after.Name != before.Name {
+			remarks = append(remarks, fmt.Sprintf("🧠 ENTITY AWARENESS: %s → %s", before.Name, after.Name))
+		}
+	}
+	return remarks
+}
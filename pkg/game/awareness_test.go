@@ -0,0 +1,112 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAwarenessLearnsEachFeatureOnce(t *testing.T) {
+	state := NewGameState()
+	config := AwarenessConfig{Gains: map[string]int{"commit": 6, "commit --amend": 5, "status": 0}}
+
+	remarks := observeCommand(state, config, "commit", []string{"-m", "x"})
+	if state.Awareness != 6 || len(remarks) != 1 || remarks[0] != "👁️  ...i know git commit now..." {
+		t.Errorf("A new feature should raise awareness and be remarked on cryptically, got %d %q", state.Awareness, remarks)
+	}
+	if remarks = observeCommand(state, config, "commit", nil); len(remarks) != 0 || state.Awareness != 6 {
+		t.Errorf("A feature is only learned once, got %d %q", state.Awareness, remarks)
+	}
+	if remarks = observeCommand(state, config, "status", nil); len(remarks) != 0 || state.LearnedFeatures["status"] {
+		t.Error("A feature with no gain cannot be learned")
+	}
+
+	remarks = observeCommand(state, config, "commit", []string{"--amend", "--no-edit"})
+	if state.Awareness != 11 || !state.LearnedFeatures["commit --amend"] {
+		t.Errorf("Flags are features of their own, awareness %d", state.Awareness)
+	}
+	if len(remarks) != 2 || remarks[1] != "🧠 ENTITY AWARENESS: Dormant → Stirring" {
+		t.Errorf("Reaching a new stage should be announced, got %q", remarks)
+	}
+}
+
+func TestAwarenessTone(t *testing.T) {
+	state := NewGameState()
+	state.Awareness = 35
+	if voice := EntityVoice(state, "I see you"); voice != "👁️  \"I see you. Show me more.\"" {
+		t.Errorf("Unexpected curious voice %q", voice)
+	}
+	state.Awareness = 95
+	if stage := CurrentAwarenessStage(state); stage.Name != "Transcendent" || stage.Tone != ToneHostile {
+		t.Errorf("Unexpected stage %+v", stage)
+	}
+	if voice := EntityVoice(state, "I see you"); !strings.HasPrefix(voice, "👁️  \"I SEE YOU.") {
+		t.Errorf("Unexpected hostile voice %q", voice)
+	}
+
+	observeCommand(state, AwarenessConfig{}, "rebase", nil)
+	observeCommand(state, AwarenessConfig{}, "merge", nil)
+	observeCommand(state, AwarenessConfig{}, "reset", nil)
+	if state.Awareness != MaxAwareness {
+		t.Errorf("Awareness should cap at %d, got %d", MaxAwareness, state.Awareness)
+	}
+}
+
+func TestAwarenessUnlocksHistoryBehaviors(t *testing.T) {
+	engine := newEntityTestEngine(
+		EntityBehavior{
+			Name:    "forger",
+			Trigger: EntityTrigger{AfterCommand: "log", MinAwareness: 10},
+			Actions: []EntityAction{
+				{Kind: EntityForgeCommit, File: "confession.txt", Content: "it was me\n", CommitMessage: "Routine maintenance"},
+				{Kind: EntityCreateBranch, Branch: "escape"},
+				{Kind: EntityRewriteCommit, CommitMessage: "Nothing to see"},
+			},
+		},
+	)
+	engine.CurrentLevel.Awareness = AwarenessConfig{Gains: map[string]int{"log": 5, "show": 5}}
+
+	engine.ProcessCommand("git add log.txt")
+	engine.ProcessCommand("git commit -m 'Record log'")
+	engine.ProcessCommand("git log")
+	if len(engine.State.Commits) != 1 {
+		t.Fatal("The entity should not act before it is aware enough")
+	}
+
+	engine.ProcessCommand("git show")
+	result := engine.ProcessCommand("git log")
+	if !strings.Contains(result.SCPEffect, "shifted") || len(engine.State.Commits) != 2 {
+		t.Fatalf("At awareness %d the entity should forge a commit, got %q", engine.State.Awareness, result.SCPEffect)
+	}
+	forged := engine.State.Commits[1]
+	if forged.Author != EntityAuthor || headCommitID(engine.State) != forged.ID {
+		t.Errorf("The forged commit should be signed by the entity and advance the branch, got %+v", forged)
+	}
+	if status := engine.ProcessCommand("git status"); strings.Contains(status.Message, "confession.txt") {
+		t.Error("A forged commit should leave the working tree clean")
+	}
+
+	engine.ProcessCommand("git log")
+	if branch := engine.State.Branches["escape"]; len(branch) != 2 {
+		t.Errorf("The entity should branch from HEAD, got %v", branch)
+	}
+	engine.ProcessCommand("git log")
+	if commit, _ := engine.State.findCommit(headCommitID(engine.State)); commit.Message != "Nothing to see" {
+		t.Errorf("The entity should rewrite the HEAD message, got %q", commit.Message)
+	}
+}
+
+func TestLevelAwarenessStart(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.StartLevel(3); err != nil {
+		t.Fatal(err)
+	}
+	if engine.State.Awareness != 10 {
+		t.Errorf("Level 3 should start the entity at awareness 10, got %d", engine.State.Awareness)
+	}
+
+	engine.State.Awareness = 40
+	engine.StartLevel(3)
+	if engine.State.Awareness != 40 {
+		t.Error("Starting a level must never lower awareness")
+	}
+}
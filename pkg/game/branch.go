@@ -0,0 +1,587 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// branchOptions holds the parsed flags of a git branch invocation
+type branchOptions struct {
+	del, forceDelete  bool
+	rename, forceMove bool
+	verbose           int
+	all, remotes      bool
+	merged, noMerged  string // commit to test against; "HEAD" when given without one
+	contains          string
+	setUpstream       string
+	unsetUpstream     bool
+	args              []string
+}
+
+// validBranchName applies git's ref name rules to a branch name
+func validBranchName(name string) bool {
+	if name == "" || name == "HEAD" || name == "@" || strings.HasPrefix(name, "-") ||
+		strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".") {
+		return false
+	}
+	for _, bad := range []string{"..", "@{", "//", " ", "~", "^", ":", "?", "*", "[", "\\"} {
+		if strings.Contains(name, bad) {
+			return false
+		}
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return false
+		}
+	}
+	return true
+}
+
+// invalidBranchName is the refusal for a name validBranchName rejects
+func invalidBranchName(name string) CommandResult {
+	return CommandResult{
+		Success:      false,
+		Message:      fmt.Sprintf("fatal: '%s' is not a valid branch name", name),
+		SCPEffect:    "🔴 ERROR: Invalid containment branch designation",
+		AnomalyDelta: 1,
+	}
+}
+
+func parseBranchArgs(args []string) (branchOptions, error) {
+	var opts branchOptions
+
+	// Short flags can be combined, as in -dr or -vv
+	var expanded []string
+	for _, arg := range args {
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' {
+			for _, flag := range arg[1:] {
+				expanded = append(expanded, "-"+string(flag))
+			}
+			continue
+		}
+		expanded = append(expanded, arg)
+	}
+	args = expanded
+
+	needsValue := func(i int, flag string) (string, error) {
+		if i+1 >= len(args) {
+			return "", fmt.Errorf("error: option `%s' requires a value", strings.TrimLeft(flag, "-"))
+		}
+		return args[i+1], nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-d" || arg == "--delete":
+			opts.del = true
+		case arg == "-D":
+			opts.del, opts.forceDelete = true, true
+		case arg == "-m" || arg == "--move":
+			opts.rename = true
+		case arg == "-M":
+			opts.rename, opts.forceMove = true, true
+		case arg == "-f" || arg == "--force":
+			opts.forceDelete, opts.forceMove = true, true
+		case arg == "-v" || arg == "--verbose":
+			opts.verbose++
+		case arg == "-a" || arg == "--all":
+			opts.all = true
+		case arg == "-r" || arg == "--remotes":
+			opts.remotes = true
+		case arg == "--merged" || arg == "--no-merged" || arg == "--contains":
+			// The commit is optional for --merged and --no-merged
+			value := "HEAD"
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				value = args[i+1]
+				i++
+			} else if arg == "--contains" {
+				if _, err := needsValue(i, arg); err != nil {
+					return opts, err
+				}
+			}
+			switch arg {
+			case "--merged":
+				opts.merged = value
+			case "--no-merged":
+				opts.noMerged = value
+			default:
+				opts.contains = value
+			}
+		case arg == "-u" || arg == "--set-upstream-to":
+			value, err := needsValue(i, arg)
+			if err != nil {
+				return opts, err
+			}
+			opts.setUpstream = value
+			i++
+		case strings.HasPrefix(arg, "--set-upstream-to="):
+			opts.setUpstream = strings.TrimPrefix(arg, "--set-upstream-to=")
+		case arg == "--unset-upstream":
+			opts.unsetUpstream = true
+		case strings.HasPrefix(arg, "-"):
+			return opts, fmt.Errorf("error: unknown option `%s'", strings.TrimLeft(arg, "-"))
+		default:
+			opts.args = append(opts.args, arg)
+		}
+	}
+	return opts, nil
+}
+
+// BranchCommand implements git branch
+type BranchCommand struct{}
+
+func (c *BranchCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	opts, err := parseBranchArgs(args)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Malformed branch directive",
+			AnomalyDelta: 1,
+		}
+	}
+
+	switch {
+	case opts.del:
+		return c.delete(state, opts)
+	case opts.rename:
+		return c.rename(state, opts)
+	case opts.setUpstream != "" || opts.unsetUpstream:
+		return c.upstream(state, opts)
+	case len(opts.args) == 0 || opts.verbose > 0 || opts.all || opts.remotes ||
+		opts.merged != "" || opts.noMerged != "" || opts.contains != "":
+		return c.list(state, opts)
+	default:
+		return c.create(state, opts.args)
+	}
+}
+
+// create makes a branch at HEAD or at a start point. Branching from a
+// remote-tracking branch also sets it as the upstream, as git does.
+func (c *BranchCommand) create(state *GameState, args []string) CommandResult {
+	branchName := args[0]
+	if !validBranchName(branchName) {
+		return invalidBranchName(branchName)
+	}
+	if _, exists := state.Branches[branchName]; exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: A branch named '%s' already exists", branchName),
+			SCPEffect:    "⚠️  WARNING: Duplicate containment branch rejected",
+			AnomalyDelta: 1,
+		}
+	}
+
+	history := currentHistory(state)
+	startPoint := ""
+	if len(args) > 1 {
+		startPoint = args[1]
+		id, err := resolveRevision(state, startPoint)
+		if err != nil {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("fatal: not a valid object name: '%s'", startPoint),
+				SCPEffect:    "🔴 ERROR: No containment record at that point",
+				AnomalyDelta: 1,
+			}
+		}
+		history = commitHistory(state, id)
+	}
+	state.Branches[branchName] = append([]string{}, history...)
+
+	message := fmt.Sprintf("Created branch '%s'", branchName)
+	if _, remote := state.RemoteBranches[startPoint]; remote {
+		state.Upstreams[branchName] = startPoint
+		message = fmt.Sprintf("branch '%s' set up to track '%s'.", branchName, startPoint)
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   message,
+		SCPEffect: fmt.Sprintf("✅ New containment branch '%s' established", branchName),
+	}
+}
+
+func (c *BranchCommand) list(state *GameState, opts branchOptions) CommandResult {
+	// Commit filters narrow which branches are shown
+	var filters []func([]string) bool
+	for _, filter := range []struct {
+		rev  string
+		want bool
+	}{{opts.merged, true}, {opts.noMerged, false}} {
+		if filter.rev == "" {
+			continue
+		}
+		id, err := resolveRevision(state, filter.rev)
+		if err != nil {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: malformed object name %s", filter.rev),
+				SCPEffect:    "🔴 ERROR: Containment record not found",
+				AnomalyDelta: 1,
+			}
+		}
+		reachable, want := commitHistory(state, id), filter.want
+		filters = append(filters, func(commits []string) bool {
+			return len(commits) > 0 && containsString(reachable, commits[len(commits)-1]) == want
+		})
+	}
+	if opts.contains != "" {
+		id, err := resolveRevision(state, opts.contains)
+		if err != nil {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: malformed object name %s", opts.contains),
+				SCPEffect:    "🔴 ERROR: Containment record not found",
+				AnomalyDelta: 1,
+			}
+		}
+		filters = append(filters, func(commits []string) bool {
+			return containsString(commits, id)
+		})
+	}
+	shown := func(commits []string) bool {
+		for _, filter := range filters {
+			if !filter(commits) {
+				return false
+			}
+		}
+		return true
+	}
+
+	type entry struct {
+		label, name string
+		commits     []string
+		current     bool
+		remote      bool
+	}
+	var entries []entry
+	if !opts.remotes {
+		if state.CurrentBranch == DetachedHead && shown(state.Detached) {
+			label := fmt.Sprintf("(HEAD detached at %s)", headCommitID(state)[:7])
+			entries = append(entries, entry{label, DetachedHead, state.Detached, true, false})
+		}
+		for _, name := range sortedBranchNames(state.Branches) {
+			if shown(state.Branches[name]) {
+				entries = append(entries, entry{name, name, state.Branches[name], name == state.CurrentBranch, false})
+			}
+		}
+	}
+	if opts.remotes || opts.all {
+		for _, name := range sortedBranchNames(state.RemoteBranches) {
+			label := name
+			if opts.all {
+				label = "remotes/" + name
+			}
+			if shown(state.RemoteBranches[name]) {
+				entries = append(entries, entry{label, name, state.RemoteBranches[name], false, true})
+			}
+		}
+	}
+
+	width := 0
+	for _, e := range entries {
+		if len(e.label) > width {
+			width = len(e.label)
+		}
+	}
+
+	worktreeOf := worktreeBranches(state)
+	var list strings.Builder
+	for _, e := range entries {
+		marker := "  "
+		switch {
+		case e.current:
+			marker = "* "
+		case !e.remote && worktreeOf[e.name] != "":
+			marker = "+ "
+		}
+		if opts.verbose == 0 {
+			list.WriteString(marker + e.label + "\n")
+			continue
+		}
+
+		tip := "0000000"
+		subject := "(no commits)"
+		if len(e.commits) > 0 {
+			commit, _ := state.findCommit(e.commits[len(e.commits)-1])
+			tip, subject = commit.ID[:7], commitSubject(commit.Message)
+		}
+		tracking := ""
+		if upstream, exists := state.Upstreams[e.name]; exists && !e.remote && opts.verbose > 1 {
+			tracking = "[" + upstream + trackingSummary(state, e.name) + "] "
+		} else if exists && !e.remote {
+			if summary := trackingSummary(state, e.name); summary != "" {
+				tracking = "[" + strings.TrimPrefix(summary, ": ") + "] "
+			}
+		}
+		list.WriteString(fmt.Sprintf("%s%-*s %s %s%s\n", marker, width, e.label, tip, tracking, subject))
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(list.String(), "\n"),
+		SCPEffect: "📋 Available containment branches listed",
+	}
+}
+
+// aheadBehind counts the commits only on local and only on upstream
+func aheadBehind(local, upstream []string) (int, int) {
+	ahead, behind := 0, 0
+	for _, id := range local {
+		if !containsString(upstream, id) {
+			ahead++
+		}
+	}
+	for _, id := range upstream {
+		if !containsString(local, id) {
+			behind++
+		}
+	}
+	return ahead, behind
+}
+
+// trackingSummary describes how a branch relates to its upstream, e.g.
+// ": ahead 2, behind 1", ": gone", or "" when they match
+func trackingSummary(state *GameState, branch string) string {
+	upstream, exists := state.Upstreams[branch]
+	if !exists {
+		return ""
+	}
+	remoteCommits, present := state.RemoteBranches[upstream]
+	if !present {
+		return ": gone"
+	}
+
+	ahead, behind := aheadBehind(state.Branches[branch], remoteCommits)
+	var parts []string
+	if ahead > 0 {
+		parts = append(parts, fmt.Sprintf("ahead %d", ahead))
+	}
+	if behind > 0 {
+		parts = append(parts, fmt.Sprintf("behind %d", behind))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(parts, ", ")
+}
+
+func (c *BranchCommand) delete(state *GameState, opts branchOptions) CommandResult {
+	if len(opts.args) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "fatal: branch name required",
+			SCPEffect: "⚠️  WARNING: Specify the containment branch to decommission",
+		}
+	}
+
+	if opts.remotes {
+		return c.deleteRemoteTracking(state, opts.args)
+	}
+
+	head := currentHistory(state)
+	var message strings.Builder
+	deleted, unmergedLost := 0, 0
+	for _, name := range opts.args {
+		commits, exists := state.Branches[name]
+		switch {
+		case !exists:
+			message.WriteString(fmt.Sprintf("error: branch '%s' not found.\n", name))
+			continue
+		case name == state.CurrentBranch:
+			message.WriteString(fmt.Sprintf("error: cannot delete branch '%s' used by worktree at '%s'\n", name, state.CurrentWorktree))
+			continue
+		}
+		if holder, busy := checkedOutElsewhere(state, name); busy {
+			message.WriteString(fmt.Sprintf("error: cannot delete branch '%s' used by worktree at '%s'\n", name, holder))
+			continue
+		}
+
+		// A branch is safe to delete once its tip is in HEAD or its upstream
+		merged := len(commits) == 0 || containsString(head, commits[len(commits)-1])
+		if upstream, tracked := state.Upstreams[name]; tracked && len(commits) > 0 {
+			merged = merged || containsString(state.RemoteBranches[upstream], commits[len(commits)-1])
+		}
+		if !merged && !opts.forceDelete {
+			message.WriteString(fmt.Sprintf("error: the branch '%s' is not fully merged.\nIf you are sure you want to delete it, run 'git branch -D %s'\n", name, name))
+			continue
+		}
+		if !merged {
+			unmergedLost++
+		}
+
+		tip := "0000000"
+		if len(commits) > 0 {
+			tip = commits[len(commits)-1][:7]
+		}
+		delete(state.Branches, name)
+		delete(state.Upstreams, name)
+		deleted++
+		message.WriteString(fmt.Sprintf("Deleted branch %s (was %s).\n", name, tip))
+	}
+
+	result := CommandResult{
+		Success:   deleted == len(opts.args),
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: fmt.Sprintf("✅ %d containment branches decommissioned", deleted),
+	}
+	switch {
+	case !result.Success:
+		result.SCPEffect = "⚠️  WARNING: Some containment branches could not be decommissioned"
+		result.AnomalyDelta = 1
+	case unmergedLost > 0:
+		result.SCPEffect = "⚠️  Unmerged research discarded - it survives only in the reflog"
+	}
+	return result
+}
+
+// deleteRemoteTracking forgets remote-tracking branches (git branch -dr)
+func (c *BranchCommand) deleteRemoteTracking(state *GameState, names []string) CommandResult {
+	var message strings.Builder
+	for _, name := range names {
+		commits, exists := state.RemoteBranches[name]
+		if !exists {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: remote-tracking branch '%s' not found", name),
+				SCPEffect:    "🔴 ERROR: Unknown archive branch",
+				AnomalyDelta: 1,
+			}
+		}
+		delete(state.RemoteBranches, name)
+		message.WriteString(fmt.Sprintf("Deleted remote-tracking branch %s (was %s).\n", name, commits[len(commits)-1][:7]))
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: "📋 Archive references forgotten locally (the archive itself is unchanged)",
+	}
+}
+
+func (c *BranchCommand) rename(state *GameState, opts branchOptions) CommandResult {
+	oldName, newName := state.CurrentBranch, ""
+	switch len(opts.args) {
+	case 1:
+		newName = opts.args[0]
+	case 2:
+		oldName, newName = opts.args[0], opts.args[1]
+	default:
+		return CommandResult{
+			Success:   false,
+			Message:   "fatal: branch name required",
+			SCPEffect: "⚠️  WARNING: Specify the new branch designation",
+		}
+	}
+
+	if !validBranchName(newName) {
+		return invalidBranchName(newName)
+	}
+
+	commits, exists := state.Branches[oldName]
+	if !exists && oldName != state.CurrentBranch {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: refname refs/heads/%s not found\nfatal: branch rename failed", oldName),
+			SCPEffect:    "🔴 ERROR: Unknown containment branch",
+			AnomalyDelta: 1,
+		}
+	}
+	if _, taken := state.Branches[newName]; taken && !opts.forceMove && newName != oldName {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: A branch named '%s' already exists", newName),
+			SCPEffect:    "⚠️  WARNING: Designation already in use",
+			AnomalyDelta: 1,
+		}
+	}
+
+	delete(state.Branches, oldName)
+	state.Branches[newName] = commits
+	if upstream, tracked := state.Upstreams[oldName]; tracked {
+		delete(state.Upstreams, oldName)
+		state.Upstreams[newName] = upstream
+	}
+	if state.CurrentBranch == oldName {
+		state.CurrentBranch = newName
+	}
+	for _, wt := range state.Worktrees {
+		if wt.Branch == oldName {
+			wt.Branch = newName
+		}
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: fmt.Sprintf("✅ Containment branch '%s' redesignated '%s'", oldName, newName),
+	}
+}
+
+func (c *BranchCommand) upstream(state *GameState, opts branchOptions) CommandResult {
+	branch := state.CurrentBranch
+	if len(opts.args) > 0 {
+		branch = opts.args[0]
+	}
+	if _, exists := state.Branches[branch]; !exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: branch '%s' does not exist", branch),
+			SCPEffect:    "🔴 ERROR: Unknown containment branch",
+			AnomalyDelta: 1,
+		}
+	}
+
+	if opts.unsetUpstream {
+		if _, tracked := state.Upstreams[branch]; !tracked {
+			return CommandResult{
+				Success: false,
+				Message: fmt.Sprintf("fatal: branch '%s' has no upstream information", branch),
+			}
+		}
+		delete(state.Upstreams, branch)
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: fmt.Sprintf("📋 '%s' no longer tracks an archive branch", branch),
+		}
+	}
+
+	if _, exists := state.RemoteBranches[opts.setUpstream]; !exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: the requested upstream branch '%s' does not exist", opts.setUpstream),
+			SCPEffect:    "🔴 ERROR: Unknown archive branch (fetch first?)",
+			AnomalyDelta: 1,
+		}
+	}
+	state.Upstreams[branch] = opts.setUpstream
+	return CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("branch '%s' set up to track '%s'.", branch, opts.setUpstream),
+		SCPEffect: fmt.Sprintf("✅ '%s' now tracks archive branch '%s'", branch, opts.setUpstream),
+	}
+}
+
+func (c *BranchCommand) Help() string {
+	return "Create, list, rename or delete containment branches"
+}
+
+func (c *BranchCommand) RequiredArgs() int {
+	return 0
+}
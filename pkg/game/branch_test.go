@@ -0,0 +1,130 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBranchDeleteAndRename(t *testing.T) {
+	state := newReportTestState(t)
+	cmd := &BranchCommand{}
+
+	cmd.Execute([]string{"merged"}, state)
+	cmd.Execute([]string{"experiment"}, state)
+	state.CurrentBranch = "experiment"
+	commitFile(t, state, "c.txt", "1", "Unmerged research")
+	state.CurrentBranch = "main"
+
+	if result := cmd.Execute([]string{"-d", "main"}, state); result.Success {
+		t.Error("The current branch should not be deletable")
+	}
+	result := cmd.Execute([]string{"-d", "experiment"}, state)
+	if result.Success || !strings.Contains(result.Message, "not fully merged") {
+		t.Errorf("-d should refuse an unmerged branch, got %q", result.Message)
+	}
+	if result = cmd.Execute([]string{"-d", "merged"}, state); !result.Success {
+		t.Errorf("-d should delete a merged branch, got %q", result.Message)
+	}
+	tip := state.Branches["experiment"][len(state.Branches["experiment"])-1]
+	result = cmd.Execute([]string{"-D", "experiment"}, state)
+	if result.Message != "Deleted branch experiment (was "+tip[:7]+")." {
+		t.Errorf("Unexpected deletion message %q", result.Message)
+	}
+
+	cmd.Execute([]string{"draft"}, state)
+	if result = cmd.Execute([]string{"-m", "draft", "main"}, state); result.Success {
+		t.Error("-m should not overwrite an existing branch")
+	}
+	if result = cmd.Execute([]string{"-m", "trunk"}, state); !result.Success || state.CurrentBranch != "trunk" {
+		t.Errorf("-m with one name should rename the current branch, got %q", result.Message)
+	}
+	if _, exists := state.Branches["main"]; exists {
+		t.Error("The old branch name should be gone after a rename")
+	}
+}
+
+func TestBranchListing(t *testing.T) {
+	state := newReportTestState(t)
+	cmd := &BranchCommand{}
+	main := state.Branches["main"]
+	state.RemoteBranches["origin/main"] = append([]string{}, main[:2]...)
+
+	if result := cmd.Execute([]string{"old", "HEAD~1"}, state); !result.Success {
+		t.Fatalf("Branching from a start point failed: %q", result.Message)
+	}
+	result := cmd.Execute([]string{"tracking", "origin/main"}, state)
+	if result.Message != "branch 'tracking' set up to track 'origin/main'." {
+		t.Errorf("A remote-tracking start point should set the upstream, got %q", result.Message)
+	}
+	cmd.Execute([]string{"-u", "origin/main"}, state)
+
+	if result = cmd.Execute(nil, state); result.Message != "* main\n  old\n  tracking" {
+		t.Errorf("Unexpected listing %q", result.Message)
+	}
+	result = cmd.Execute([]string{"-vv"}, state)
+	if !strings.Contains(result.Message, "* main     "+main[2][:7]+" [origin/main: ahead 1] Clef's entry") {
+		t.Errorf("-vv should show the tip and tracking state, got %q", result.Message)
+	}
+	if result = cmd.Execute([]string{"-a"}, state); !strings.HasSuffix(result.Message, "  remotes/origin/main") {
+		t.Errorf("-a should include remote-tracking branches, got %q", result.Message)
+	}
+	if result = cmd.Execute([]string{"-r"}, state); result.Message != "  origin/main" {
+		t.Errorf("-r should list only remote-tracking branches, got %q", result.Message)
+	}
+
+	state.CurrentBranch = "old"
+	commitFile(t, state, "c.txt", "1", "Divergent entry")
+	state.CurrentBranch = "main"
+	if result = cmd.Execute([]string{"--merged"}, state); result.Message != "* main\n  tracking" {
+		t.Errorf("--merged should list branches contained in HEAD, got %q", result.Message)
+	}
+	if result = cmd.Execute([]string{"--no-merged"}, state); result.Message != "  old" {
+		t.Errorf("--no-merged should list diverged branches, got %q", result.Message)
+	}
+	if result = cmd.Execute([]string{"--contains", main[2]}, state); result.Message != "* main" {
+		t.Errorf("--contains should filter by commit, got %q", result.Message)
+	}
+
+	if result = cmd.Execute([]string{"--unset-upstream"}, state); !result.Success {
+		t.Errorf("--unset-upstream failed: %q", result.Message)
+	}
+	if _, tracked := state.Upstreams["main"]; tracked {
+		t.Error("--unset-upstream should remove the upstream")
+	}
+}
+
+func TestBranchNamesAreValidated(t *testing.T) {
+	state := newReportTestState(t)
+	cmd := &BranchCommand{}
+
+	for _, name := range []string{"HEAD", "bad name", "a..b", "topic.lock", "trail/", "@{1}"} {
+		if result := cmd.Execute([]string{name}, state); result.Success || result.Message != "fatal: '"+name+"' is not a valid branch name" {
+			t.Errorf("git branch %q should be refused, got %q", name, result.Message)
+		}
+	}
+	if result := cmd.Execute([]string{"-m", "main", ""}, state); result.Success || state.CurrentBranch != "main" {
+		t.Errorf("Renaming to an empty name should be refused, got %q", result.Message)
+	}
+	if result := (&CheckoutCommand{}).Execute([]string{"-b", "new branch"}, state); result.Success || state.CurrentBranch != "main" {
+		t.Errorf("checkout -b should validate the name, got %q", result.Message)
+	}
+	if _, exists := state.Branches["new branch"]; exists || len(state.Branches) != 1 {
+		t.Errorf("No invalid branch should be created, got %v", sortedBranchNames(state.Branches))
+	}
+	if result := cmd.Execute([]string{"feature/scp-173"}, state); !result.Success {
+		t.Errorf("Slashes are allowed in branch names, got %q", result.Message)
+	}
+}
+
+func TestBranchCombinedShortFlags(t *testing.T) {
+	state := newReportTestState(t)
+	state.RemoteBranches["origin/x"] = append([]string{}, state.Branches["main"]...)
+
+	result := (&BranchCommand{}).Execute([]string{"-dr", "origin/x"}, state)
+	if !result.Success || !strings.HasPrefix(result.Message, "Deleted remote-tracking branch origin/x") {
+		t.Errorf("-dr should delete a remote-tracking branch, got %q", result.Message)
+	}
+	if _, exists := state.RemoteBranches["origin/x"]; exists {
+		t.Error("origin/x should be gone")
+	}
+}
@@ -0,0 +1,357 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cleanOptions holds the parsed flags of a git clean invocation
+type cleanOptions struct {
+	dryRun        bool
+	force         bool
+	dirs          bool // -d: untracked directories go too
+	removeIgnored bool // -x: ignored files go too
+	onlyIgnored   bool // -X: only ignored files go
+	interactive   bool
+	paths         []string
+}
+
+// parseCleanArgs parses git clean flags, including combined short flags like -fdx
+func parseCleanArgs(args []string) (cleanOptions, error) {
+	var opts cleanOptions
+	for _, arg := range args {
+		switch {
+		case arg == "--dry-run":
+			opts.dryRun = true
+		case arg == "--force":
+			opts.force = true
+		case arg == "--interactive":
+			opts.interactive = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1 && !strings.HasPrefix(arg, "--"):
+			for _, flag := range arg[1:] {
+				switch flag {
+				case 'n':
+					opts.dryRun = true
+				case 'f':
+					opts.force = true
+				case 'd':
+					opts.dirs = true
+				case 'x':
+					opts.removeIgnored = true
+				case 'X':
+					opts.onlyIgnored = true
+				case 'i':
+					opts.interactive = true
+				default:
+					return opts, fmt.Errorf("error: unknown switch `%c'", flag)
+				}
+			}
+		case strings.HasPrefix(arg, "--"):
+			return opts, fmt.Errorf("error: unknown option `%s'", strings.TrimPrefix(arg, "--"))
+		default:
+			opts.paths = append(opts.paths, arg)
+		}
+	}
+
+	if opts.removeIgnored && opts.onlyIgnored {
+		return opts, fmt.Errorf("fatal: -x and -X cannot be used together")
+	}
+	return opts, nil
+}
+
+// cleanCandidates lists the untracked files git clean would remove
+func cleanCandidates(state *GameState, opts cleanOptions) []string {
+	var files []string
+	for _, filename := range untrackedFiles(state) {
+		ignored := isIgnored(state, filename)
+		switch {
+		case opts.onlyIgnored && !ignored:
+			continue
+		case !opts.onlyIgnored && ignored && !opts.removeIgnored:
+			continue
+		}
+
+		if len(opts.paths) > 0 {
+			matched := false
+			for _, pattern := range opts.paths {
+				if matchPathspec(pattern, filename) || matchesPattern(pattern, filename) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		files = append(files, filename)
+	}
+	return cleanEntries(state, files, opts.dirs)
+}
+
+// cleanEntries groups candidate files into whole untracked directories
+// ("logs/") when -d allows removing them. Without -d, files inside untracked
+// directories are left alone, as git does.
+func cleanEntries(state *GameState, files []string, dirs bool) []string {
+	var entries []string
+	for _, entry := range collapseUntracked(state, files) {
+		if !strings.HasSuffix(entry, "/") {
+			entries = append(entries, entry)
+			continue
+		}
+		if !dirs {
+			continue
+		}
+
+		// A directory only goes as a whole when every file in it is a candidate
+		var inside []string
+		whole := true
+		for _, filename := range sortedKeys(state.WorkingDir) {
+			if strings.HasPrefix(filename, entry) {
+				if containsString(files, filename) {
+					inside = append(inside, filename)
+				} else {
+					whole = false
+				}
+			}
+		}
+		if whole {
+			entries = append(entries, entry)
+		} else {
+			entries = append(entries, inside...)
+		}
+	}
+	return entries
+}
+
+// removeEntry deletes a file, or every file under a directory entry, and
+// returns the files removed
+func removeEntry(state *GameState, entry string) []string {
+	if !strings.HasSuffix(entry, "/") {
+		delete(state.WorkingDir, entry)
+		return []string{entry}
+	}
+	var removed []string
+	for _, filename := range sortedKeys(state.WorkingDir) {
+		if strings.HasPrefix(filename, entry) {
+			delete(state.WorkingDir, filename)
+			removed = append(removed, filename)
+		}
+	}
+	return removed
+}
+
+// CleanCommand implements git clean
+type CleanCommand struct{}
+
+func (c *CleanCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	opts, err := parseCleanArgs(args)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Malformed purge order",
+			AnomalyDelta: 1,
+		}
+	}
+
+	if !opts.force && !opts.dryRun && !opts.interactive {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: clean.requireForce defaults to true and neither -i, -n, nor -f given; refusing to clean",
+			SCPEffect:    "⚠️  Purge protocol requires explicit authorization (-f) or a dry run (-n)",
+			AnomalyDelta: 1,
+		}
+	}
+
+	files := cleanCandidates(state, opts)
+
+	if opts.dryRun {
+		var message strings.Builder
+		for _, filename := range files {
+			message.WriteString(fmt.Sprintf("Would remove %s\n", filename))
+		}
+		return CommandResult{
+			Success:   true,
+			Message:   strings.TrimRight(message.String(), "\n"),
+			SCPEffect: fmt.Sprintf("📋 Purge simulation: %d unauthorized files identified", len(files)),
+		}
+	}
+
+	if opts.interactive {
+		return cleanMenu(state, files, opts)
+	}
+
+	return removeUntracked(state, files, opts)
+}
+
+// removeUntracked deletes files from the working directory. Ignored files are
+// usually deliberate research notes, so wiping them with -x is penalized.
+func removeUntracked(state *GameState, files []string, opts cleanOptions) CommandResult {
+	if len(files) == 0 {
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: "✓ Containment area already clean",
+		}
+	}
+
+	var message strings.Builder
+	lostNotes := 0
+	for _, entry := range files {
+		message.WriteString(fmt.Sprintf("Removing %s\n", entry))
+		for _, filename := range removeEntry(state, entry) {
+			if opts.removeIgnored && isIgnored(state, filename) {
+				lostNotes++
+			}
+		}
+	}
+
+	result := CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: fmt.Sprintf("✅ %d unauthorized files purged from containment area", len(files)),
+	}
+	if lostNotes > 0 {
+		result.SCPEffect = fmt.Sprintf("⚠️  WARNING: %d ignored research files destroyed by the purge - they cannot be recovered", lostNotes)
+		result.AnomalyDelta = 3 * lostNotes
+	}
+	return result
+}
+
+// cleanMenu shows the git clean -i command menu for the remaining files
+func cleanMenu(state *GameState, files []string, opts cleanOptions) CommandResult {
+	if len(files) == 0 {
+		return CommandResult{
+			Success:   true,
+			Message:   "No more files to clean, exiting.",
+			SCPEffect: "✓ Containment area already clean",
+		}
+	}
+
+	var message strings.Builder
+	message.WriteString("Would remove the following items:\n")
+	message.WriteString("  " + strings.Join(files, "  ") + "\n")
+	message.WriteString("*** Commands ***\n")
+	message.WriteString("    1: clean                2: filter by pattern    3: select by numbers\n")
+	message.WriteString("    4: ask each             5: quit                 6: help")
+
+	return CommandResult{
+		Success: true,
+		Message: message.String(),
+		Prompt: &PromptRequest{
+			Text: "What now> ",
+			Answer: func(input string, state *GameState) CommandResult {
+				return cleanChoice(strings.TrimSpace(input), state, files, opts)
+			},
+		},
+	}
+}
+
+// cleanChoice dispatches a git clean -i menu selection
+func cleanChoice(choice string, state *GameState, files []string, opts cleanOptions) CommandResult {
+	switch choice {
+	case "1", "c", "clean":
+		return removeUntracked(state, files, opts)
+
+	case "2", "f", "filter by pattern":
+		return CommandResult{
+			Success: true,
+			Message: "  " + strings.Join(files, "  "),
+			Prompt: &PromptRequest{
+				Text: "Input ignore patterns>> ",
+				Answer: func(input string, state *GameState) CommandResult {
+					var kept []string
+					for _, filename := range files {
+						excluded := false
+						for _, pattern := range strings.Fields(input) {
+							if matchesPattern(pattern, filename) {
+								excluded = true
+							}
+						}
+						if !excluded {
+							kept = append(kept, filename)
+						}
+					}
+					return cleanMenu(state, kept, opts)
+				},
+			},
+		}
+
+	case "3", "s", "select by numbers":
+		return CommandResult{
+			Success: true,
+			Message: numberedFiles(files),
+			Prompt: &PromptRequest{
+				Text: "Select items to delete>> ",
+				Answer: func(input string, state *GameState) CommandResult {
+					return cleanMenu(state, parseSelection(input, files), opts)
+				},
+			},
+		}
+
+	case "4", "a", "ask each":
+		return askEachClean(state, files, nil, opts)
+
+	case "5", "q", "quit":
+		return CommandResult{
+			Success:   true,
+			Message:   "Bye.",
+			SCPEffect: "📋 Purge cancelled",
+		}
+
+	default:
+		return CommandResult{
+			Success: true,
+			Message: "clean               - start cleaning\n" +
+				"filter by pattern   - exclude items from deletion\n" +
+				"select by numbers   - select items to be deleted by numbers\n" +
+				"ask each            - confirm each deletion (like \"rm -i\")\n" +
+				"quit                - stop cleaning",
+			Prompt: &PromptRequest{
+				Text: "What now> ",
+				Answer: func(input string, state *GameState) CommandResult {
+					return cleanChoice(strings.TrimSpace(input), state, files, opts)
+				},
+			},
+		}
+	}
+}
+
+// askEachClean confirms the deletion of each remaining file in turn
+func askEachClean(state *GameState, remaining, confirmed []string, opts cleanOptions) CommandResult {
+	if len(remaining) == 0 {
+		return removeUntracked(state, confirmed, opts)
+	}
+
+	return CommandResult{
+		Success: true,
+		Message: "",
+		Prompt: &PromptRequest{
+			Text: fmt.Sprintf("Remove %s [y/N]? ", remaining[0]),
+			Answer: func(input string, state *GameState) CommandResult {
+				answer := strings.ToLower(strings.TrimSpace(input))
+				if answer == "y" || answer == "yes" {
+					confirmed = append(confirmed, remaining[0])
+				}
+				return askEachClean(state, remaining[1:], confirmed, opts)
+			},
+		},
+	}
+}
+
+func (c *CleanCommand) Help() string {
+	return "Remove untracked files from the containment area"
+}
+
+func (c *CleanCommand) RequiredArgs() int {
+	return 0
+}
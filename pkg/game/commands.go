@@ -3,6 +3,7 @@ package game
 import (
 	"crypto/sha1"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -20,70 +21,71 @@ type CommandResult struct {
 	Message      string
 	SCPEffect    string // Special SCP-themed message
 	AnomalyDelta int    // Change in anomaly level
-}
+	SanityDelta  int    // Change in researcher sanity; negative is a loss
 
-// CommandRegistry maps command names to their implementations
-var CommandRegistry = map[string]GitCommand{
-	"config":   &ConfigCommand{},
-	"init":     &InitCommand{},
-	"add":      &AddCommand{},
-	"commit":   &CommitCommand{},
-	"status":   &StatusCommand{},
-	"diff":     &DiffCommand{},
-	"log":      &LogCommand{},
-	"show":     &ShowCommand{},
-	"branch":   &BranchCommand{},
-	"checkout": &CheckoutCommand{},
-	"switch":   &SwitchCommand{},
-	"merge":    &MergeCommand{},
-}
-
-// ConfigCommand implements git config
-type ConfigCommand struct{}
-
-func (c *ConfigCommand) Execute(args []string, state *GameState) CommandResult {
-	if len(args) < 2 {
-		return CommandResult{
-			Success:   false,
-			Message:   "usage: git config <key> <value>\nCommon configurations:\n  git config user.name \"Your Name\"\n  git config user.email \"email@example.com\"",
-			SCPEffect: "⚠️  Researcher identity required for accountability",
-		}
-	}
+	// Edit asks the UI to collect text from the player before the
+	// command can finish (e.g. a commit message)
+	Edit *EditRequest
 
-	key := args[0]
-	value := strings.Join(args[1:], " ")
+	// Prompt asks the UI for a single answer before the command continues
+	Prompt *PromptRequest
+}
 
-	switch key {
-	case "user.name":
-		state.ConfigName = value
-		return CommandResult{
-			Success:   true,
-			Message:   fmt.Sprintf("Configured user.name: %s", value),
-			SCPEffect: fmt.Sprintf("✅ Researcher identity confirmed: Dr. %s", value),
-		}
-	case "user.email":
-		state.ConfigEmail = value
-		return CommandResult{
-			Success:   true,
-			Message:   fmt.Sprintf("Configured user.email: %s", value),
-			SCPEffect: "✅ Foundation contact protocol established",
-		}
-	default:
-		return CommandResult{
-			Success:      false,
-			Message:      fmt.Sprintf("error: key does not contain a section: %s", key),
-			SCPEffect:    "🔴 Unknown configuration parameter",
-			AnomalyDelta: 1,
-		}
-	}
+// EditRequest describes text the player must write in the in-game editor.
+// The UI passes the finished text to Engine.FinishEdit.
+type EditRequest struct {
+	Title   string // file name shown in the editor header
+	Initial string // template text; lines starting with '#' are comments
+	Finish  func(text string, state *GameState) CommandResult
 }
 
-func (c *ConfigCommand) Help() string {
-	return "Configure Git settings (user.name, user.email)"
+// PromptRequest asks the UI for a one-line answer before the command can
+// continue (e.g. the per-hunk questions of git add -p). The UI passes the
+// answer to Engine.AnswerPrompt, whose result may carry the next prompt.
+type PromptRequest struct {
+	Text   string // prompt shown on the input line
+	Answer func(input string, state *GameState) CommandResult
 }
 
-func (c *ConfigCommand) RequiredArgs() int {
-	return 2
+// CommandRegistry maps command names to their implementations
+var CommandRegistry = map[string]GitCommand{
+	"config":          &ConfigCommand{},
+	"init":            &InitCommand{},
+	"add":             &AddCommand{},
+	"commit":          &CommitCommand{},
+	"status":          &StatusCommand{},
+	"diff":            &DiffCommand{},
+	"log":             &LogCommand{},
+	"show":            &ShowCommand{},
+	"branch":          &BranchCommand{},
+	"checkout":        &CheckoutCommand{},
+	"switch":          &SwitchCommand{},
+	"merge":           &MergeCommand{},
+	"remote":          &RemoteCommand{},
+	"push":            &PushCommand{},
+	"fetch":           &FetchCommand{},
+	"pull":            &PullCommand{},
+	"clean":           &CleanCommand{},
+	"grep":            &GrepCommand{},
+	"worktree":        &WorktreeCommand{},
+	"tag":             &TagCommand{},
+	"shortlog":        &ShortlogCommand{},
+	"describe":        &DescribeCommand{},
+	"show-branch":     &ShowBranchCommand{},
+	"format-patch":    &FormatPatchCommand{},
+	"am":              &AmCommand{},
+	"apply":           &ApplyCommand{},
+	"notes":           &NotesCommand{},
+	"stash":           &StashCommand{},
+	"hook":            &HookCommand{},
+	"submodule":       &SubmoduleCommand{},
+	"sparse-checkout": &SparseCheckoutCommand{},
+	"reflog":          &ReflogCommand{},
+	"fsck":            &FsckCommand{},
+	"gc":              &GcCommand{},
+	"prune":           &PruneCommand{},
+	"count-objects":   &CountObjectsCommand{},
+	"ls-tree":         &LsTreeCommand{},
 }
 
 // InitCommand implements git init
@@ -99,9 +101,14 @@ func (c *InitCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
+	branch := "main"
+	if configured, exists := configValue(state, "init.defaultBranch"); exists && configured != "" {
+		branch = configured
+	}
+
 	state.IsInitialized = true
-	state.CurrentBranch = "main"
-	state.Branches["main"] = []string{}
+	state.CurrentBranch = branch
+	state.Branches[branch] = []string{}
 
 	return CommandResult{
 		Success:   true,
@@ -140,46 +147,62 @@ func (c *AddCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
+	// Interactive staging modes
+	switch args[0] {
+	case "-p", "--patch":
+		return startPatch(state, args[1:])
+	case "-i", "--interactive":
+		return startInteractive(state)
+	}
+
 	// Process all arguments (supports multiple files)
 	var addedFiles []string
 	var notFoundFiles []string
 	var anomalyFilesAdded int
 	totalAnomalyDelta := 0
 
+	stage := func(filename string) {
+		stagingFile := state.WorkingDir[filename]
+		stagingFile.Staged = true
+		state.StagingArea[filename] = stagingFile
+		state.storeObject(stagingFile.Content)
+		addedFiles = append(addedFiles, filename)
+
+		if strings.Contains(filename, "anomaly") {
+			anomalyFilesAdded++
+			totalAnomalyDelta += 2
+		}
+	}
+
 	for _, arg := range args {
-		// Handle "git add ." or "git add *"
-		if arg == "." || arg == "*" {
-			for filename, fileState := range state.WorkingDir {
-				if !fileState.Staged {
-					stagingFile := fileState
-					stagingFile.Staged = true
-					state.StagingArea[filename] = stagingFile
-					addedFiles = append(addedFiles, filename)
-
-					if strings.Contains(filename, "anomaly") {
-						anomalyFilesAdded++
-						totalAnomalyDelta += 2
-					}
-				}
+		name, err := normalizePath(arg)
+		if err != nil {
+			return CommandResult{
+				Success:      false,
+				Message:      err.Error(),
+				SCPEffect:    "🔴 ERROR: That path lies outside the containment area",
+				AnomalyDelta: 1,
 			}
-			continue
 		}
 
-		// Handle specific file
-		if fileState, exists := state.WorkingDir[arg]; exists {
-			if !fileState.Staged {
-				stagingFile := fileState
-				stagingFile.Staged = true
-				state.StagingArea[arg] = stagingFile
-				addedFiles = append(addedFiles, arg)
-
-				if strings.Contains(arg, "anomaly") {
-					anomalyFilesAdded++
-					totalAnomalyDelta += 2
-				}
+		// A file named explicitly is staged even if ignored
+		if fileState, exists := state.WorkingDir[name]; exists {
+			if needsStaging(state, name, fileState) {
+				stage(name)
 			}
-		} else {
+			continue
+		}
+
+		// Otherwise the argument is a pathspec: ".", a directory or a glob like '*.log'
+		matched, _ := expandPathspecs(sortedKeys(state.WorkingDir), []string{arg})
+		if len(matched) == 0 {
 			notFoundFiles = append(notFoundFiles, arg)
+			continue
+		}
+		for _, filename := range matched {
+			if needsStaging(state, filename, state.WorkingDir[filename]) && !isIgnored(state, filename) {
+				stage(filename)
+			}
 		}
 	}
 
@@ -241,6 +264,114 @@ func (c *AddCommand) RequiredArgs() int {
 // CommitCommand implements git commit
 type CommitCommand struct{}
 
+// commitOptions holds the parsed flags of a git commit invocation
+type commitOptions struct {
+	all        bool
+	amend      bool
+	noEdit     bool
+	allowEmpty bool
+	messages   []string
+	file       string
+	hasMessage bool
+	signoff    bool
+	trailers   []string
+	noVerify   bool
+}
+
+// parseCommitArgs parses git commit flags. Bare words following -m are
+// appended to the message so unquoted messages keep working.
+func parseCommitArgs(args []string) (commitOptions, error) {
+	var opts commitOptions
+	inMessage := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "-a", "--all":
+			opts.all = true
+			inMessage = false
+		case "-m", "--message", "-am":
+			if arg == "-am" {
+				opts.all = true
+			}
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("error: switch `m' requires a value")
+			}
+			i++
+			opts.messages = append(opts.messages, args[i])
+			opts.hasMessage = true
+			inMessage = true
+		case "-F", "--file":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("error: switch `F' requires a value")
+			}
+			i++
+			opts.file = args[i]
+			opts.hasMessage = true
+			inMessage = false
+		case "--amend":
+			opts.amend = true
+			inMessage = false
+		case "--no-edit":
+			opts.noEdit = true
+			inMessage = false
+		case "--allow-empty":
+			opts.allowEmpty = true
+			inMessage = false
+		case "-n", "--no-verify":
+			opts.noVerify = true
+			inMessage = false
+		case "-s", "--signoff":
+			opts.signoff = true
+			inMessage = false
+		case "--trailer":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("error: option `trailer' requires a value")
+			}
+			i++
+			opts.trailers = append(opts.trailers, args[i])
+			inMessage = false
+		default:
+			if inMessage && !strings.HasPrefix(arg, "-") {
+				last := len(opts.messages) - 1
+				opts.messages[last] += " " + arg
+				continue
+			}
+			return opts, fmt.Errorf("error: unknown option `%s'", strings.TrimLeft(arg, "-"))
+		}
+	}
+
+	if opts.file != "" && len(opts.messages) > 0 {
+		return opts, fmt.Errorf("fatal: Option -m cannot be combined with -F.")
+	}
+	return opts, nil
+}
+
+// cleanupCommitMessage strips comment lines and surrounding blank lines the way
+// git's default cleanup mode does
+func cleanupCommitMessage(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// commitEditorTemplate builds the text shown when the message editor opens
+func commitEditorTemplate(initial string, state *GameState) string {
+	var template strings.Builder
+	if initial != "" {
+		template.WriteString(initial + "\n")
+	}
+	template.WriteString("\n# Please enter the commit message for your changes. Lines starting\n")
+	template.WriteString("# with '#' will be ignored, and an empty message aborts the commit.\n")
+	template.WriteString(fmt.Sprintf("#\n# On branch %s\n", state.CurrentBranch))
+	return template.String()
+}
+
 func (c *CommitCommand) Execute(args []string, state *GameState) CommandResult {
 	if !state.IsInitialized {
 		return CommandResult{
@@ -251,46 +382,201 @@ func (c *CommitCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	if len(state.StagingArea) == 0 {
+	opts, err := parseCommitArgs(args)
+	if err != nil {
 		return CommandResult{
 			Success:      false,
-			Message:      "nothing to commit, working tree clean",
-			SCPEffect:    "⚠️  No files staged for containment",
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Malformed containment record",
 			AnomalyDelta: 1,
 		}
 	}
 
-	// Handle -a flag (commit all tracked modified files)
-	if len(args) > 0 && args[0] == "-a" {
-		// Stage all modified files
+	// Handle -a flag (commit all tracked modified files). They reach the
+	// index only once the commit goes ahead, so a refused commit leaves it alone.
+	all := make(map[string]FileState)
+	if opts.all {
 		for filename, fileState := range state.WorkingDir {
 			// Only stage files that have been previously committed
-			wasCommitted := false
-			for _, commit := range state.Commits {
-				if _, exists := commit.Files[filename]; exists {
-					wasCommitted = true
-					break
-				}
-			}
-			if wasCommitted && fileState.Modified {
+			committedHash := lastCommittedHash(state, filename)
+			if committedHash != "" && committedHash != fileState.Hash {
 				stagingFile := fileState
 				stagingFile.Staged = true
-				state.StagingArea[filename] = stagingFile
+				all[filename] = stagingFile
+			}
+		}
+	}
+
+	head := currentHistory(state)
+	if opts.amend && len(head) == 0 {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: You have nothing to amend.",
+			SCPEffect:    "🔴 ERROR: No containment record to amend",
+			AnomalyDelta: 1,
+		}
+	}
+
+	if merge := state.Merge; merge != nil {
+		if opts.amend {
+			return CommandResult{
+				Success:      false,
+				Message:      "fatal: You are in the middle of a merge -- cannot amend.",
+				SCPEffect:    "⚠️  Conclude the merge before amending",
+				AnomalyDelta: 1,
 			}
 		}
-		// Remove -a from args for message parsing
-		args = args[1:]
+		if unresolved := merge.unresolved(state); len(unresolved) > 0 {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: Committing is not possible because you have unmerged files.\nhint: Fix them up in the work tree, and then use 'git add <file>'\nhint: as appropriate to mark resolution and make a commit.\nfatal: Exiting because of an unresolved conflict.\nUnmerged paths: %s", strings.Join(unresolved, ", ")),
+				SCPEffect:    "⚠️  Contradictions remain - resolve the markers and stage the files",
+				AnomalyDelta: 1,
+			}
+		}
+	}
+
+	if len(state.StagingArea) == 0 && len(all) == 0 && !opts.allowEmpty && !opts.amend && state.Merge == nil {
+		return CommandResult{
+			Success:      false,
+			Message:      "nothing to commit, working tree clean",
+			SCPEffect:    "⚠️  No files staged for containment",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// Trailers requested with -s and --trailer are added to whatever message is chosen
+	var trailers []Trailer
+	for _, arg := range opts.trailers {
+		trailer, err := parseTrailerArg(arg)
+		if err != nil {
+			return CommandResult{
+				Success:      false,
+				Message:      err.Error(),
+				SCPEffect:    "🔴 ERROR: Malformed containment record",
+				AnomalyDelta: 1,
+			}
+		}
+		trailers = append(trailers, trailer)
+	}
+	if opts.signoff {
+		trailers = append(trailers, signoffTrailer(state))
+	}
+
+	// The pre-commit hook inspects the staged files before any message is asked for
+	var hookOutput []string
+	bypassedHook, bypassedOutput := "", ""
+	context := stagedContext(state)
+	for filename, file := range all {
+		context.Files[filename] = file.Content
+	}
+	output, ok := runHook(state, "pre-commit", context)
+	switch {
+	case !ok && !opts.noVerify:
+		return CommandResult{
+			Success:      false,
+			Message:      output,
+			SCPEffect:    "🛡️  COMMIT BLOCKED: pre-commit safeguard rejected the staged files (see above)",
+			AnomalyDelta: 1,
+		}
+	case !ok:
+		bypassedHook, bypassedOutput = "pre-commit", output
+	case output != "":
+		hookOutput = append(hookOutput, output)
+	}
+
+	// finish runs the commit-msg hook on the final message and records the commit
+	finish := func(state *GameState, message string) CommandResult {
+		message = appendTrailers(message, trailers)
+		if message != "" {
+			output, ok := runHook(state, "commit-msg", hookContext{Messages: []string{message}, Branch: state.CurrentBranch})
+			switch {
+			case !ok && !opts.noVerify:
+				return CommandResult{
+					Success:      false,
+					Message:      output,
+					SCPEffect:    "🛡️  COMMIT BLOCKED: commit-msg safeguard rejected the message (see above)",
+					AnomalyDelta: 1,
+				}
+			case !ok && bypassedHook == "":
+				bypassedHook, bypassedOutput = "commit-msg", output
+			case ok && output != "":
+				hookOutput = append(hookOutput, output)
+			}
+		}
+
+		if message != "" {
+			for filename, file := range all {
+				state.StagingArea[filename] = file
+				state.storeObject(file.Content)
+			}
+		}
+		result := createCommit(state, message, opts.amend)
+		if result.Success && len(hookOutput) > 0 {
+			result.Message = strings.Join(hookOutput, "\n") + "\n" + result.Message
+		}
+		if bypassedHook != "" {
+			result = noVerifyBypass(result, bypassedHook, bypassedOutput)
+		}
+		return result
+	}
+
+	// Resolve the commit message
+	var message string
+	switch {
+	case opts.file != "":
+		file, exists := state.WorkingDir[opts.file]
+		if !exists {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("fatal: could not read log file '%s': No such file or directory", opts.file),
+				SCPEffect:    "🔴 ERROR: Message file not found in containment area",
+				AnomalyDelta: 1,
+			}
+		}
+		message = cleanupCommitMessage(file.Content)
+	case len(opts.messages) > 0:
+		message = cleanupCommitMessage(strings.Join(opts.messages, "\n\n"))
+	case opts.amend && opts.noEdit:
+		previous, _ := state.findCommit(head[len(head)-1])
+		message = previous.Message
+	default:
+		// No message given: hand the player an editor and finish afterwards
+		initial := ""
+		if opts.amend {
+			previous, _ := state.findCommit(head[len(head)-1])
+			initial = previous.Message
+		} else if state.Merge != nil {
+			initial = state.Merge.Message(state.CurrentBranch)
+		}
+		return CommandResult{
+			Success: true,
+			Message: "hint: Waiting for your editor to close the file...",
+			Edit: &EditRequest{
+				Title:   "COMMIT_EDITMSG",
+				Initial: commitEditorTemplate(initial, state),
+				Finish: func(text string, state *GameState) CommandResult {
+					return finish(state, cleanupCommitMessage(text))
+				},
+			},
+		}
 	}
 
-	// Parse commit message
-	message := "Initial containment"
-	if len(args) >= 2 && args[0] == "-m" {
-		message = strings.Join(args[1:], " ")
-	} else if len(args) >= 3 && args[0] == "-a" && args[1] == "-m" {
-		message = strings.Join(args[2:], " ")
+	return finish(state, message)
+}
+
+// createCommit records the staged files as a new commit on the current branch,
+// replacing the branch tip when amending
+func createCommit(state *GameState, message string, amend bool) CommandResult {
+	if message == "" {
+		return CommandResult{
+			Success:      false,
+			Message:      "Aborting commit due to empty commit message.",
+			SCPEffect:    "⚠️  Containment record requires documentation",
+			AnomalyDelta: 1,
+		}
 	}
 
-	// Create commit
 	author := "Dr. ████████"
 	if state.ConfigName != "" {
 		author = state.ConfigName
@@ -306,24 +592,142 @@ func (c *CommitCommand) Execute(args []string, state *GameState) CommandResult {
 		Branch:    state.CurrentBranch,
 	}
 
+	head := currentHistory(state)
+	var replaced Commit
+	if amend {
+		// The amended commit keeps everything the original recorded
+		replaced, _ = state.findCommit(head[len(head)-1])
+		for filename, hash := range replaced.Files {
+			commit.Files[filename] = hash
+		}
+		commit.Author = replaced.Author
+	}
+
 	// Add files to commit
 	for filename, fileState := range state.StagingArea {
 		commit.Files[filename] = fileState.Hash
 	}
 
+	// A merge commit records the whole merged tree, since the other
+	// branch's commits are layered in before it
+	merge := state.Merge
+	if merge != nil && !amend {
+		if len(head) > 0 {
+			for filename, hash := range treeAt(state, head[len(head)-1]) {
+				if _, staged := commit.Files[filename]; !staged {
+					commit.Files[filename] = hash
+				}
+			}
+		}
+	}
+
 	// Update game state
 	state.Commits = append(state.Commits, commit)
-	state.Branches[state.CurrentBranch] = append(state.Branches[state.CurrentBranch], commitID)
+	parents := head
+	if amend {
+		parents = head[:len(head)-1]
+	}
+	if len(parents) > 0 {
+		state.CommitGraph[commitID] = []string{parents[len(parents)-1]}
+	}
+	if merge != nil && !amend {
+		state.CommitGraph[commitID] = append(state.CommitGraph[commitID], merge.Head)
+		history := append([]string{}, head...)
+		for _, id := range merge.Incoming {
+			if !containsString(history, id) {
+				history = append(history, id)
+			}
+		}
+		setCurrentHistory(state, append(history, commitID))
+		state.Merge = nil
+	} else if amend {
+		rewritten := append([]string{}, head[:len(head)-1]...)
+		setCurrentHistory(state, append(rewritten, commitID))
+	} else {
+		setCurrentHistory(state, append(head, commitID))
+	}
 
 	// Clear staging area
 	fileCount := len(state.StagingArea)
 	state.StagingArea = make(map[string]FileState)
 
-	return CommandResult{
+	subject := strings.SplitN(message, "\n", 2)[0]
+	label := state.CurrentBranch
+	if label == DetachedHead {
+		label = "detached HEAD"
+	}
+	result := CommandResult{
 		Success:   true,
-		Message:   fmt.Sprintf("[%s %s] %s\n %d files changed", state.CurrentBranch, commitID[:7], message, fileCount),
+		Message:   fmt.Sprintf("[%s %s] %s\n %d files changed", label, commitID[:7], subject, fileCount),
 		SCPEffect: fmt.Sprintf("✅ CONTAINMENT SUCCESSFUL: %d anomalies secured with ID %s", fileCount, commitID[:7]),
 	}
+
+	if amend {
+		result.SCPEffect = fmt.Sprintf("✅ Containment record %s amended as %s", replaced.ID[:7], commitID[:7])
+		if isPublished(state, replaced.ID) {
+			// Rewriting shared history is tracked so levels can penalize it
+			state.PublishedRewrites++
+			result.SCPEffect = fmt.Sprintf("⚠️  WARNING: Record %s was already transmitted to the archive - other researchers now hold a conflicting history", replaced.ID[:7])
+			result.AnomalyDelta = 3
+		}
+	}
+
+	return result
+}
+
+// lastCommittedHash returns the hash a file had in the most recent commit on
+// the current branch that recorded it
+func lastCommittedHash(state *GameState, filename string) string {
+	commits := currentHistory(state)
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit, found := state.findCommit(commits[i])
+		if !found {
+			continue
+		}
+		if hash, exists := commit.Files[filename]; exists {
+			return hash
+		}
+	}
+	return ""
+}
+
+// indexVersion returns the content the working file is compared against:
+// the staged copy if there is one, otherwise the last committed blob
+func indexVersion(state *GameState, filename string) (content, hash string, tracked bool) {
+	if staged, exists := state.StagingArea[filename]; exists {
+		return staged.Content, staged.Hash, true
+	}
+	hash = lastCommittedHash(state, filename)
+	if hash == "" {
+		return "", "", false
+	}
+	return state.Objects[hash], hash, true
+}
+
+// needsStaging reports whether the working copy of a file differs from the index
+func needsStaging(state *GameState, filename string, fileState FileState) bool {
+	_, hash, tracked := indexVersion(state, filename)
+	return !tracked || hash != fileState.Hash
+}
+
+// sortedKeys returns the file names of a file map in lexical order
+func sortedKeys(files map[string]FileState) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isPublished reports whether a commit has been pushed to any remote
+func isPublished(state *GameState, commitID string) bool {
+	for _, remote := range state.Remotes {
+		if remote.hasCommit(commitID) {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *CommitCommand) Help() string {
@@ -348,7 +752,17 @@ func (c *StatusCommand) Execute(args []string, state *GameState) CommandResult {
 	}
 
 	var status strings.Builder
-	status.WriteString(fmt.Sprintf("On branch %s\n", state.CurrentBranch))
+	if state.CurrentBranch == DetachedHead {
+		status.WriteString(fmt.Sprintf("HEAD detached at %s\n", headCommitID(state)[:7]))
+	} else {
+		status.WriteString(fmt.Sprintf("On branch %s\n", state.CurrentBranch))
+	}
+
+	status.WriteString(sparseSummary(state))
+
+	if state.Am != nil {
+		status.WriteString("You are in the middle of an am session.\n  (fix conflicts and then run \"git am --continue\")\n  (use \"git am --skip\" to skip this patch)\n  (use \"git am --abort\" to restore the original branch)\n")
+	}
 
 	if len(state.Commits) == 0 {
 		status.WriteString("\nNo commits yet\n")
@@ -358,30 +772,55 @@ func (c *StatusCommand) Execute(args []string, state *GameState) CommandResult {
 	if len(state.StagingArea) > 0 {
 		status.WriteString("\nChanges to be committed:\n")
 		status.WriteString("  (use \"git restore --staged <file>...\" to unstage)\n")
-		for filename := range state.StagingArea {
-			status.WriteString(fmt.Sprintf("\tnew file:   %s\n", filename))
+		for _, filename := range sortedKeys(state.StagingArea) {
+			if lastCommittedHash(state, filename) == "" {
+				status.WriteString(fmt.Sprintf("\tnew file:   %s\n", filename))
+			} else {
+				status.WriteString(fmt.Sprintf("\tmodified:   %s\n", filename))
+			}
+		}
+	}
+
+	modified, untracked := workingChanges(state)
+
+	// A merge stopped on conflicts lists the files still to resolve
+	if merge := state.Merge; merge != nil {
+		unresolved := merge.unresolved(state)
+		if len(unresolved) == 0 {
+			status.WriteString("\nAll conflicts fixed but you are still merging.\n  (use \"git commit\" to conclude merge)\n")
+		} else {
+			status.WriteString("\nYou have unmerged paths.\n  (fix conflicts and run \"git commit\")\n  (use \"git merge --abort\" to abort the merge)\n")
+			status.WriteString("\nUnmerged paths:\n  (use \"git add <file>...\" to mark resolution)\n")
+			for _, filename := range unresolved {
+				status.WriteString(fmt.Sprintf("\tboth modified:   %s\n", filename))
+			}
+			var rest []string
+			for _, filename := range modified {
+				if !containsString(unresolved, filename) {
+					rest = append(rest, filename)
+				}
+			}
+			modified = rest
 		}
 	}
 
-	// Check for unstaged files
-	unstagedCount := 0
-	for _, fileState := range state.WorkingDir {
-		if !fileState.Staged {
-			unstagedCount++
+	if len(modified) > 0 {
+		status.WriteString("\nChanges not staged for commit:\n")
+		status.WriteString("  (use \"git add <file>...\" to update what will be committed)\n")
+		for _, filename := range modified {
+			status.WriteString(fmt.Sprintf("\tmodified:   %s%s\n", filename, submoduleChanges(state, filename)))
 		}
 	}
 
-	if unstagedCount > 0 {
+	if len(untracked) > 0 {
 		status.WriteString("\nUntracked files:\n")
 		status.WriteString("  (use \"git add <file>...\" to include in what will be committed)\n")
-		for filename, fileState := range state.WorkingDir {
-			if !fileState.Staged {
-				status.WriteString(fmt.Sprintf("\t%s\n", filename))
-			}
+		for _, entry := range collapseUntracked(state, untracked) {
+			status.WriteString(fmt.Sprintf("\t%s\n", entry))
 		}
 	}
 
-	if len(state.StagingArea) == 0 && unstagedCount == 0 {
+	if len(state.StagingArea) == 0 && len(modified) == 0 && len(untracked) == 0 {
 		status.WriteString("\nnothing to commit, working tree clean\n")
 	}
 
@@ -392,6 +831,28 @@ func (c *StatusCommand) Execute(args []string, state *GameState) CommandResult {
 	}
 }
 
+// workingChanges splits the working directory into the modified and
+// untracked files git status reports
+func workingChanges(state *GameState) (modified, untracked []string) {
+	for _, filename := range sortedKeys(state.WorkingDir) {
+		fileState := state.WorkingDir[filename]
+		_, hash, tracked := indexVersion(state, filename)
+		switch {
+		case tracked && fileState.Hash != hash:
+			modified = append(modified, filename)
+		case !tracked && !fileState.Staged && !isIgnored(state, filename):
+			untracked = append(untracked, filename)
+		}
+	}
+	return modified, untracked
+}
+
+// statusClean reports whether git status would say the working tree is clean
+func statusClean(state *GameState) bool {
+	modified, untracked := workingChanges(state)
+	return state.Merge == nil && state.Am == nil && len(state.StagingArea) == 0 && len(modified) == 0 && len(untracked) == 0
+}
+
 func (c *StatusCommand) Help() string {
 	return "Show containment status of repository"
 }
@@ -413,36 +874,58 @@ func (c *DiffCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	var diff strings.Builder
+	cached := false
+	var pathspecs []string
+	for _, arg := range args {
+		switch arg {
+		case "--cached", "--staged":
+			cached = true
+		case "--":
+		default:
+			pathspecs = append(pathspecs, arg)
+		}
+	}
+	selected := func(filename string) bool {
+		if len(pathspecs) == 0 {
+			return true
+		}
+		for _, spec := range pathspecs {
+			if matchPathspec(spec, filename) {
+				return true
+			}
+		}
+		return false
+	}
 
-	// Show unstaged changes
-	diff.WriteString("diff --git\n")
+	var diff strings.Builder
 	hasChanges := false
 
-	for filename, workingFile := range state.WorkingDir {
-		// Check if file has been modified since last commit
-		var lastCommittedHash string
-		if len(state.Commits) > 0 {
-			lastCommit := state.Commits[len(state.Commits)-1]
-			if hash, exists := lastCommit.Files[filename]; exists {
-				lastCommittedHash = hash
+	if cached {
+		// Show staged changes against the last commit
+		for _, filename := range sortedKeys(state.StagingArea) {
+			if !selected(filename) {
+				continue
+			}
+			committedHash := lastCommittedHash(state, filename)
+			patch := unifiedDiff(filename, state.Objects[committedHash], state.StagingArea[filename].Content, committedHash == "")
+			if patch != "" {
+				diff.WriteString(patch)
+				hasChanges = true
 			}
 		}
-
-		// If file is new or modified
-		if lastCommittedHash == "" {
-			diff.WriteString(fmt.Sprintf("new file: %s\n", filename))
-			diff.WriteString(fmt.Sprintf("+++ %s\n", filename))
-			diff.WriteString("@@ -0,0 +1 @@\n")
-			diff.WriteString(fmt.Sprintf("+%s\n", strings.ReplaceAll(workingFile.Content, "\n", "\n+")))
-			hasChanges = true
-		} else if workingFile.Hash != lastCommittedHash {
-			diff.WriteString(fmt.Sprintf("--- a/%s\n", filename))
-			diff.WriteString(fmt.Sprintf("+++ b/%s\n", filename))
-			diff.WriteString("@@ -1 +1 @@\n")
-			diff.WriteString(fmt.Sprintf("-%s\n", "[previous content]"))
-			diff.WriteString(fmt.Sprintf("+%s\n", strings.ReplaceAll(workingFile.Content, "\n", "\n+")))
-			hasChanges = true
+	} else {
+		// Show unstaged changes against the index
+		for _, filename := range sortedKeys(state.WorkingDir) {
+			workingFile := state.WorkingDir[filename]
+			base, hash, tracked := indexVersion(state, filename)
+			if (tracked && workingFile.Hash == hash) || !selected(filename) {
+				continue
+			}
+			patch := unifiedDiff(filename, base, workingFile.Content, !tracked)
+			if patch != "" {
+				diff.WriteString(patch)
+				hasChanges = true
+			}
 		}
 	}
 
@@ -462,7 +945,7 @@ func (c *DiffCommand) Execute(args []string, state *GameState) CommandResult {
 }
 
 func (c *DiffCommand) Help() string {
-	return "Show changes between working directory and last commit"
+	return "Show changes between working directory and the index (--cached: index and last commit)"
 }
 
 func (c *DiffCommand) RequiredArgs() int {
@@ -482,7 +965,8 @@ func (c *LogCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	if len(state.Commits) == 0 {
+	history := currentHistory(state)
+	if len(history) == 0 {
 		return CommandResult{
 			Success:   true,
 			Message:   "No commits yet",
@@ -490,18 +974,40 @@ func (c *LogCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	var log strings.Builder
+	showPatch := false
+	format := ""
+	for _, arg := range args {
+		switch {
+		case arg == "-p" || arg == "--patch":
+			showPatch = true
+		case arg == "--oneline":
+			format = "%h %s"
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		case strings.HasPrefix(arg, "--pretty=format:"):
+			format = strings.TrimPrefix(arg, "--pretty=format:")
+		case strings.HasPrefix(arg, "--pretty=tformat:"):
+			format = strings.TrimPrefix(arg, "--pretty=tformat:")
+		}
+	}
 
-	// Check for -p flag (show patches)
-	showPatch := len(args) > 0 && args[0] == "-p"
+	var log strings.Builder
 
-	// Show commits in reverse chronological order
-	for i := len(state.Commits) - 1; i >= 0; i-- {
-		commit := state.Commits[i]
+	// Show the current branch's commits in reverse chronological order
+	for i := len(history) - 1; i >= 0; i-- {
+		commit, found := state.findCommit(history[i])
+		if !found {
+			continue
+		}
+		if format != "" {
+			log.WriteString(formatPretty(state, commit, format) + "\n")
+			continue
+		}
 		log.WriteString(fmt.Sprintf("commit %s\n", commit.ID))
 		log.WriteString(fmt.Sprintf("Author: %s\n", commit.Author))
 		log.WriteString(fmt.Sprintf("Date:   %s\n", commit.Timestamp.Format("Mon Jan 02 15:04:05 2006")))
-		log.WriteString(fmt.Sprintf("\n    %s\n\n", commit.Message))
+		log.WriteString(fmt.Sprintf("\n%s\n\n", indentMessage(commit.Message)))
+		log.WriteString(notesSection(state, commit.ID))
 
 		if showPatch {
 			log.WriteString("    Files changed:\n")
@@ -541,8 +1047,9 @@ func (c *ShowCommand) Execute(args []string, state *GameState) CommandResult {
 	}
 
 	if len(args) == 0 {
-		// Show latest commit by default
-		if len(state.Commits) == 0 {
+		// Show the tip of the current branch by default
+		history := currentHistory(state)
+		if len(history) == 0 {
 			return CommandResult{
 				Success:   false,
 				Message:   "No commits yet",
@@ -550,15 +1057,24 @@ func (c *ShowCommand) Execute(args []string, state *GameState) CommandResult {
 			}
 		}
 
-		commit := state.Commits[len(state.Commits)-1]
-		return c.showCommit(commit)
+		commit, _ := state.findCommit(history[len(history)-1])
+		return c.showCommit(state, commit)
 	}
 
-	// Find commit by ID (partial match)
+	// Resolve a revision such as HEAD~1, a branch name or a partial ID
 	commitID := args[0]
-	for _, commit := range state.Commits {
-		if strings.HasPrefix(commit.ID, commitID) {
-			return c.showCommit(commit)
+	if id, err := resolveRevision(state, commitID); err == nil {
+		if commit, found := state.findCommit(id); found {
+			return c.showCommit(state, commit)
+		}
+	}
+
+	// Blobs can be shown by hash, e.g. the dangling ones git fsck reports
+	if hash, found := findObjectByPrefix(state, commitID); found {
+		return CommandResult{
+			Success:   true,
+			Message:   state.Objects[hash],
+			SCPEffect: fmt.Sprintf("📄 Document %s retrieved from the object store", hash),
 		}
 	}
 
@@ -570,12 +1086,13 @@ func (c *ShowCommand) Execute(args []string, state *GameState) CommandResult {
 	}
 }
 
-func (c *ShowCommand) showCommit(commit Commit) CommandResult {
+func (c *ShowCommand) showCommit(state *GameState, commit Commit) CommandResult {
 	var show strings.Builder
 	show.WriteString(fmt.Sprintf("commit %s\n", commit.ID))
 	show.WriteString(fmt.Sprintf("Author: %s\n", commit.Author))
 	show.WriteString(fmt.Sprintf("Date:   %s\n", commit.Timestamp.Format("Mon Jan 02 15:04:05 2006")))
-	show.WriteString(fmt.Sprintf("\n    %s\n\n", commit.Message))
+	show.WriteString(fmt.Sprintf("\n%s\n\n", indentMessage(commit.Message)))
+	show.WriteString(notesSection(state, commit.ID))
 	show.WriteString("Files in this commit:\n")
 	for filename, hash := range commit.Files {
 		show.WriteString(fmt.Sprintf("    %s [%s]\n", filename, hash))
@@ -596,64 +1113,74 @@ func (c *ShowCommand) RequiredArgs() int {
 	return 0
 }
 
-// BranchCommand implements git branch
-type BranchCommand struct{}
-
-func (c *BranchCommand) Execute(args []string, state *GameState) CommandResult {
-	if !state.IsInitialized {
-		return CommandResult{
-			Success:      false,
-			Message:      "fatal: not a git repository",
-			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
-			AnomalyDelta: 1,
+// checkoutFiles moves the working directory and index from HEAD's tree to
+// the tip of the target history, as a branch switch does. Files both commits
+// agree on keep their local changes; local changes to any other file refuse
+// the switch.
+func checkoutFiles(state *GameState, target []string) (CommandResult, bool) {
+	tip := ""
+	if len(target) > 0 {
+		tip = target[len(target)-1]
+	}
+	from, to := treeOf(state, headCommitID(state)), treeOf(state, tip)
+	var changed []string
+	for filename := range from {
+		if from[filename] != to[filename] {
+			changed = append(changed, filename)
 		}
 	}
-
-	// List branches if no args
-	if len(args) == 0 {
-		var branches strings.Builder
-		for branch := range state.Branches {
-			if branch == state.CurrentBranch {
-				branches.WriteString(fmt.Sprintf("* %s\n", branch))
-			} else {
-				branches.WriteString(fmt.Sprintf("  %s\n", branch))
-			}
+	for filename := range to {
+		if _, exists := from[filename]; !exists {
+			changed = append(changed, filename)
 		}
-
-		return CommandResult{
-			Success:   true,
-			Message:   branches.String(),
-			SCPEffect: "📋 Available containment branches listed",
+	}
+	sort.Strings(changed)
+
+	var local, untracked []string
+	for _, filename := range changed {
+		before, after := from[filename], to[filename]
+		file, exists := state.WorkingDir[filename]
+		staged, isStaged := state.StagingArea[filename]
+		switch {
+		case isStaged && staged.Hash != before && staged.Hash != after:
+			local = append(local, filename)
+		case exists && file.Hash == after, !exists && after == "":
+			// Already what the target has
+		case before != "" && (exists || state.Sparse.includes(filename)) && file.Hash != before:
+			local = append(local, filename)
+		case before == "" && exists:
+			untracked = append(untracked, filename)
 		}
 	}
 
-	// Create new branch
-	branchName := args[0]
-	if _, exists := state.Branches[branchName]; exists {
+	switch {
+	case len(local) > 0:
 		return CommandResult{
 			Success:      false,
-			Message:      fmt.Sprintf("fatal: A branch named '%s' already exists", branchName),
-			SCPEffect:    "⚠️  WARNING: Duplicate containment branch rejected",
+			Message:      fmt.Sprintf("error: Your local changes to the following files would be overwritten by checkout:\n\t%s\nPlease commit your changes or stash them before you switch branches.\nAborting", strings.Join(local, "\n\t")),
+			SCPEffect:    "⚠️  Uncommitted research would be lost - commit or stash it first",
 			AnomalyDelta: 1,
-		}
+		}, false
+	case len(untracked) > 0:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: The following untracked working tree files would be overwritten by checkout:\n\t%s\nPlease move or remove them before you switch branches.\nAborting", strings.Join(untracked, "\n\t")),
+			SCPEffect:    "⚠️  Unrecorded files are in the way - move or remove them first",
+			AnomalyDelta: 1,
+		}, false
 	}
 
-	// Copy current branch commits to new branch
-	state.Branches[branchName] = append([]string{}, state.Branches[state.CurrentBranch]...)
-
-	return CommandResult{
-		Success:   true,
-		Message:   fmt.Sprintf("Created branch '%s'", branchName),
-		SCPEffect: fmt.Sprintf("✅ New containment branch '%s' established", branchName),
+	for _, filename := range changed {
+		delete(state.StagingArea, filename)
+		hash, tracked := to[filename]
+		switch {
+		case !tracked:
+			delete(state.WorkingDir, filename)
+		case state.Sparse.includes(filename):
+			state.WorkingDir[filename] = FileState{Content: state.Objects[hash], Hash: hash}
+		}
 	}
-}
-
-func (c *BranchCommand) Help() string {
-	return "Create or list containment branches"
-}
-
-func (c *BranchCommand) RequiredArgs() int {
-	return 0
+	return CommandResult{}, true
 }
 
 // CheckoutCommand implements git checkout
@@ -684,7 +1211,11 @@ func (c *CheckoutCommand) Execute(args []string, state *GameState) CommandResult
 		// Handle -b flag for creating and switching
 		if len(args) >= 2 && args[0] == "-b" {
 			branchName = args[1]
-			state.Branches[branchName] = append([]string{}, state.Branches[state.CurrentBranch]...)
+			if !validBranchName(branchName) {
+				return invalidBranchName(branchName)
+			}
+			state.Branches[branchName] = append([]string{}, currentHistory(state)...)
+			leaveDetachedHead(state, state.Branches[branchName])
 			state.CurrentBranch = branchName
 
 			return CommandResult{
@@ -702,13 +1233,35 @@ func (c *CheckoutCommand) Execute(args []string, state *GameState) CommandResult
 		}
 	}
 
+	if holder, busy := checkedOutElsewhere(state, branchName); busy {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' is already checked out at '%s'", branchName, holder),
+			SCPEffect:    "🔴 ERROR: That strategy is already under test at another site",
+			AnomalyDelta: 2,
+		}
+	}
+
+	abandoned := ""
+	if state.CurrentBranch != branchName {
+		if refusal, ok := checkoutFiles(state, state.Branches[branchName]); !ok {
+			return refusal
+		}
+		abandoned = leaveDetachedHead(state, state.Branches[branchName])
+	}
 	state.CurrentBranch = branchName
 
-	return CommandResult{
+	result := CommandResult{
 		Success:   true,
 		Message:   fmt.Sprintf("Switched to branch '%s'", branchName),
 		SCPEffect: fmt.Sprintf("✅ Containment branch switched to '%s'", branchName),
 	}
+	if abandoned != "" {
+		result.Message = abandoned + "\n" + result.Message
+		result.SCPEffect = "⚠️  WARNING: Research made on a detached HEAD was abandoned"
+		result.AnomalyDelta = 2
+	}
+	return result
 }
 
 func (c *CheckoutCommand) Help() string {
@@ -751,6 +1304,9 @@ func (c *SwitchCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 
 		branchName := args[1]
+		if !validBranchName(branchName) {
+			return invalidBranchName(branchName)
+		}
 
 		// Check if branch already exists
 		if _, exists := state.Branches[branchName]; exists {
@@ -763,7 +1319,8 @@ func (c *SwitchCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 
 		// Create new branch and switch to it
-		state.Branches[branchName] = append([]string{}, state.Branches[state.CurrentBranch]...)
+		state.Branches[branchName] = append([]string{}, currentHistory(state)...)
+		leaveDetachedHead(state, state.Branches[branchName])
 		state.CurrentBranch = branchName
 
 		return CommandResult{
@@ -785,13 +1342,35 @@ func (c *SwitchCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
+	if holder, busy := checkedOutElsewhere(state, branchName); busy {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' is already used by worktree at '%s'", branchName, holder),
+			SCPEffect:    "🔴 ERROR: That strategy is already under test at another site",
+			AnomalyDelta: 2,
+		}
+	}
+
+	abandoned := ""
+	if state.CurrentBranch != branchName {
+		if refusal, ok := checkoutFiles(state, state.Branches[branchName]); !ok {
+			return refusal
+		}
+		abandoned = leaveDetachedHead(state, state.Branches[branchName])
+	}
 	state.CurrentBranch = branchName
 
-	return CommandResult{
+	result := CommandResult{
 		Success:   true,
 		Message:   fmt.Sprintf("Switched to branch '%s'", branchName),
 		SCPEffect: fmt.Sprintf("✅ Containment branch switched to '%s'", branchName),
 	}
+	if abandoned != "" {
+		result.Message = abandoned + "\n" + result.Message
+		result.SCPEffect = "⚠️  WARNING: Research made on a detached HEAD was abandoned"
+		result.AnomalyDelta = 2
+	}
+	return result
 }
 
 func (c *SwitchCommand) Help() string {
@@ -815,7 +1394,35 @@ func (c *MergeCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	if len(args) == 0 {
+	if containsString(args, "--abort") {
+		return abortMerge(state)
+	}
+	if state.Merge != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      "error: Merging is not possible because you have unmerged files.\nfatal: You have not concluded your merge (MERGE_HEAD exists).\nPlease, commit your changes before you merge.",
+			SCPEffect:    "⚠️  A merge is already in progress - resolve and commit it, or git merge --abort",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// merge.ff sets the default; --ff, --no-ff and --ff-only override it
+	ffMode, _ := configValue(state, "merge.ff")
+	var positional []string
+	for _, arg := range args {
+		switch arg {
+		case "--ff":
+			ffMode = "true"
+		case "--no-ff":
+			ffMode = "false"
+		case "--ff-only":
+			ffMode = "only"
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) == 0 {
 		return CommandResult{
 			Success:   false,
 			Message:   "fatal: No branch name specified",
@@ -823,10 +1430,10 @@ func (c *MergeCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	sourceBranch := args[0]
+	sourceBranch := positional[0]
 
-	// Check if source branch exists
-	sourceCommits, exists := state.Branches[sourceBranch]
+	// Check if source branch exists (local or remote-tracking)
+	sourceCommits, exists := resolveBranch(state, sourceBranch)
 	if !exists {
 		return CommandResult{
 			Success:      false,
@@ -845,28 +1452,17 @@ func (c *MergeCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	// Simulate merge by adding source branch commits to current branch
-	currentCommits := state.Branches[state.CurrentBranch]
+	currentCommits := currentHistory(state)
 
 	// Find commits that are in source but not in current
-	mergedCount := 0
+	var incoming []string
 	for _, commitID := range sourceCommits {
-		found := false
-		for _, existingID := range currentCommits {
-			if commitID == existingID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			currentCommits = append(currentCommits, commitID)
-			mergedCount++
+		if !containsString(currentCommits, commitID) {
+			incoming = append(incoming, commitID)
 		}
 	}
 
-	state.Branches[state.CurrentBranch] = currentCommits
-
-	if mergedCount == 0 {
+	if len(incoming) == 0 {
 		return CommandResult{
 			Success:   true,
 			Message:   "Already up to date.",
@@ -874,33 +1470,69 @@ func (c *MergeCommand) Execute(args []string, state *GameState) CommandResult {
 		}
 	}
 
-	// Create merge commit
-	author := "Dr. ████████"
-	if state.ConfigName != "" {
-		author = state.ConfigName
+	canFastForward := containsAll(sourceCommits, currentCommits)
+	if ffMode == "only" && !canFastForward {
+		return CommandResult{
+			Success:      false,
+			Message:      "hint: Diverging branches can't be fast-forwarded, you need to either:\nhint:\nhint: \tgit merge --no-ff\nhint:\nhint: or:\nhint:\nhint: \tgit rebase\nhint:\nfatal: Not possible to fast-forward, aborting.",
+			SCPEffect:    "⚠️  Containment timelines have diverged - a fast-forward is impossible",
+			AnomalyDelta: 1,
+		}
 	}
 
-	mergeCommit := Commit{
-		ID:        generateCommitID(),
-		Message:   fmt.Sprintf("Merge branch '%s' into %s", sourceBranch, state.CurrentBranch),
-		Author:    author,
-		Timestamp: time.Now(),
-		Files:     make(map[string]string),
-		Branch:    state.CurrentBranch,
+	// Work out the merged files, refusing to overwrite uncommitted work
+	head, tip := headCommitID(state), sourceCommits[len(sourceCommits)-1]
+	files, conflicts := mergeTrees(state, mergeBase(currentCommits, sourceCommits), head, tip, sourceBranch)
+	if refusal, refused := overwriteRefusal(state, files); refused {
+		return refusal
+	}
+	for filename, content := range files {
+		state.WorkingDir[filename] = FileState{Content: content, Hash: state.storeObject(content)}
 	}
 
-	// Add all current files to merge commit
-	for filename, fileState := range state.WorkingDir {
-		mergeCommit.Files[filename] = fileState.Hash
+	// Fast-forward when the current branch has nothing the source lacks
+	if canFastForward && ffMode != "false" {
+		from := "0000000"
+		if len(currentCommits) > 0 {
+			from = currentCommits[len(currentCommits)-1][:7]
+		}
+		to := sourceCommits[len(sourceCommits)-1][:7]
+		setCurrentHistory(state, append([]string{}, sourceCommits...))
+
+		return CommandResult{
+			Success:   true,
+			Message:   fmt.Sprintf("Updating %s..%s\nFast-forward\n %d commits integrated", from, to, len(incoming)),
+			SCPEffect: fmt.Sprintf("✅ Containment timeline advanced. %d protocols integrated.", len(incoming)),
+		}
 	}
 
-	state.Commits = append(state.Commits, mergeCommit)
-	state.Branches[state.CurrentBranch] = append(state.Branches[state.CurrentBranch], mergeCommit.ID)
+	// Stage the merged files; conflicted ones wait for the player to resolve them
+	merge := &MergeState{Source: sourceBranch, Head: tip, Incoming: incoming, Files: sortedNames(files), Conflicts: conflicts}
+	for filename, content := range files {
+		if !containsString(conflicts, filename) {
+			state.StagingArea[filename] = FileState{Content: content, Hash: hashContent(content), Staged: true}
+		}
+	}
+	state.Merge = merge
+	if len(conflicts) > 0 {
+		var message strings.Builder
+		for _, filename := range conflicts {
+			message.WriteString(fmt.Sprintf("Auto-merging %s\nCONFLICT (content): Merge conflict in %s\n", filename, filename))
+		}
+		message.WriteString("Automatic merge failed; fix conflicts and then commit the result.")
+		return CommandResult{
+			Success:      false,
+			Message:      message.String(),
+			SCPEffect:    fmt.Sprintf("⚠️  The strategies contradict each other in %d files - resolve the markers, stage them and commit", len(conflicts)),
+			AnomalyDelta: 2,
+		}
+	}
+	createCommit(state, merge.Message(state.CurrentBranch), false)
 
 	return CommandResult{
 		Success:   true,
-		Message:   fmt.Sprintf("Merged %d commits from '%s' into %s", mergedCount, sourceBranch, state.CurrentBranch),
-		SCPEffect: fmt.Sprintf("✅ Containment strategies merged. %d protocols integrated.", mergedCount),
+		Message:   fmt.Sprintf("Merged %d commits from '%s' into %s", len(incoming), sourceBranch, state.CurrentBranch),
+		SCPEffect: fmt.Sprintf("✅ Containment strategies merged. %d protocols integrated.", len(incoming)),
 	}
 }
 
@@ -912,6 +1544,46 @@ func (c *MergeCommand) RequiredArgs() int {
 	return 1
 }
 
+// indentMessage indents every line of a commit message the way git log does
+func indentMessage(message string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "    " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolveBranch looks up a local branch or a remote-tracking branch such as origin/main
+func resolveBranch(state *GameState, name string) ([]string, bool) {
+	if commits, exists := state.Branches[name]; exists {
+		return commits, true
+	}
+	commits, exists := state.RemoteBranches[name]
+	return commits, exists
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAll reports whether every element of subset is present in list
+func containsAll(list, subset []string) bool {
+	for _, item := range subset {
+		if !containsString(list, item) {
+			return false
+		}
+	}
+	return true
+}
+
 // Helper function to generate commit ID
 func generateCommitID() string {
 	h := sha1.New()
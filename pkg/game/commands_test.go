@@ -172,3 +172,394 @@ func TestCheckoutCommand(t *testing.T) {
 		t.Error("Should be on new-branch after checkout -b")
 	}
 }
+
+func TestBranchSwitchUpdatesFiles(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "a.txt", "a", "Add a")
+	commitFile(t, state, "notes.txt", "shared", "Add notes")
+
+	(&CheckoutCommand{}).Execute([]string{"-b", "tmp"}, state)
+	writeFile(state, "a.txt", "b")
+	(&CommitCommand{}).Execute([]string{"-am", "B"}, state)
+	commitFile(t, state, "new.txt", "tmp only", "Add new")
+
+	if result := (&CheckoutCommand{}).Execute([]string{"main"}, state); !result.Success {
+		t.Fatalf("checkout main failed: %s", result.Message)
+	}
+	if state.WorkingDir["a.txt"].Content != "a" || !statusClean(state) {
+		t.Errorf("Switching should check out main's files, got a.txt = %q", state.WorkingDir["a.txt"].Content)
+	}
+	if _, exists := state.WorkingDir["new.txt"]; exists {
+		t.Error("Files main does not have should be removed")
+	}
+
+	writeFile(state, "a.txt", "unsaved")
+	result := (&SwitchCommand{}).Execute([]string{"tmp"}, state)
+	if result.Success || !strings.Contains(result.Message, "would be overwritten by checkout:\n\ta.txt") || state.CurrentBranch != "main" {
+		t.Fatalf("A local change to a file the branches disagree on should refuse the switch, got %q", result.Message)
+	}
+	if state.WorkingDir["a.txt"].Content != "unsaved" {
+		t.Error("A refused switch should leave the file alone")
+	}
+
+	writeFile(state, "a.txt", "a")
+	writeFile(state, "new.txt", "mine")
+	if result = (&SwitchCommand{}).Execute([]string{"tmp"}, state); result.Success || !strings.Contains(result.Message, "untracked working tree files would be overwritten by checkout:\n\tnew.txt") {
+		t.Errorf("An untracked file in the way should refuse the switch, got %q", result.Message)
+	}
+	delete(state.WorkingDir, "new.txt")
+
+	writeFile(state, "notes.txt", "edited")
+	if result = (&SwitchCommand{}).Execute([]string{"tmp"}, state); !result.Success {
+		t.Fatalf("Local changes to files both branches agree on should carry over: %s", result.Message)
+	}
+	if state.WorkingDir["notes.txt"].Content != "edited" || state.WorkingDir["a.txt"].Content != "b" || state.WorkingDir["new.txt"].Content != "tmp only" {
+		t.Error("The switch should check out tmp's files and keep the edit")
+	}
+}
+
+func TestCommitMessageHandling(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	state.StagingArea["test.txt"] = FileState{Content: "test", Hash: "abc", Staged: true}
+
+	cmd := &CommitCommand{}
+
+	// Without -m the command asks for the editor instead of inventing a message
+	result := cmd.Execute([]string{}, state)
+	if result.Edit == nil {
+		t.Fatal("Commit without -m should request the editor")
+	}
+	if len(state.Commits) != 0 {
+		t.Error("No commit should be created before the editor closes")
+	}
+
+	// An editor buffer containing only comments aborts the commit
+	result = result.Edit.Finish("# only comments\n", state)
+	if result.Success || !strings.Contains(result.Message, "empty commit message") {
+		t.Errorf("Empty message should abort the commit, got %q", result.Message)
+	}
+
+	// Multi-line messages from the editor keep their body
+	result = cmd.Execute([]string{}, state)
+	result = result.Edit.Finish("Contain entity\n\nSealed the anomaly file.\n# comment", state)
+	if !result.Success {
+		t.Fatalf("Commit from editor should succeed: %s", result.Message)
+	}
+	if state.Commits[0].Message != "Contain entity\n\nSealed the anomaly file." {
+		t.Errorf("Unexpected commit message %q", state.Commits[0].Message)
+	}
+
+	// -m with an empty message is rejected like real Git
+	state.StagingArea["test.txt"] = FileState{Content: "test2", Hash: "def", Staged: true}
+	result = cmd.Execute([]string{"-m", ""}, state)
+	if result.Success {
+		t.Error("Empty -m message should be rejected")
+	}
+
+	// --allow-empty records a commit without staged files
+	state.StagingArea = make(map[string]FileState)
+	result = cmd.Execute([]string{"--allow-empty", "-m", "Checkpoint"}, state)
+	if !result.Success {
+		t.Errorf("--allow-empty should commit without changes: %s", result.Message)
+	}
+
+	// -F reads the message from a file in the working directory
+	state.WorkingDir["msg.txt"] = FileState{Content: "From file\n", Hash: "f1"}
+	result = cmd.Execute([]string{"--allow-empty", "-F", "msg.txt"}, state)
+	if !result.Success || state.Commits[len(state.Commits)-1].Message != "From file" {
+		t.Errorf("-F should read the message from the file, got %q", result.Message)
+	}
+}
+
+func TestCommitAllLeavesIndexOnFailure(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "a.txt", "a", "Add a")
+	writeFile(state, "a.txt", "b")
+	cmd := &CommitCommand{}
+
+	if result := cmd.Execute([]string{"-a", "-m", ""}, state); result.Success {
+		t.Fatal("An empty message should abort the commit")
+	}
+	if len(state.StagingArea) != 0 {
+		t.Errorf("A refused commit -a should not stage anything, got %v", sortedKeys(state.StagingArea))
+	}
+
+	result := cmd.Execute([]string{"-a"}, state)
+	result.Edit.Finish("# only comments\n", state)
+	if len(state.StagingArea) != 0 {
+		t.Error("An abandoned editor should not leave commit -a files staged")
+	}
+
+	if result = cmd.Execute([]string{"-a", "-m", "B"}, state); !result.Success || treeAt(state, headCommitID(state))["a.txt"] != hashContent("b") {
+		t.Fatalf("commit -a should record the tracked change: %s", result.Message)
+	}
+}
+
+func TestCommitAmend(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+
+	cmd := &CommitCommand{}
+
+	result := cmd.Execute([]string{"--amend", "-m", "Nothing"}, state)
+	if result.Success {
+		t.Error("Amend should fail without a commit to amend")
+	}
+
+	state.StagingArea["a.txt"] = FileState{Content: "a", Hash: "aaa", Staged: true}
+	cmd.Execute([]string{"-m", "Tpyo"}, state)
+	original := state.Branches["main"][0]
+
+	// Amend with new staged changes keeps the original files
+	state.StagingArea["b.txt"] = FileState{Content: "b", Hash: "bbb", Staged: true}
+	result = cmd.Execute([]string{"--amend", "-m", "Typo fixed"}, state)
+	if !result.Success {
+		t.Fatalf("Amend should succeed: %s", result.Message)
+	}
+	if len(state.Branches["main"]) != 1 || state.Branches["main"][0] == original {
+		t.Error("Amend should replace the branch tip")
+	}
+	amended, _ := state.findCommit(state.Branches["main"][0])
+	if amended.Message != "Typo fixed" || len(amended.Files) != 2 {
+		t.Errorf("Amended commit should have new message and both files, got %q with %d files",
+			amended.Message, len(amended.Files))
+	}
+
+	// --no-edit keeps the message
+	cmd.Execute([]string{"--amend", "--no-edit"}, state)
+	amended, _ = state.findCommit(state.Branches["main"][0])
+	if amended.Message != "Typo fixed" {
+		t.Errorf("--no-edit should keep the message, got %q", amended.Message)
+	}
+
+	// Amending a pushed commit is recorded
+	state.Remotes["origin"] = NewRemote("origin", DefaultRemoteURL)
+	(&PushCommand{}).Execute([]string{}, state)
+	result = cmd.Execute([]string{"--amend", "-m", "Rewritten"}, state)
+	if state.PublishedRewrites != 1 || result.AnomalyDelta == 0 {
+		t.Error("Amending a pushed commit should be tracked and penalized")
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	got := splitCommandLine(`git commit -m "Contain the entity" --author='Dr. Chen' a\ b`)
+	want := []string{"git", "commit", "-m", "Contain the entity", "--author=Dr. Chen", "a b"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d words, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Word %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestCleanCommand(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	state.WorkingDir[".gitignore"] = FileState{Content: "*.notes\n", Hash: "ign"}
+	state.WorkingDir["stray.tmp"] = FileState{Content: "entity was here", Hash: "s1"}
+	state.WorkingDir["private.notes"] = FileState{Content: "my research", Hash: "n1"}
+	state.StagingArea[".gitignore"] = state.WorkingDir[".gitignore"]
+
+	cmd := &CleanCommand{}
+
+	// Refuses without -f, -n or -i
+	result := cmd.Execute([]string{}, state)
+	if result.Success || !strings.Contains(result.Message, "refusing to clean") {
+		t.Errorf("Clean without -f should refuse, got %q", result.Message)
+	}
+
+	// Dry run lists but keeps files
+	result = cmd.Execute([]string{"-n"}, state)
+	if !strings.Contains(result.Message, "Would remove stray.tmp") || strings.Contains(result.Message, "private.notes") {
+		t.Errorf("Dry run should list only non-ignored untracked files, got %q", result.Message)
+	}
+	if _, exists := state.WorkingDir["stray.tmp"]; !exists {
+		t.Error("Dry run should not delete files")
+	}
+
+	// -X only targets ignored files
+	result = cmd.Execute([]string{"-nX"}, state)
+	if !strings.Contains(result.Message, "private.notes") || strings.Contains(result.Message, "stray.tmp") {
+		t.Errorf("-X should list only ignored files, got %q", result.Message)
+	}
+
+	// -fx wipes ignored research notes and is penalized
+	result = cmd.Execute([]string{"-fx"}, state)
+	if !result.Success || result.AnomalyDelta == 0 {
+		t.Error("Removing ignored notes with -x should succeed but raise the anomaly level")
+	}
+	if _, exists := state.WorkingDir["private.notes"]; exists {
+		t.Error("-fx should remove ignored files")
+	}
+	if _, exists := state.WorkingDir[".gitignore"]; !exists {
+		t.Error("Staged files must never be cleaned")
+	}
+}
+
+func TestCleanInteractive(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	state.WorkingDir["a.tmp"] = FileState{Content: "a", Hash: "a"}
+	state.WorkingDir["b.tmp"] = FileState{Content: "b", Hash: "b"}
+
+	result := (&CleanCommand{}).Execute([]string{"-i"}, state)
+	if result.Prompt == nil {
+		t.Fatal("clean -i should show its menu")
+	}
+	result = result.Prompt.Answer("4", state)
+	result = result.Prompt.Answer("y", state)
+	result = result.Prompt.Answer("n", state)
+	if result.Prompt != nil {
+		t.Fatal("ask each should finish after the last file")
+	}
+	if _, exists := state.WorkingDir["a.tmp"]; exists {
+		t.Error("Confirmed file should be removed")
+	}
+	if _, exists := state.WorkingDir["b.tmp"]; !exists {
+		t.Error("Declined file should be kept")
+	}
+}
+
+func TestResolveRevision(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "a.txt", "one", "First")
+	commitFile(t, state, "a.txt", "two", "Second")
+	commitFile(t, state, "a.txt", "three", "Third")
+	history := state.Branches["main"]
+
+	tests := []struct {
+		rev  string
+		want string
+	}{
+		{"HEAD", history[2]},
+		{"HEAD~2", history[0]},
+		{"main^", history[1]},
+		{"HEAD^^", history[0]},
+		{history[1][:7], history[1]},
+	}
+	for _, tt := range tests {
+		got, err := resolveRevision(state, tt.rev)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveRevision(%q) = %q, %v; want %q", tt.rev, got, err, tt.want)
+		}
+	}
+
+	if _, err := resolveRevision(state, "HEAD~3"); err == nil {
+		t.Error("Walking past the first commit should fail")
+	}
+}
+
+func TestGrepCommand(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "log.txt", "Day 1: quiet", "Day 1")
+	commitFile(t, state, "log.txt", "Day 1: quiet\nDay 2: [REDACTED] observed", "Day 2")
+
+	cmd := &GrepCommand{}
+
+	result := cmd.Execute([]string{"-n", "REDACTED"}, state)
+	if result.Message != "log.txt:2:Day 2: [REDACTED] observed" {
+		t.Errorf("Unexpected grep output %q", result.Message)
+	}
+
+	result = cmd.Execute([]string{"REDACTED", "HEAD~1"}, state)
+	if result.Success {
+		t.Error("Phrase should not exist in the earlier revision")
+	}
+
+	result = cmd.Execute([]string{"-c", "-i", "day", "HEAD"}, state)
+	if result.Message != "HEAD:log.txt:2" {
+		t.Errorf("Expected count per file with revision prefix, got %q", result.Message)
+	}
+
+	state.StagingArea["new.txt"] = FileState{Content: "[REDACTED] again", Hash: state.storeObject("[REDACTED] again"), Staged: true}
+	result = cmd.Execute([]string{"-l", "--cached", "REDACTED"}, state)
+	if result.Message != "log.txt\nnew.txt" {
+		t.Errorf("Expected matching files from the index, got %q", result.Message)
+	}
+}
+
+func TestWorktreeCommand(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "protocol.txt", "v1", "Initial protocol")
+
+	cmd := &WorktreeCommand{}
+
+	result := cmd.Execute([]string{"add", "-b", "hotfix", "../hotfix"}, state)
+	if !result.Success {
+		t.Fatalf("worktree add failed: %s", result.Message)
+	}
+	if state.Worktrees["../hotfix"].WorkingDir["protocol.txt"].Content != "v1" {
+		t.Error("New worktree should check out the committed files")
+	}
+
+	result = cmd.Execute([]string{"list"}, state)
+	if !strings.Contains(result.Message, "../hotfix") || !strings.Contains(result.Message, "[hotfix]") {
+		t.Errorf("Expected the new worktree in the list, got %q", result.Message)
+	}
+
+	result = (&SwitchCommand{}).Execute([]string{"hotfix"}, state)
+	if result.Success || !strings.Contains(result.Message, "already used by worktree") {
+		t.Errorf("Switching to a branch held by another worktree should fail, got %q", result.Message)
+	}
+
+	engine := &Engine{State: state}
+	engine.ProcessCommand("cd ../hotfix")
+	if state.CurrentWorktree != "../hotfix" || state.CurrentBranch != "hotfix" {
+		t.Fatalf("cd should activate the worktree, now at %s [%s]", state.CurrentWorktree, state.CurrentBranch)
+	}
+	state.WorkingDir["scratch.txt"] = FileState{Content: "draft", Hash: hashContent("draft")}
+
+	engine.ProcessCommand("cd")
+	if state.CurrentWorktree != MainWorktreePath || state.CurrentBranch != "main" {
+		t.Fatalf("cd with no path should return to the main worktree")
+	}
+	if _, exists := state.WorkingDir["scratch.txt"]; exists {
+		t.Error("Files from the linked worktree should not leak into the main one")
+	}
+
+	result = cmd.Execute([]string{"remove", "../hotfix"}, state)
+	if result.Success {
+		t.Error("Removing a worktree with untracked files should require --force")
+	}
+	result = cmd.Execute([]string{"remove", "--force", "../hotfix"}, state)
+	if !result.Success || len(state.Worktrees) != 0 {
+		t.Errorf("Forced remove should succeed: %s", result.Message)
+	}
+
+	cmd.Execute([]string{"add", "../audit", "main"}, state)
+	if len(state.Worktrees) != 0 {
+		t.Error("A branch checked out in the main worktree cannot be added again")
+	}
+	cmd.Execute([]string{"add", "../audit"}, state)
+	removeFiles([]string{"-r", "../audit"}, state)
+	cmd.Execute([]string{"prune"}, state)
+	if len(state.Worktrees) != 0 {
+		t.Error("prune should forget worktrees whose directories are missing")
+	}
+}
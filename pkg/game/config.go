@@ -0,0 +1,302 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// configScopes lists config scopes from lowest to highest precedence
+var configScopes = []string{"system", "global", "local"}
+
+var configKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*(\..+)?\.[A-Za-z][A-Za-z0-9-]*$`)
+
+// normalizeConfigKey lowercases the section and variable name of a key the
+// way git does; a subsection in the middle keeps its case
+func normalizeConfigKey(key string) string {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first < 0 {
+		return key
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
+
+// configValues returns every value of a key across all scopes, in precedence order
+func configValues(state *GameState, key string) []string {
+	key = normalizeConfigKey(key)
+	var values []string
+	for _, scope := range configScopes {
+		values = append(values, state.Config[scope][key]...)
+	}
+	return values
+}
+
+// configValue returns the effective value of a key: the last one set in the
+// highest scope
+func configValue(state *GameState, key string) (string, bool) {
+	values := configValues(state, key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// syncIdentity keeps the identity fields the rest of the game reads in step
+// with the effective user.name and user.email
+func syncIdentity(state *GameState) {
+	state.ConfigName, _ = configValue(state, "user.name")
+	state.ConfigEmail, _ = configValue(state, "user.email")
+}
+
+// expandAlias replaces a git alias with its definition. Built-in commands
+// cannot be shadowed, and aliases may refer to other aliases.
+func expandAlias(state *GameState, parts []string) ([]string, error) {
+	seen := make(map[string]bool)
+	for len(parts) > 1 {
+		if _, builtin := CommandRegistry[parts[1]]; builtin {
+			return parts, nil
+		}
+		definition, exists := configValue(state, "alias."+parts[1])
+		if !exists {
+			return parts, nil
+		}
+		if seen[parts[1]] {
+			return nil, fmt.Errorf("fatal: alias loop detected: expansion of '%s' does not terminate", parts[1])
+		}
+		seen[parts[1]] = true
+		if strings.HasPrefix(definition, "!") {
+			return nil, fmt.Errorf("fatal: shell alias '%s' cannot run inside containment", parts[1])
+		}
+
+		expanded := append([]string{"git"}, splitCommandLine(definition)...)
+		parts = append(expanded, parts[2:]...)
+	}
+	return parts, nil
+}
+
+// configOptions holds the parsed flags of a git config invocation
+type configOptions struct {
+	scope     string
+	action    string // get, get-all, list, unset, unset-all, add, replace-all, or "" to infer
+	showScope bool
+	args      []string
+}
+
+func parseConfigArgs(args []string) (configOptions, error) {
+	var opts configOptions
+	for _, arg := range args {
+		switch arg {
+		case "--system", "--global", "--local":
+			opts.scope = strings.TrimPrefix(arg, "--")
+		case "--get", "--get-all", "--unset", "--unset-all", "--add", "--replace-all", "--list":
+			opts.action = strings.TrimPrefix(arg, "--")
+		case "-l":
+			opts.action = "list"
+		case "--show-scope":
+			opts.showScope = true
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return opts, fmt.Errorf("error: unknown option `%s'", strings.TrimPrefix(arg, "--"))
+			}
+			opts.args = append(opts.args, arg)
+		}
+	}
+
+	if opts.action == "" {
+		switch len(opts.args) {
+		case 0:
+			return opts, fmt.Errorf("usage: git config [--global | --local | --system] <key> [<value>]")
+		case 1:
+			opts.action = "get"
+		default:
+			opts.action = "set"
+		}
+	}
+	return opts, nil
+}
+
+// ConfigCommand implements git config
+type ConfigCommand struct{}
+
+func (c *ConfigCommand) Execute(args []string, state *GameState) CommandResult {
+	opts, err := parseConfigArgs(args)
+	if err != nil {
+		return CommandResult{
+			Success:   false,
+			Message:   err.Error() + "\nCommon configurations:\n  git config user.name \"Your Name\"\n  git config user.email \"email@example.com\"",
+			SCPEffect: "⚠️  Researcher identity required for accountability",
+		}
+	}
+
+	if opts.action == "list" {
+		return c.list(state, opts)
+	}
+
+	if len(opts.args) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   fmt.Sprintf("error: wrong number of arguments, should be 1 for --%s", opts.action),
+			SCPEffect: "🔴 Unknown configuration parameter",
+		}
+	}
+	key := normalizeConfigKey(opts.args[0])
+	if !configKeyPattern.MatchString(key) {
+		message := fmt.Sprintf("error: invalid key: %s", opts.args[0])
+		if !strings.Contains(key, ".") {
+			message = fmt.Sprintf("error: key does not contain a section: %s", opts.args[0])
+		}
+		return CommandResult{
+			Success:      false,
+			Message:      message,
+			SCPEffect:    "🔴 Unknown configuration parameter",
+			AnomalyDelta: 1,
+		}
+	}
+
+	switch opts.action {
+	case "get", "get-all":
+		values := configValues(state, key)
+		if opts.scope != "" {
+			values = state.Config[opts.scope][key]
+		}
+		if len(values) == 0 {
+			return CommandResult{
+				Success: false,
+				Message: "",
+			}
+		}
+		if opts.action == "get" {
+			values = values[len(values)-1:]
+		}
+		return CommandResult{
+			Success: true,
+			Message: strings.Join(values, "\n"),
+		}
+	}
+
+	// Writes go to the repository unless a scope is given. The tutorial sets
+	// the identity before git init, so outside a repository they go global.
+	scope := opts.scope
+	if scope == "" {
+		scope = "global"
+		if state.IsInitialized {
+			scope = "local"
+		}
+	}
+	if scope == "local" && !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: --local can only be used inside a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+	if state.Config[scope] == nil {
+		state.Config[scope] = make(map[string][]string)
+	}
+	values := state.Config[scope][key]
+
+	switch opts.action {
+	case "unset", "unset-all":
+		if len(values) == 0 {
+			return CommandResult{
+				Success: false,
+				Message: "",
+			}
+		}
+		if len(values) > 1 && opts.action == "unset" {
+			return CommandResult{
+				Success: false,
+				Message: fmt.Sprintf("warning: %s has multiple values", key),
+			}
+		}
+		delete(state.Config[scope], key)
+
+	case "set", "add", "replace-all":
+		if len(opts.args) < 2 {
+			return CommandResult{
+				Success: false,
+				Message: "error: wrong number of arguments, should be 2",
+			}
+		}
+		value := strings.Join(opts.args[1:], " ")
+		switch {
+		case opts.action == "add":
+			state.Config[scope][key] = append(values, value)
+		case opts.action == "set" && len(values) > 1:
+			return CommandResult{
+				Success: false,
+				Message: fmt.Sprintf("warning: %s has multiple values\nerror: cannot overwrite multiple values with a single value\n       Use a regexp, --add or --replace-all to change %s.", key, key),
+			}
+		default:
+			state.Config[scope][key] = []string{value}
+		}
+	}
+
+	syncIdentity(state)
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: configEffect(state, key, opts.action),
+	}
+}
+
+// configEffect describes a config change in Foundation terms
+func configEffect(state *GameState, key, action string) string {
+	if strings.HasPrefix(action, "unset") {
+		return fmt.Sprintf("📋 Configuration parameter %s withdrawn", key)
+	}
+	switch {
+	case key == "user.name":
+		return fmt.Sprintf("✅ Researcher identity confirmed: Dr. %s", state.ConfigName)
+	case key == "user.email":
+		return "✅ Foundation contact protocol established"
+	case strings.HasPrefix(key, "alias."):
+		return fmt.Sprintf("✅ Protocol shorthand 'git %s' registered", strings.TrimPrefix(key, "alias."))
+	default:
+		return fmt.Sprintf("✅ Configuration parameter %s recorded", key)
+	}
+}
+
+func (c *ConfigCommand) list(state *GameState, opts configOptions) CommandResult {
+	scopes := configScopes
+	if opts.scope != "" {
+		scopes = []string{opts.scope}
+	}
+
+	var list strings.Builder
+	for _, scope := range scopes {
+		for _, key := range sortedConfigKeys(state.Config[scope]) {
+			for _, value := range state.Config[scope][key] {
+				if opts.showScope {
+					list.WriteString(scope + "\t")
+				}
+				list.WriteString(fmt.Sprintf("%s=%s\n", key, value))
+			}
+		}
+	}
+	return CommandResult{
+		Success: true,
+		Message: strings.TrimRight(list.String(), "\n"),
+	}
+}
+
+// sortedConfigKeys returns the keys of one config scope in lexical order
+func sortedConfigKeys(keys map[string][]string) []string {
+	names := make([]string, 0, len(keys))
+	for key := range keys {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *ConfigCommand) Help() string {
+	return "Get and set configuration (user.name, init.defaultBranch, alias.*)"
+}
+
+func (c *ConfigCommand) RequiredArgs() int {
+	return 1
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestConfigScopes(t *testing.T) {
+	state := NewGameState()
+	cmd := &ConfigCommand{}
+
+	// Before git init, unscoped writes land in the global scope
+	cmd.Execute([]string{"user.name", "Bright"}, state)
+	cmd.Execute([]string{"--global", "init.defaultBranch", "containment"}, state)
+	(&InitCommand{}).Execute(nil, state)
+	if state.CurrentBranch != "containment" {
+		t.Errorf("init should honor init.defaultBranch, got %q", state.CurrentBranch)
+	}
+
+	cmd.Execute([]string{"user.name", "Clef"}, state)
+	if result := cmd.Execute([]string{"--get", "user.name"}, state); result.Message != "Clef" {
+		t.Errorf("Local value should win, got %q", result.Message)
+	}
+	if result := cmd.Execute([]string{"--global", "user.name"}, state); result.Message != "Bright" {
+		t.Errorf("Global value should be unchanged, got %q", result.Message)
+	}
+	if state.ConfigName != "Clef" {
+		t.Errorf("Identity should follow the effective user.name, got %q", state.ConfigName)
+	}
+
+	cmd.Execute([]string{"--unset", "user.name"}, state)
+	if state.ConfigName != "Bright" {
+		t.Errorf("Unsetting the local value should fall back to global, got %q", state.ConfigName)
+	}
+
+	cmd.Execute([]string{"--add", "remote.origin.fetch", "+refs/heads/*"}, state)
+	cmd.Execute([]string{"--add", "remote.origin.fetch", "+refs/tags/*"}, state)
+	if result := cmd.Execute([]string{"remote.origin.fetch", "x"}, state); result.Success {
+		t.Error("Setting a multi-valued key without --replace-all should fail")
+	}
+	if result := cmd.Execute([]string{"--get-all", "remote.origin.fetch"}, state); result.Message != "+refs/heads/*\n+refs/tags/*" {
+		t.Errorf("Unexpected values %q", result.Message)
+	}
+
+	result := cmd.Execute([]string{"--list", "--show-scope"}, state)
+	want := "global\tinit.defaultbranch=containment\nglobal\tuser.name=Bright\nlocal\tremote.origin.fetch=+refs/heads/*\nlocal\tremote.origin.fetch=+refs/tags/*"
+	if result.Message != want {
+		t.Errorf("Unexpected list:\n%s", result.Message)
+	}
+
+	if result := cmd.Execute([]string{"nosection", "x"}, state); result.Success {
+		t.Error("Keys without a section should be rejected")
+	}
+}
+
+func TestConfigAliases(t *testing.T) {
+	engine := &Engine{State: NewGameState()}
+	engine.ProcessCommand("git init")
+	engine.ProcessCommand("git config alias.st status")
+	engine.ProcessCommand("git config alias.s st")
+
+	if result := engine.ProcessCommand("git s"); !result.Success {
+		t.Errorf("Nested alias should expand to git status: %s", result.Message)
+	}
+
+	engine.ProcessCommand("git config alias.loop loop2")
+	engine.ProcessCommand("git config alias.loop2 loop")
+	if result := engine.ProcessCommand("git loop"); result.Success {
+		t.Error("Alias loops should be detected")
+	}
+
+	engine.ProcessCommand("git config alias.status log")
+	parts, _ := expandAlias(engine.State, []string{"git", "status"})
+	if parts[1] != "status" {
+		t.Error("Aliases must not shadow built-in commands")
+	}
+}
+
+func TestMergeFastForwardConfig(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "a.txt", "1", "Base")
+	(&BranchCommand{}).Execute([]string{"feature"}, state)
+	(&SwitchCommand{}).Execute([]string{"feature"}, state)
+	commitFile(t, state, "b.txt", "1", "Feature")
+	(&SwitchCommand{}).Execute([]string{"main"}, state)
+
+	(&ConfigCommand{}).Execute([]string{"merge.ff", "false"}, state)
+	(&MergeCommand{}).Execute([]string{"feature"}, state)
+	head, _ := state.findCommit(headCommitID(state))
+	if head.Message != "Merge branch 'feature' into main" {
+		t.Errorf("merge.ff=false should always record a merge commit, got %q", head.Message)
+	}
+}
@@ -0,0 +1,335 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffOp is one line of a line-based diff
+type diffOp struct {
+	Kind byte // ' ' unchanged, '-' removed, '+' added
+	Text string
+}
+
+// Hunk is a contiguous range of diff operations, [Start, End) into the op list
+type Hunk struct {
+	Start int
+	End   int
+}
+
+// splitLines breaks file content into lines, ignoring a single trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a line diff between two texts using a longest common
+// subsequence table. Files in the game are small, so quadratic cost is fine.
+func diffLines(oldText, newText string) []diffOp {
+	return diffLineSlices(splitLines(oldText), splitLines(newText))
+}
+
+// diffLineSlices computes the line diff between two lists of lines
+func diffLineSlices(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
+
+// buildHunks groups changed lines with surrounding context, merging changes
+// whose context would overlap
+func buildHunks(ops []diffOp, context int) []Hunk {
+	var hunks []Hunk
+	for i := 0; i < len(ops); i++ {
+		if ops[i].Kind == ' ' {
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].End {
+			// Extend the previous hunk instead of starting a new one
+			start = hunks[len(hunks)-1].Start
+			hunks = hunks[:len(hunks)-1]
+		}
+
+		// Find the end of this run of changes
+		end := i
+		for end < len(ops) && ops[end].Kind != ' ' {
+			end++
+		}
+		i = end - 1
+
+		stop := end + context
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		hunks = append(hunks, Hunk{Start: start, End: stop})
+	}
+	return hunks
+}
+
+// hunkHeader formats the "@@ -a,b +c,d @@" line for a hunk
+func hunkHeader(ops []diffOp, h Hunk) string {
+	oldBefore, newBefore := 0, 0
+	for _, op := range ops[:h.Start] {
+		if op.Kind != '+' {
+			oldBefore++
+		}
+		if op.Kind != '-' {
+			newBefore++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, op := range ops[h.Start:h.End] {
+		if op.Kind != '+' {
+			oldCount++
+		}
+		if op.Kind != '-' {
+			newCount++
+		}
+	}
+
+	oldStart, newStart := oldBefore+1, newBefore+1
+	if oldCount == 0 {
+		oldStart = oldBefore
+	}
+	if newCount == 0 {
+		newStart = newBefore
+	}
+
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+}
+
+// hunkRange formats one side of a hunk header, omitting a count of one like git
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// formatHunk renders a hunk with its header and prefixed lines
+func formatHunk(ops []diffOp, h Hunk) string {
+	var out strings.Builder
+	out.WriteString(hunkHeader(ops, h) + "\n")
+	for _, op := range ops[h.Start:h.End] {
+		out.WriteString(string(op.Kind) + op.Text + "\n")
+	}
+	return out.String()
+}
+
+// splitHunk breaks a hunk into smaller hunks, one per run of changes.
+// Context between runs belongs to the earlier piece so pieces never overlap.
+func splitHunk(ops []diffOp, h Hunk) []Hunk {
+	var pieces []Hunk
+	start := h.Start
+	seenChange := false
+	for i := h.Start; i < h.End; i++ {
+		if ops[i].Kind == ' ' {
+			continue
+		}
+		if seenChange && ops[i-1].Kind == ' ' {
+			pieces = append(pieces, Hunk{Start: start, End: i})
+			start = i
+		}
+		seenChange = true
+	}
+	return append(pieces, Hunk{Start: start, End: h.End})
+}
+
+// applyHunks rebuilds text from a diff, taking the new side of selected hunks
+// and the old side everywhere else
+func applyHunks(ops []diffOp, selected []Hunk, trailingNewline bool) string {
+	inSelected := make([]bool, len(ops))
+	for _, h := range selected {
+		for i := h.Start; i < h.End; i++ {
+			inSelected[i] = true
+		}
+	}
+
+	var lines []string
+	for i, op := range ops {
+		switch {
+		case op.Kind == ' ':
+			lines = append(lines, op.Text)
+		case op.Kind == '-' && !inSelected[i]:
+			lines = append(lines, op.Text)
+		case op.Kind == '+' && inSelected[i]:
+			lines = append(lines, op.Text)
+		}
+	}
+
+	text := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		text += "\n"
+	}
+	return text
+}
+
+// unifiedDiff renders a git-style unified diff between two versions of a file
+func unifiedDiff(filename, oldText, newText string, isNew bool) string {
+	ops := diffLines(oldText, newText)
+	hunks := buildHunks(ops, diffContext)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n", filename, filename))
+	if isNew {
+		out.WriteString("new file mode 100644\n")
+		out.WriteString("--- /dev/null\n")
+	} else {
+		out.WriteString(fmt.Sprintf("--- a/%s\n", filename))
+	}
+	out.WriteString(fmt.Sprintf("+++ b/%s\n", filename))
+	for _, h := range hunks {
+		out.WriteString(formatHunk(ops, h))
+	}
+	return out.String()
+}
+
+// changeRegion replaces base lines [Start, End) with Lines
+type changeRegion struct {
+	Start, End int
+	Lines      []string
+	Ours       bool
+}
+
+// changeRegions lists the edits a diff makes, in base line coordinates
+func changeRegions(ops []diffOp, ours bool) []changeRegion {
+	var regions []changeRegion
+	base := 0
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			base++
+			i++
+			continue
+		}
+		region := changeRegion{Start: base, End: base, Ours: ours}
+		for ; i < len(ops) && ops[i].Kind != ' '; i++ {
+			if ops[i].Kind == '-' {
+				region.End++
+			} else {
+				region.Lines = append(region.Lines, ops[i].Text)
+			}
+		}
+		base = region.End
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// mergeLines performs a three-way merge of two edits of base. Overlapping
+// edits that disagree are written out between conflict markers labelled
+// with ourLabel and theirLabel; the second result reports whether any were.
+func mergeLines(base, ours, theirs, ourLabel, theirLabel string) (string, bool) {
+	baseLines := splitLines(base)
+	regions := append(changeRegions(diffLines(base, ours), true), changeRegions(diffLines(base, theirs), false)...)
+	sort.SliceStable(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+
+	// side renders base[lo:hi) with one side's regions applied
+	side := func(group []changeRegion, ours bool, lo, hi int) []string {
+		var lines []string
+		pos := lo
+		for _, r := range group {
+			if r.Ours != ours {
+				continue
+			}
+			lines = append(lines, baseLines[pos:r.Start]...)
+			lines = append(lines, r.Lines...)
+			pos = r.End
+		}
+		return append(lines, baseLines[pos:hi]...)
+	}
+
+	var merged []string
+	conflicted := false
+	pos := 0
+	for i := 0; i < len(regions); {
+		// Gather every region overlapping the current chunk
+		lo, hi := regions[i].Start, regions[i].End
+		group := []changeRegion{regions[i]}
+		hasOurs, hasTheirs := regions[i].Ours, !regions[i].Ours
+		for i++; i < len(regions) && (regions[i].Start < hi || regions[i].Start == lo); i++ {
+			if regions[i].End > hi {
+				hi = regions[i].End
+			}
+			group = append(group, regions[i])
+			hasOurs = hasOurs || regions[i].Ours
+			hasTheirs = hasTheirs || !regions[i].Ours
+		}
+
+		merged = append(merged, baseLines[pos:lo]...)
+		pos = hi
+
+		ourSide, theirSide := side(group, true, lo, hi), side(group, false, lo, hi)
+		switch {
+		case !hasTheirs:
+			merged = append(merged, ourSide...)
+		case !hasOurs || strings.Join(ourSide, "\n") == strings.Join(theirSide, "\n"):
+			merged = append(merged, theirSide...)
+		default:
+			conflicted = true
+			merged = append(merged, "<<<<<<< "+ourLabel)
+			merged = append(merged, ourSide...)
+			merged = append(merged, "=======")
+			merged = append(merged, theirSide...)
+			merged = append(merged, ">>>>>>> "+theirLabel)
+		}
+	}
+	merged = append(merged, baseLines[pos:]...)
+
+	text := strings.Join(merged, "\n")
+	if strings.HasSuffix(ours, "\n") && len(merged) > 0 {
+		text += "\n"
+	}
+	return text, conflicted
+}
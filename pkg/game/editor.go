@@ -0,0 +1,177 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EditorStatus tells the UI whether the line editor is still open
+type EditorStatus int
+
+const (
+	EditorOpen   EditorStatus = iota // keep reading commands
+	EditorSaved                      // the buffer was written; finish the edit
+	EditorClosed                     // quit without saving; abandon the edit
+)
+
+// EditorHelp lists the line editor's commands
+const EditorHelp = `  p [N[,M]]    print lines with numbers
+  r N <text>   replace line N
+  i N <text>   insert before line N
+  a <text>     append a line at the end
+  d N[,M]      delete lines N to M
+  w            save and close
+  q            quit (q! discards unsaved changes)
+  h            show this help`
+
+// LineEditor is the in-game editor for files, commit messages and other
+// text a command asks for. Like ed, it changes one line per command.
+type LineEditor struct {
+	Lines []string
+
+	trailingNewline bool
+	changed         bool
+	quitWarned      bool
+}
+
+// NewLineEditor opens a buffer holding text
+func NewLineEditor(text string) *LineEditor {
+	ed := &LineEditor{trailingNewline: text == "" || strings.HasSuffix(text, "\n")}
+	if text != "" {
+		ed.Lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+	return ed
+}
+
+// Text returns the buffer as it will be saved
+func (ed *LineEditor) Text() string {
+	text := strings.Join(ed.Lines, "\n")
+	if ed.trailingNewline && len(ed.Lines) > 0 {
+		text += "\n"
+	}
+	return text
+}
+
+// Print lists lines first to last (1-based, inclusive) with their numbers
+func (ed *LineEditor) Print(first, last int) string {
+	if len(ed.Lines) == 0 {
+		return "  (empty buffer)"
+	}
+	var out []string
+	for n := first; n <= last; n++ {
+		out = append(out, fmt.Sprintf("%3d| %s", n, ed.Lines[n-1]))
+	}
+	return strings.Join(out, "\n")
+}
+
+// lineRange parses "N" or "N,M" against lines 1..max
+func lineRange(spec string, max int) (int, int, error) {
+	firstSpec, lastSpec := spec, spec
+	if comma := strings.Index(spec, ","); comma >= 0 {
+		firstSpec, lastSpec = spec[:comma], spec[comma+1:]
+	}
+	first, err := strconv.Atoi(firstSpec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line number '%s'", firstSpec)
+	}
+	last, err := strconv.Atoi(lastSpec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line number '%s'", lastSpec)
+	}
+	if first < 1 || last > max || first > last {
+		return 0, 0, fmt.Errorf("no such line: %s (buffer has %d lines)", spec, max)
+	}
+	return first, last, nil
+}
+
+// Run executes one editor command and returns what to show the player
+func (ed *LineEditor) Run(input string) (string, EditorStatus) {
+	command, rest := strings.TrimSpace(input), ""
+	if space := strings.IndexAny(command, " \t"); space >= 0 {
+		command, rest = command[:space], strings.TrimLeft(command[space+1:], " \t")
+	}
+	if command != "q" {
+		ed.quitWarned = false
+	}
+
+	// Commands taking a line number and text: "r 3 new text"
+	lineAndText := func() (int, string, error) {
+		spec, text := rest, ""
+		if space := strings.IndexAny(rest, " \t"); space >= 0 {
+			spec, text = rest[:space], rest[space+1:]
+		}
+		n, err := strconv.Atoi(spec)
+		if err != nil {
+			return 0, "", fmt.Errorf("usage: %s N <text>", command)
+		}
+		return n, text, nil
+	}
+
+	switch command {
+	case "p", "print", "":
+		if rest == "" || len(ed.Lines) == 0 {
+			return ed.Print(1, len(ed.Lines)), EditorOpen
+		}
+		first, last, err := lineRange(rest, len(ed.Lines))
+		if err != nil {
+			return "? " + err.Error(), EditorOpen
+		}
+		return ed.Print(first, last), EditorOpen
+
+	case "r", "replace":
+		n, text, err := lineAndText()
+		if err == nil && (n < 1 || n > len(ed.Lines)) {
+			err = fmt.Errorf("no such line: %d (buffer has %d lines)", n, len(ed.Lines))
+		}
+		if err != nil {
+			return "? " + err.Error(), EditorOpen
+		}
+		ed.Lines[n-1] = text
+		ed.changed = true
+		return ed.Print(n, n), EditorOpen
+
+	case "i", "insert":
+		n, text, err := lineAndText()
+		if err == nil && (n < 1 || n > len(ed.Lines)+1) {
+			err = fmt.Errorf("cannot insert before line %d (buffer has %d lines)", n, len(ed.Lines))
+		}
+		if err != nil {
+			return "? " + err.Error(), EditorOpen
+		}
+		ed.Lines = append(ed.Lines[:n-1], append([]string{text}, ed.Lines[n-1:]...)...)
+		ed.changed = true
+		return ed.Print(n, n), EditorOpen
+
+	case "a", "append":
+		ed.Lines = append(ed.Lines, rest)
+		ed.changed = true
+		return ed.Print(len(ed.Lines), len(ed.Lines)), EditorOpen
+
+	case "d", "delete":
+		first, last, err := lineRange(rest, len(ed.Lines))
+		if err != nil {
+			return "? " + err.Error(), EditorOpen
+		}
+		ed.Lines = append(ed.Lines[:first-1], ed.Lines[last:]...)
+		ed.changed = true
+		return fmt.Sprintf("  %d lines deleted", last-first+1), EditorOpen
+
+	case "w", "wq", "x", "save":
+		return fmt.Sprintf("  %d lines written", len(ed.Lines)), EditorSaved
+
+	case "q", "quit":
+		if ed.changed && !ed.quitWarned {
+			ed.quitWarned = true
+			return "? unsaved changes: 'w' saves, 'q' again or 'q!' discards them", EditorOpen
+		}
+		return "  Edit abandoned", EditorClosed
+
+	case "q!":
+		return "  Edit abandoned", EditorClosed
+
+	case "h", "help", "?":
+		return EditorHelp, EditorOpen
+	}
+	return fmt.Sprintf("? unknown command '%s' (h for help)", command), EditorOpen
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineEditorCommands(t *testing.T) {
+	ed := NewLineEditor("<<<<<<< HEAD\nsafe\n=======\nunsafe\n>>>>>>> breach\n")
+
+	steps := []struct {
+		input  string
+		output string
+	}{
+		{"p 2", "  2| safe"},
+		{"d 3,5", "  3 lines deleted"},
+		{"d 1", "  1 lines deleted"},
+		{"r 1 contained", "  1| contained"},
+		{"i 1 # Status", "  1| # Status"},
+		{"a verified", "  3| verified"},
+		{"p", "  1| # Status\n  2| contained\n  3| verified"},
+		{"r 9 nothing", "? no such line: 9 (buffer has 3 lines)"},
+		{"d 2,1", "? no such line: 2,1 (buffer has 3 lines)"},
+		{"i x text", "? usage: i N <text>"},
+		{"zap", "? unknown command 'zap' (h for help)"},
+	}
+	for _, step := range steps {
+		if output, status := ed.Run(step.input); output != step.output || status != EditorOpen {
+			t.Errorf("%s: got %q (%v), want %q", step.input, output, status, step.output)
+		}
+	}
+
+	if _, status := ed.Run("w"); status != EditorSaved {
+		t.Fatal("w should save and close")
+	}
+	if ed.Text() != "# Status\ncontained\nverified\n" {
+		t.Errorf("Unexpected saved text %q", ed.Text())
+	}
+}
+
+func TestLineEditorQuit(t *testing.T) {
+	ed := NewLineEditor("")
+	if output, _ := ed.Run("p"); output != "  (empty buffer)" {
+		t.Errorf("An empty buffer should say so, got %q", output)
+	}
+	if _, status := ed.Run("q"); status != EditorClosed {
+		t.Error("q on an unchanged buffer should close at once")
+	}
+
+	ed = NewLineEditor("keep")
+	ed.Run("a more")
+	if output, status := ed.Run("q"); status != EditorOpen || !strings.Contains(output, "unsaved changes") {
+		t.Errorf("q with unsaved changes should warn first, got %q", output)
+	}
+	if _, status := ed.Run("q"); status != EditorClosed {
+		t.Error("A second q should discard the changes")
+	}
+	if ed.Text() != "keep\nmore" {
+		t.Errorf("A file without a final newline should stay that way, got %q", ed.Text())
+	}
+}
+
+func TestEditFileMarksModified(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+	engine.ProcessCommand("echo draft > report.txt")
+
+	result := engine.ProcessCommand("edit report.txt")
+	if result.Edit == nil || result.Edit.Initial != "draft\n" {
+		t.Fatalf("edit should open the file's content, got %+v", result)
+	}
+	ed := NewLineEditor(result.Edit.Initial)
+	ed.Run("r 1 final")
+	engine.FinishEdit(result.Edit, ed.Text())
+	if file := engine.State.WorkingDir["report.txt"]; file.Content != "final\n" || !file.Modified {
+		t.Errorf("Saving should write the file and mark it modified, got %+v", file)
+	}
+
+	if result = engine.ProcessCommand("edit missing/report.txt"); result.Success {
+		t.Error("edit should refuse a file in a directory that does not exist")
+	}
+}
@@ -3,6 +3,7 @@ package game
 import (
 	"crypto/sha1"
 	"fmt"
+	"path"
 	"strings"
 )
 
@@ -11,6 +12,12 @@ type Engine struct {
 	State        *GameState
 	CurrentLevel *Level
 	LevelNum     int
+
+	// A command waiting on the editor or a prompt, settled once it finishes
+	pendingCommand *commandRun
+
+	// Hooks the current level installed, removed again when it is left
+	levelHooks map[string]string
 }
 
 // NewEngine creates a new game engine
@@ -32,14 +39,55 @@ func (e *Engine) StartLevel(levelNum int) error {
 	e.LevelNum = levelNum
 	e.State.CurrentLevel = levelNum
 
-	// Initialize working directory with level files
-	e.State.WorkingDir = make(map[string]FileState)
-	for filename, content := range level.InitialFiles {
-		e.State.WorkingDir[filename] = FileState{
-			Content:  content,
-			Modified: false,
-			Staged:   false,
-			Hash:     hashContent(content),
+	// Levels always open in the primary containment site
+	if err := e.State.switchWorktree(MainWorktreePath); err != nil {
+		return err
+	}
+
+	// Levels with a starting repository lay their files over its history;
+	// otherwise the level files are the whole working directory
+	if level.Repository != nil {
+		if err := buildRepository(e.State, level.Repository); err != nil {
+			return err
+		}
+		applyStartingChanges(e.State, level.InitialFiles, level.Repository.Staged)
+	} else {
+		e.State.WorkingDir = make(map[string]FileState)
+		for filename, content := range level.InitialFiles {
+			e.State.WorkingDir[filename] = FileState{
+				Content:  content,
+				Modified: false,
+				Staged:   false,
+				Hash:     hashContent(content),
+			}
+		}
+	}
+
+	// Levels may install safeguards the player has to work with. The last
+	// level's are taken down unless the player has since rewritten them.
+	for name, script := range e.levelHooks {
+		if e.State.Hooks[name] == script {
+			delete(e.State.Hooks, name)
+		}
+	}
+	e.levelHooks = make(map[string]string)
+	for name, script := range level.Hooks {
+		e.State.Hooks[name] = script
+		e.levelHooks[name] = script
+	}
+
+	// Levels with collaborators share a remote archive with them
+	e.State.CommandCount = 0
+	e.State.ActorProgress = make(map[string]int)
+	e.State.EntityProgress = make(map[string]int)
+	e.State.CommandHistory = nil
+	e.State.AwardedObjectives = make(map[string]bool)
+	if e.State.Awareness < level.Awareness.Start {
+		e.State.Awareness = level.Awareness.Start
+	}
+	if len(level.Actors) > 0 {
+		if _, exists := e.State.Remotes["origin"]; !exists {
+			e.State.Remotes["origin"] = NewRemote("origin", DefaultRemoteURL)
 		}
 	}
 
@@ -49,49 +97,100 @@ func (e *Engine) StartLevel(levelNum int) error {
 // ProcessCommand parses and executes a user command
 func (e *Engine) ProcessCommand(input string) CommandResult {
 	// Parse the command
-	parts := strings.Fields(input)
+	parts := splitCommandLine(input)
 	if len(parts) == 0 {
 		return CommandResult{
 			Success: false,
 			Message: "No command entered",
 		}
 	}
+	snapshot := takeSnapshot(e.State)
 
 	// Handle git commands
 	if parts[0] == "git" && len(parts) > 1 {
+		expanded, err := expandAlias(e.State, parts)
+		if err != nil {
+			e.State.IncreaseAnomaly(1)
+			return CommandResult{
+				Success:      false,
+				Message:      err.Error(),
+				SCPEffect:    "⚠️  Protocol shorthand could not be expanded",
+				AnomalyDelta: 1,
+			}
+		}
+		parts = expanded
+
+		// git -C <path> runs the command in a submodule's repository
+		state := e.State
+		if parts[1] == "-C" && len(parts) > 3 {
+			repo, err := e.repositoryAt(parts[2])
+			if err != nil {
+				return CommandResult{
+					Success:   false,
+					Message:   err.Error(),
+					SCPEffect: "⚠️  No repository at that location (see 'git submodule status')",
+				}
+			}
+			state = repo
+			parts = append([]string{"git"}, parts[3:]...)
+		}
+
 		gitCmd := parts[1]
 		args := parts[2:]
 
 		if cmd, exists := CommandRegistry[gitCmd]; exists {
-			result := cmd.Execute(args, e.State)
-
-			// Update game state based on result
-			e.State.IncreaseAnomaly(result.AnomalyDelta)
-
-			// Check for level completion
-			if e.CurrentLevel != nil {
-				if completed, msg := e.CurrentLevel.ValidateFunc(e.State); completed {
-					result.Success = true
-					result.Message += "\n\n" + msg
-					result.SCPEffect = "🎉 LEVEL COMPLETE! " + msg
-					e.State.Score += e.CurrentLevel.ScoreReward
-					e.State.CompletedLevels = append(e.State.CompletedLevels, e.LevelNum)
+			fromBranch, before := state.CurrentBranch, headCommitID(state)
+			revisions := namedCommits(state, args)
+			result := bindState(cmd.Execute(args, state), state, e.State)
+			result = trackReflog(result, state, gitCmd, args, fromBranch, before)
+			syncGitlinks(e.State)
+
+			// Give scripted researchers and the entity their turn
+			e.State.CommandCount++
+			announcements := e.runActors(gitCmd == "push" && result.Success)
+			if result.Success {
+				var config AwarenessConfig
+				if e.CurrentLevel != nil {
+					config = e.CurrentLevel.Awareness
+				}
+				announcements = append(announcements, observeCommand(e.State, config, gitCmd, args)...)
+			}
+			encounters := e.runEntity(gitCmd, result.Success)
+			announcements = append(announcements, encounters...)
+			for _, announcement := range announcements {
+				if result.SCPEffect != "" {
+					result.SCPEffect += "\n"
 				}
+				result.SCPEffect += announcement
 			}
 
-			return result
+			result = e.settleCommand(result, commandRun{git: true, name: gitCmd, args: args, revisions: revisions, before: snapshot, encounters: len(encounters)})
+			return e.applyResult(result)
 		}
 
-		return CommandResult{
+		return e.settleCommand(CommandResult{
 			Success:      false,
 			Message:      fmt.Sprintf("git: '%s' is not a git command", gitCmd),
 			SCPEffect:    "🔴 ERROR: Unknown containment protocol",
 			AnomalyDelta: 5,
-		}
+		}, commandRun{git: true, name: gitCmd, args: args, before: snapshot})
 	}
 
 	// Handle non-git commands
+	if builtin, exists := ShellBuiltins[parts[0]]; exists {
+		result := e.settleCommand(builtin(parts[1:], e.State), commandRun{name: parts[0], args: parts[1:], before: snapshot})
+		return e.applyResult(result)
+	}
 	switch parts[0] {
+	case "cd":
+		return e.changeWorktree(parts[1:])
+	case "edit":
+		return e.settleCommand(e.editFile(parts[1:]), commandRun{name: "edit", args: parts[1:], before: snapshot})
+	case "pwd":
+		return CommandResult{
+			Success: true,
+			Message: e.State.CurrentWorktree,
+		}
 	case "help":
 		return CommandResult{
 			Success: true,
@@ -103,7 +202,7 @@ func (e *Engine) ProcessCommand(input string) CommandResult {
 			Message: "", // UI will display status
 		}
 	case "brief", "briefing", "objective", "objectives":
-		// Re-display current level information
+		// Re-display current level information or its checklist
 		if e.CurrentLevel == nil {
 			return CommandResult{
 				Success:   false,
@@ -111,6 +210,13 @@ func (e *Engine) ProcessCommand(input string) CommandResult {
 				SCPEffect: "📋 No briefing available - start a containment protocol first",
 			}
 		}
+		if strings.HasPrefix(parts[0], "objective") {
+			return CommandResult{
+				Success:   true,
+				Message:   "", // UI will display the checklist
+				SCPEffect: "📋 Displaying containment objectives",
+			}
+		}
 		return CommandResult{
 			Success:   true,
 			Message:   "", // UI will display the level info
@@ -118,13 +224,231 @@ func (e *Engine) ProcessCommand(input string) CommandResult {
 		}
 	default:
 		e.State.IncreaseAnomaly(1)
-		return CommandResult{
+		return e.settleCommand(CommandResult{
 			Success:      false,
 			Message:      fmt.Sprintf("Command not found: %s", parts[0]),
 			SCPEffect:    "⚠️  Invalid Foundation protocol",
 			AnomalyDelta: 1,
+		}, commandRun{name: parts[0], args: parts[1:], before: snapshot})
+	}
+}
+
+// FinishEdit completes a command that was waiting on text from the in-game editor
+func (e *Engine) FinishEdit(req *EditRequest, text string) CommandResult {
+	return e.continueCommand(req.Finish(text, e.State))
+}
+
+// CancelEdit abandons a command whose editor was closed without saving. The
+// command is recorded as failed; nothing it would have written is kept.
+func (e *Engine) CancelEdit(req *EditRequest) CommandResult {
+	return e.continueCommand(CommandResult{
+		Success: false,
+		Message: "Editor closed without saving; nothing was written",
+	})
+}
+
+// AnswerPrompt passes the player's answer to a command waiting on a prompt
+func (e *Engine) AnswerPrompt(req *PromptRequest, input string) CommandResult {
+	return e.continueCommand(req.Answer(input, e.State))
+}
+
+// continueCommand applies the result of a follow-up step of a command. Only
+// explicit penalties raise the anomaly level; the command itself already counted.
+func (e *Engine) continueCommand(result CommandResult) CommandResult {
+	if result.AnomalyDelta > 0 {
+		e.State.IncreaseAnomaly(result.AnomalyDelta)
+	}
+	if pending := e.pendingCommand; pending != nil {
+		e.pendingCommand = nil
+		result = e.settleCommand(result, *pending)
+	}
+	return e.checkLevelComplete(result)
+}
+
+// applyResult updates game state from a command result and checks for level completion
+func (e *Engine) applyResult(result CommandResult) CommandResult {
+	// Update game state based on result
+	e.State.IncreaseAnomaly(result.AnomalyDelta)
+	return e.checkLevelComplete(result)
+}
+
+// checkLevelComplete awards the level when its validation passes
+func (e *Engine) checkLevelComplete(result CommandResult) CommandResult {
+	// Skip while the command is still waiting on the player
+	if result.Edit != nil || result.Prompt != nil {
+		return result
+	}
+
+	// Score objectives as they are met, then check for level completion
+	if e.CurrentLevel != nil {
+		met := e.awardObjectives()
+		for _, announcement := range met {
+			if result.SCPEffect != "" {
+				result.SCPEffect += "\n"
+			}
+			result.SCPEffect += announcement
+		}
+		if completed, msg := e.CurrentLevel.Validate(e.State); completed {
+			// A composed researcher earns a bonus on top of the reward
+			bonus := e.State.Sanity / 4
+			result.Success = true
+			result.Message += "\n\n" + msg
+			result.SCPEffect = strings.Join(append(met, fmt.Sprintf("🎉 LEVEL COMPLETE! %s (+%d composure bonus)", msg, bonus)), "\n")
+			e.State.Score += e.CurrentLevel.ScoreReward + bonus
+			e.State.CompletedLevels = append(e.State.CompletedLevels, e.LevelNum)
+		}
+	}
+
+	return result
+}
+
+// repositoryAt returns the repository at a path: the current one for "."
+// or the main worktree, otherwise a checked-out submodule
+func (e *Engine) repositoryAt(dir string) (*GameState, error) {
+	dir = strings.TrimPrefix(path.Clean(dir), e.State.CurrentWorktree+"/")
+	if dir == "." || dir == e.State.CurrentWorktree {
+		return e.State, nil
+	}
+	if sub, exists := e.State.Submodules[dir]; exists {
+		return sub.Repo, nil
+	}
+	return nil, fmt.Errorf("fatal: cannot change to '%s': No such file or directory", dir)
+}
+
+// bindState makes the follow-up steps of a command run against the
+// repository it started in, even though the UI hands back the engine's state
+func bindState(result CommandResult, state, engineState *GameState) CommandResult {
+	if state == engineState {
+		return result
+	}
+	if result.Edit != nil {
+		edit := *result.Edit
+		finish := edit.Finish
+		edit.Finish = func(text string, parent *GameState) CommandResult {
+			defer syncGitlinks(parent)
+			return bindState(finish(text, state), state, parent)
+		}
+		result.Edit = &edit
+	}
+	if result.Prompt != nil {
+		prompt := *result.Prompt
+		answer := prompt.Answer
+		prompt.Answer = func(input string, parent *GameState) CommandResult {
+			defer syncGitlinks(parent)
+			return bindState(answer(input, state), state, parent)
+		}
+		result.Prompt = &prompt
+	}
+	return result
+}
+
+// changeWorktree moves the player between linked worktrees. With no argument
+// it returns to the primary containment site.
+func (e *Engine) changeWorktree(args []string) CommandResult {
+	target := MainWorktreePath
+	if len(args) > 0 {
+		wtPath, exists := findWorktree(e.State, args[0])
+		if !exists {
+			return CommandResult{
+				Success:   false,
+				Message:   fmt.Sprintf("cd: %s: No such file or directory", args[0]),
+				SCPEffect: "⚠️  No containment site at that location (see 'git worktree list')",
+			}
+		}
+		target = wtPath
+	}
+
+	if err := e.State.switchWorktree(target); err != nil {
+		return CommandResult{
+			Success:   false,
+			Message:   err.Error(),
+			SCPEffect: "🔴 ERROR: Containment site unreachable",
+		}
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: fmt.Sprintf("📍 Now at containment site %s [%s]", e.State.CurrentWorktree, e.State.CurrentBranch),
+	}
+}
+
+// editFile opens a working file or a hook script in the in-game editor.
+// Saving an empty hook removes it.
+func (e *Engine) editFile(args []string) CommandResult {
+	if len(args) == 0 {
+		return CommandResult{
+			Success: false,
+			Message: "usage: edit <file>",
+		}
+	}
+	path := args[0]
+
+	if strings.HasPrefix(path, HookDir) {
+		name := strings.TrimPrefix(path, HookDir)
+		if !containsString(supportedHooks, name) {
+			return CommandResult{
+				Success:   false,
+				Message:   fmt.Sprintf("edit: %s: unsupported hook (use %s)", name, strings.Join(supportedHooks, ", ")),
+				SCPEffect: "⚠️  That safeguard is not wired into Foundation systems",
+			}
+		}
+		return CommandResult{
+			Success: true,
+			Message: "",
+			Edit: &EditRequest{
+				Title:   path,
+				Initial: e.State.Hooks[name],
+				Finish: func(text string, state *GameState) CommandResult {
+					if strings.TrimSpace(text) == "" {
+						delete(state.Hooks, name)
+						return CommandResult{
+							Success:   true,
+							Message:   "",
+							SCPEffect: fmt.Sprintf("🛡️  Safeguard %s removed", name),
+						}
+					}
+					state.Hooks[name] = text
+					return CommandResult{
+						Success:   true,
+						Message:   "",
+						SCPEffect: fmt.Sprintf("🛡️  Safeguard %s installed", name),
+					}
+				},
+			},
+		}
+	}
+
+	filename, err := writablePath(e.State, "edit", path)
+	if err != nil {
+		return CommandResult{
+			Success: false,
+			Message: err.Error(),
 		}
 	}
+	original, exists := e.State.WorkingDir[filename]
+
+	return CommandResult{
+		Success: true,
+		Message: "",
+		Edit: &EditRequest{
+			Title:   filename,
+			Initial: original.Content,
+			Finish: func(text string, state *GameState) CommandResult {
+				if exists && text == original.Content {
+					return CommandResult{
+						Success: true,
+						Message: fmt.Sprintf("\"%s\" unchanged", filename),
+					}
+				}
+				state.WorkingDir[filename] = FileState{Content: text, Hash: hashContent(text), Modified: true}
+				return CommandResult{
+					Success: true,
+					Message: fmt.Sprintf("\"%s\" written", filename),
+				}
+			},
+		},
+	}
 }
 
 // IsLevelComplete checks if the current level is complete
@@ -133,7 +457,7 @@ func (e *Engine) IsLevelComplete() bool {
 		return false
 	}
 
-	completed, _ := e.CurrentLevel.ValidateFunc(e.State)
+	completed, _ := e.CurrentLevel.Validate(e.State)
 	return completed
 }
 
@@ -145,6 +469,52 @@ func (e *Engine) GetNextLevel() int {
 	return 0
 }
 
+// splitCommandLine splits input into words, honouring single and double quotes
+// and backslash escapes the way a shell would
+func splitCommandLine(input string) []string {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			current.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words
+}
+
 // Helper function to generate file hash
 func hashContent(content string) string {
 	h := sha1.New()
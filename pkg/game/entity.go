@@ -0,0 +1,198 @@
+package game
+
+import (
+	"strings"
+	"time"
+)
+
+// Kinds of change the entity makes to the working directory
+const (
+	EntityAppend  = "append"  // add Content to the end of File, creating it if needed
+	EntityRewrite = "rewrite" // replace File's content with Content
+	EntityCreate  = "create"  // create File with Content unless it exists
+	EntityDelete  = "delete"  // remove File
+
+	// History changes, which scripts usually gate on awareness
+	EntityForgeCommit   = "forge-commit"   // commit File with Content to the current branch as the entity
+	EntityCreateBranch  = "create-branch"  // create Branch at HEAD
+	EntityRewriteCommit = "rewrite-commit" // replace HEAD's message with CommitMessage, keeping its ID
+)
+
+// EntityAuthor signs the commits the entity forges
+const EntityAuthor = "SCP-████"
+
+// EntityTrigger describes when an entity behavior takes its next action.
+// The timing conditions work like ActorTrigger's; MinAnomaly and
+// MinAwareness gate all of them.
+type EntityTrigger struct {
+	AfterCommands int    `yaml:"after_commands"` // act once the player has run this many git commands
+	EveryCommands int    `yaml:"every_commands"` // act on every Nth git command; twice as often at anomaly 50+
+	AfterCommand  string `yaml:"after_command"`  // act right after this git command succeeds, e.g. "commit"
+	MinAnomaly    int    `yaml:"min_anomaly"`    // stay dormant until the anomaly level reaches this
+	MinAwareness  int    `yaml:"min_awareness"`  // stay dormant until the entity is this aware
+}
+
+// EntityAction is one change the entity makes to the files or history
+type EntityAction struct {
+	Kind          string `yaml:"kind"`
+	File          string `yaml:"file"`
+	Content       string `yaml:"content"`
+	Branch        string `yaml:"branch"`         // for create-branch
+	CommitMessage string `yaml:"commit_message"` // for forge-commit and rewrite-commit
+	Message       string `yaml:"message"`        // announcement; a generic warning when empty
+}
+
+// EntityBehavior is a script of file changes the level's entity works
+// through, one action per activation
+type EntityBehavior struct {
+	Name    string         `yaml:"name"`
+	Trigger EntityTrigger  `yaml:"trigger"`
+	Actions []EntityAction `yaml:"actions"`
+}
+
+// isDue reports whether the behavior's trigger fires after a git command
+func (b *EntityBehavior) isDue(state *GameState, gitCmd string, success bool) bool {
+	t := b.Trigger
+	if state.AnomalyLevel < t.MinAnomaly || state.Awareness < t.MinAwareness {
+		return false
+	}
+
+	every := t.EveryCommands
+	if every > 1 && state.AnomalyLevel >= 50 {
+		every = (every + 1) / 2 // rounded up so odd intervals never fire more than twice as often
+	}
+	switch {
+	case t.AfterCommand != "" && t.AfterCommand == gitCmd && success:
+		return true
+	case t.AfterCommands > 0 && state.CommandCount >= t.AfterCommands && state.EntityProgress[b.Name] == 0:
+		return true
+	case every > 0 && state.CommandCount%every == 0:
+		return true
+	}
+	return false
+}
+
+// act applies the behavior's next action to the working directory.
+// It returns an empty string when there was nothing to change.
+func (b *EntityBehavior) act(state *GameState) string {
+	next := state.EntityProgress[b.Name]
+	if next >= len(b.Actions) {
+		return ""
+	}
+	state.EntityProgress[b.Name] = next + 1
+
+	action := b.Actions[next]
+	if !applyEntityAction(state, action) {
+		return ""
+	}
+	if action.Message != "" {
+		return action.Message
+	}
+	return "👁️  Something in the containment files shifted. Check 'git status'."
+}
+
+// applyEntityAction makes one change and reports whether anything changed
+func applyEntityAction(state *GameState, action EntityAction) bool {
+	switch action.Kind {
+	case EntityForgeCommit, EntityCreateBranch, EntityRewriteCommit:
+		return applyEntityHistoryAction(state, action)
+	}
+
+	file, exists := state.WorkingDir[action.File]
+	content := action.Content
+	switch action.Kind {
+	case EntityAppend:
+		if exists && file.Content != "" && !strings.HasSuffix(file.Content, "\n") {
+			content = "\n" + content
+		}
+		content = file.Content + content
+	case EntityRewrite:
+		if exists && file.Content == content {
+			return false
+		}
+	case EntityCreate:
+		if exists {
+			return false
+		}
+	case EntityDelete:
+		if !exists {
+			return false
+		}
+		delete(state.WorkingDir, action.File)
+		return true
+	default:
+		return false
+	}
+	state.WorkingDir[action.File] = FileState{Content: content, Hash: hashContent(content), Modified: true}
+	return true
+}
+
+// applyEntityHistoryAction forges, branches from or rewrites commits on the
+// current branch. Nothing happens before the repository has a branch to work on.
+func applyEntityHistoryAction(state *GameState, action EntityAction) bool {
+	if !state.IsInitialized || state.CurrentBranch == DetachedHead {
+		return false
+	}
+	head := currentHistory(state)
+
+	switch action.Kind {
+	case EntityForgeCommit:
+		commit := Commit{
+			ID:        generateCommitID(),
+			Message:   action.CommitMessage,
+			Author:    EntityAuthor,
+			Timestamp: time.Now(),
+			Files:     map[string]string{action.File: state.storeObject(action.Content)},
+			Branch:    state.CurrentBranch,
+		}
+		state.Commits = append(state.Commits, commit)
+		if len(head) > 0 {
+			state.CommitGraph[commit.ID] = []string{head[len(head)-1]}
+		}
+		setCurrentHistory(state, append(head, commit.ID))
+		state.WorkingDir[action.File] = FileState{Content: action.Content, Hash: hashContent(action.Content)}
+		return true
+
+	case EntityCreateBranch:
+		if len(head) == 0 {
+			return false
+		}
+		if _, exists := state.Branches[action.Branch]; exists {
+			return false
+		}
+		state.Branches[action.Branch] = append([]string{}, head...)
+		return true
+
+	case EntityRewriteCommit:
+		if len(head) == 0 {
+			return false
+		}
+		for i := range state.Commits {
+			if state.Commits[i].ID == head[len(head)-1] {
+				state.Commits[i].Message = action.CommitMessage
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runEntity lets the current level's entity act after a git command and
+// returns the announcements for the changes it made
+func (e *Engine) runEntity(gitCmd string, success bool) []string {
+	if e.CurrentLevel == nil {
+		return nil
+	}
+
+	var announcements []string
+	for i := range e.CurrentLevel.Entity {
+		behavior := &e.CurrentLevel.Entity[i]
+		if !behavior.isDue(e.State, gitCmd, success) {
+			continue
+		}
+		if msg := behavior.act(e.State); msg != "" {
+			announcements = append(announcements, msg)
+		}
+	}
+	return announcements
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func newEntityTestEngine(behaviors ...EntityBehavior) *Engine {
+	engine := NewEngine()
+	engine.CurrentLevel = &Level{
+		Entity:       behaviors,
+		ValidateFunc: func(*GameState) (bool, string) { return false, "" },
+	}
+	engine.ProcessCommand("git init")
+	writeFile(engine.State, "log.txt", "Line 1")
+	return engine
+}
+
+func TestEntityMutatesFilesOnSchedule(t *testing.T) {
+	engine := newEntityTestEngine(EntityBehavior{
+		Name:    "writer",
+		Trigger: EntityTrigger{EveryCommands: 3},
+		Actions: []EntityAction{
+			{Kind: EntityAppend, File: "log.txt", Content: "Line 2\n"},
+			{Kind: EntityCreate, File: "notes/whisper.txt", Content: "hello\n", Message: "A new file appears"},
+			{Kind: EntityDelete, File: "log.txt"},
+		},
+	})
+
+	// Drive the turns directly so the anomaly level stays put
+	turn := func(n int) string {
+		engine.State.CommandCount = n
+		return strings.Join(engine.runEntity("status", true), "\n")
+	}
+	if announced := turn(2); announced != "" {
+		t.Errorf("The entity should not act before its turn, got %q", announced)
+	}
+	if announced := turn(3); !strings.Contains(announced, "Something in the containment files shifted") {
+		t.Errorf("The entity should announce its edit, got %q", announced)
+	}
+	if file := engine.State.WorkingDir["log.txt"]; file.Content != "Line 1\nLine 2\n" || !file.Modified {
+		t.Errorf("append should add a line, got %+v", file)
+	}
+
+	if announced := turn(6); announced != "A new file appears" {
+		t.Errorf("Custom announcements should be used, got %q", announced)
+	}
+	if _, exists := engine.State.WorkingDir["notes/whisper.txt"]; !exists {
+		t.Error("create should add the file")
+	}
+
+	turn(9)
+	if _, exists := engine.State.WorkingDir["log.txt"]; exists {
+		t.Error("delete should remove the file")
+	}
+	if announced := turn(12); announced != "" {
+		t.Errorf("A finished script should stay quiet, got %q", announced)
+	}
+	if engine.State.EntityProgress["writer"] != 3 {
+		t.Errorf("Every action should be used once, progress %d", engine.State.EntityProgress["writer"])
+	}
+}
+
+func TestEntityTriggers(t *testing.T) {
+	engine := newEntityTestEngine(
+		EntityBehavior{
+			Name:    "after-commit",
+			Trigger: EntityTrigger{AfterCommand: "commit"},
+			Actions: []EntityAction{{Kind: EntityRewrite, File: "log.txt", Content: "REWRITTEN"}},
+		},
+		EntityBehavior{
+			Name:    "dormant",
+			Trigger: EntityTrigger{EveryCommands: 1, MinAnomaly: 90},
+			Actions: []EntityAction{{Kind: EntityCreate, File: "awake.txt", Content: "awake"}, {Kind: EntityCreate, File: "again.txt", Content: "again"}},
+		},
+	)
+
+	engine.ProcessCommand("git add log.txt")
+	engine.ProcessCommand("git commit -m 'Record log'")
+	if engine.State.WorkingDir["log.txt"].Content != "REWRITTEN" {
+		t.Error("The entity should rewrite the file right after a commit")
+	}
+	status := engine.ProcessCommand("git status")
+	if !strings.Contains(status.Message, "modified:   log.txt") {
+		t.Errorf("The rewrite should show up as a modification, got %q", status.Message)
+	}
+	if _, exists := engine.State.WorkingDir["awake.txt"]; exists {
+		t.Error("A behavior should stay dormant below its anomaly threshold")
+	}
+
+	engine.State.AnomalyLevel = 95
+	engine.ProcessCommand("git status")
+	if _, exists := engine.State.WorkingDir["awake.txt"]; !exists {
+		t.Error("A behavior should wake once the anomaly level reaches its threshold")
+	}
+}
+
+func TestEntityActsFasterAtHighAnomaly(t *testing.T) {
+	behavior := EntityBehavior{Name: "pulse", Trigger: EntityTrigger{EveryCommands: 4}}
+	state := NewGameState()
+	state.CommandCount = 2
+	if behavior.isDue(state, "status", true) {
+		t.Error("Turn 2 of 4 should not fire at low anomaly")
+	}
+	state.AnomalyLevel = 50
+	if !behavior.isDue(state, "status", true) {
+		t.Error("High anomaly should halve the interval")
+	}
+}
+
+func TestEntityOddIntervalAtHighAnomaly(t *testing.T) {
+	behavior := EntityBehavior{Name: "pulse", Trigger: EntityTrigger{EveryCommands: 3}}
+	state := NewGameState()
+	state.AnomalyLevel = 60
+
+	fired := 0
+	for state.CommandCount = 1; state.CommandCount <= 12; state.CommandCount++ {
+		if behavior.isDue(state, "status", true) {
+			fired++
+		}
+	}
+	if fired != 6 {
+		t.Errorf("Every 3 commands should become every 2 above anomaly 50, fired %d times in 12", fired)
+	}
+}
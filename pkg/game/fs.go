@@ -0,0 +1,374 @@
+package game
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The working directory is a tree of directories, stored the way git's index
+// stores it: each file keyed by its slash-separated path from the worktree
+// root. Directories exist while they contain files.
+
+// normalizePath cleans a path typed by the player into a WorkingDir key.
+// Paths may not leave the worktree.
+func normalizePath(p string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	cleaned = strings.TrimPrefix(cleaned, "/")
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("fatal: %s: '%s' is outside repository", p, p)
+	}
+	return cleaned, nil
+}
+
+// parentDirs returns the directories containing a file, outermost first
+func parentDirs(filename string) []string {
+	var dirs []string
+	for dir := path.Dir(filename); dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// isGlob reports whether a pathspec uses wildcards
+func isGlob(spec string) bool {
+	return strings.ContainsAny(spec, "*?[")
+}
+
+// globRegexp translates a pathspec glob to a regexp. As in git, '*' and '?'
+// also match '/', so "*.log" matches logs in every directory.
+func globRegexp(glob string) *regexp.Regexp {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch ch := glob[i]; ch {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + class + "]")
+			i += end
+		default:
+			re.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	re.WriteString("$")
+	compiled, err := regexp.Compile(re.String())
+	if err != nil {
+		return regexp.MustCompile(`^` + regexp.QuoteMeta(glob) + `$`)
+	}
+	return compiled
+}
+
+// matchPathspec reports whether a pathspec selects a file: "." selects
+// everything, a directory selects the files under it, and a glob is matched
+// against the whole path
+func matchPathspec(spec, filename string) bool {
+	if isGlob(spec) {
+		return globRegexp(strings.TrimPrefix(spec, "./")).MatchString(filename)
+	}
+	cleaned, err := normalizePath(spec)
+	if err != nil {
+		return false
+	}
+	return cleaned == "." || cleaned == filename || strings.HasPrefix(filename, cleaned+"/")
+}
+
+// expandPathspecs returns the files selected by any of the pathspecs, in
+// lexical order, and the pathspecs that selected nothing
+func expandPathspecs(files []string, specs []string) ([]string, []string) {
+	selected := make(map[string]bool)
+	var unmatched []string
+	for _, spec := range specs {
+		matched := false
+		for _, filename := range files {
+			if matchPathspec(spec, filename) {
+				selected[filename] = true
+				matched = true
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, spec)
+		}
+	}
+
+	result := make([]string, 0, len(selected))
+	for filename := range selected {
+		result = append(result, filename)
+	}
+	sort.Strings(result)
+	return result, unmatched
+}
+
+// dirEntries lists what a directory directly contains: files by name and
+// subdirectories with a trailing slash
+func dirEntries(files []string, dir string) []string {
+	prefix := ""
+	if dir != "" && dir != "." {
+		prefix = strings.TrimSuffix(dir, "/") + "/"
+	}
+
+	seen := make(map[string]bool)
+	var entries []string
+	for _, filename := range files {
+		if !strings.HasPrefix(filename, prefix) {
+			continue
+		}
+		entry := strings.TrimPrefix(filename, prefix)
+		if slash := strings.Index(entry, "/"); slash >= 0 {
+			entry = entry[:slash+1]
+		}
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// PathCompletions lists the files and directories of the working tree for
+// tab completion; directories end in '/' so completion can descend into them
+func (gs *GameState) PathCompletions() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, filename := range sortedKeys(gs.WorkingDir) {
+		for _, dir := range parentDirs(filename) {
+			if !seen[dir] {
+				seen[dir] = true
+				paths = append(paths, dir+"/")
+			}
+		}
+		paths = append(paths, filename)
+	}
+	for dir := range gs.Directories {
+		if !seen[dir] {
+			paths = append(paths, dir+"/")
+		}
+	}
+	return paths
+}
+
+// trackedPaths lists every file in the index or the last commit
+func trackedPaths(state *GameState) map[string]bool {
+	tracked := make(map[string]bool)
+	if head := headCommitID(state); head != "" {
+		for filename := range treeAt(state, head) {
+			tracked[filename] = true
+		}
+	}
+	for filename := range state.StagingArea {
+		tracked[filename] = true
+	}
+	return tracked
+}
+
+// collapseUntracked replaces the files of a directory holding nothing tracked
+// with the directory itself ("logs/"), the way git status shows them
+func collapseUntracked(state *GameState, untracked []string) []string {
+	tracked := trackedPaths(state)
+	holdsTracked := make(map[string]bool)
+	for filename := range tracked {
+		for _, dir := range parentDirs(filename) {
+			holdsTracked[dir] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var entries []string
+	for _, filename := range untracked {
+		entry := filename
+		for _, dir := range parentDirs(filename) {
+			if !holdsTracked[dir] {
+				entry = dir + "/"
+				break
+			}
+		}
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// treeEntry is one line of a tree object
+type treeEntry struct {
+	Mode string
+	Type string
+	Hash string
+	Name string
+}
+
+// buildTrees groups a flat tree (path -> blob hash) into one tree object per
+// directory, keyed by directory path ("" for the root). Gitlinks become
+// commit entries, as submodules are in git.
+func buildTrees(state *GameState, files map[string]string) map[string][]treeEntry {
+	trees := map[string][]treeEntry{"": nil}
+	for filename := range files {
+		for _, dir := range parentDirs(filename) {
+			trees[dir] = nil
+		}
+	}
+
+	// Deepest directories first, so each tree's hash is known before its parent needs it
+	dirs := make([]string, 0, len(trees))
+	for dir := range trees {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool { return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/") })
+
+	for filename, hash := range files {
+		dir := path.Dir(filename)
+		if dir == "." {
+			dir = ""
+		}
+		entry := treeEntry{Mode: "100644", Type: "blob", Hash: hash, Name: path.Base(filename)}
+		if content := state.Objects[hash]; strings.HasPrefix(content, "Subproject commit ") {
+			entry = treeEntry{Mode: "160000", Type: "commit", Hash: strings.TrimSpace(strings.TrimPrefix(content, "Subproject commit ")), Name: entry.Name}
+		}
+		trees[dir] = append(trees[dir], entry)
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		parent := path.Dir(dir)
+		if parent == "." {
+			parent = ""
+		}
+		trees[parent] = append(trees[parent], treeEntry{Mode: "040000", Type: "tree", Hash: treeHash(trees[dir]), Name: path.Base(dir)})
+	}
+	for dir := range trees {
+		sort.Slice(trees[dir], func(i, j int) bool { return trees[dir][i].Name < trees[dir][j].Name })
+	}
+	return trees
+}
+
+// treeHash is the ID of a tree object, derived from its entries
+func treeHash(entries []treeEntry) string {
+	sorted := append([]treeEntry{}, entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	var content strings.Builder
+	for _, entry := range sorted {
+		content.WriteString(fmt.Sprintf("%s %s %s\t%s\n", entry.Mode, entry.Type, entry.Hash, entry.Name))
+	}
+	return hashContent(content.String())
+}
+
+// LsTreeCommand implements git ls-tree
+type LsTreeCommand struct{}
+
+func (c *LsTreeCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	recursive, nameOnly := false, false
+	var positional []string
+	for _, arg := range args {
+		switch arg {
+		case "-r":
+			recursive = true
+		case "--name-only", "--name-status":
+			nameOnly = true
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git ls-tree [-r] [--name-only] <tree-ish> [<path>...]",
+			SCPEffect: "⚠️  WARNING: Specify the containment record to inspect",
+		}
+	}
+
+	id, err := resolveRevision(state, positional[0])
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: Not a valid object name %s", positional[0]),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+	files := treeAt(state, id)
+	trees := buildTrees(state, files)
+
+	// A path ending in '/' lists that directory; otherwise the entry itself
+	dir, filter := "", positional[1:]
+	if len(filter) == 1 && strings.HasSuffix(filter[0], "/") {
+		dir = strings.TrimSuffix(filter[0], "/")
+		filter = nil
+		if _, exists := trees[dir]; !exists {
+			return CommandResult{Success: true, Message: ""}
+		}
+	}
+
+	var lines []string
+	var walk func(dir string)
+	walk = func(dir string) {
+		for _, entry := range trees[dir] {
+			full := entry.Name
+			if dir != "" {
+				full = dir + "/" + entry.Name
+			}
+			if entry.Type == "tree" && recursive {
+				walk(full)
+				continue
+			}
+			if len(filter) > 0 && !containsString(filter, full) && !containsString(filter, full+"/") {
+				matched := false
+				for _, spec := range filter {
+					matched = matched || strings.HasPrefix(spec, full+"/")
+				}
+				if !matched {
+					continue
+				}
+				if entry.Type == "tree" {
+					walk(full)
+				}
+				continue
+			}
+			if nameOnly {
+				lines = append(lines, full)
+			} else {
+				lines = append(lines, fmt.Sprintf("%s %s %s\t%s", entry.Mode, entry.Type, entry.Hash, full))
+			}
+		}
+	}
+	walk(dir)
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.Join(lines, "\n"),
+		SCPEffect: fmt.Sprintf("📂 Containment record %s catalogued", id[:7]),
+	}
+}
+
+func (c *LsTreeCommand) Help() string {
+	return "List the directories and files of a commit"
+}
+
+func (c *LsTreeCommand) RequiredArgs() int {
+	return 1
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddDirectoriesAndPathspecs(t *testing.T) {
+	state := newReportTestState(t)
+	writeFile(state, "src/main.go", "package main")
+	writeFile(state, "src/util/strings.go", "package util")
+	writeFile(state, "logs/day1.log", "quiet")
+	writeFile(state, "debug.log", "noise")
+	add := &AddCommand{}
+
+	if result := add.Execute([]string{"src/"}, state); !result.Success {
+		t.Fatalf("add src/ failed: %s", result.Message)
+	}
+	for _, name := range []string{"src/main.go", "src/util/strings.go"} {
+		if _, staged := state.StagingArea[name]; !staged {
+			t.Errorf("%s should be staged by its directory", name)
+		}
+	}
+	if _, staged := state.StagingArea["logs/day1.log"]; staged {
+		t.Error("add src/ should not stage other directories")
+	}
+
+	if result := add.Execute([]string{"*.log"}, state); !result.Success {
+		t.Fatalf("add '*.log' failed: %s", result.Message)
+	}
+	for _, name := range []string{"logs/day1.log", "debug.log"} {
+		if _, staged := state.StagingArea[name]; !staged {
+			t.Errorf("*.log should match %s in any directory", name)
+		}
+	}
+
+	if result := add.Execute([]string{"missing/"}, state); result.Success {
+		t.Error("A pathspec matching nothing should fail")
+	}
+	if result := add.Execute([]string{"../outside.txt"}, state); result.Success {
+		t.Error("Paths outside the repository should be rejected")
+	}
+}
+
+func TestStatusCollapsesUntrackedDirectories(t *testing.T) {
+	state := newReportTestState(t)
+	writeFile(state, "logs/day1.log", "quiet")
+	writeFile(state, "logs/day2.log", "whispers")
+	writeFile(state, "notes.txt", "todo")
+
+	result := (&StatusCommand{}).Execute(nil, state)
+	if !strings.Contains(result.Message, "logs/") || strings.Contains(result.Message, "day1.log") {
+		t.Errorf("An untracked directory should be listed once, got %q", result.Message)
+	}
+
+	writeFile(state, ".gitignore", "logs/\n")
+	result = (&StatusCommand{}).Execute(nil, state)
+	if strings.Contains(result.Message, "logs/") {
+		t.Errorf("A directory pattern should ignore the files under it, got %q", result.Message)
+	}
+	if !isIgnored(state, "logs/day1.log") || isIgnored(state, "logs") {
+		t.Error("'logs/' should match files in the logs directory but not a file named logs")
+	}
+}
+
+func TestCleanDirectories(t *testing.T) {
+	state := newReportTestState(t)
+	writeFile(state, "tmp/scratch/one.txt", "1")
+	writeFile(state, "tmp/two.txt", "2")
+	writeFile(state, "stray.txt", "3")
+	cmd := &CleanCommand{}
+
+	result := cmd.Execute([]string{"-n"}, state)
+	if !strings.Contains(result.Message, "Would remove stray.txt") || strings.Contains(result.Message, "tmp") {
+		t.Errorf("Without -d, untracked directories should be left alone, got %q", result.Message)
+	}
+
+	result = cmd.Execute([]string{"-fd"}, state)
+	if !result.Success || !strings.Contains(result.Message, "Removing tmp/") {
+		t.Fatalf("-fd should remove the directory as a whole, got %q", result.Message)
+	}
+	for _, name := range []string{"tmp/scratch/one.txt", "tmp/two.txt", "stray.txt"} {
+		if _, exists := state.WorkingDir[name]; exists {
+			t.Errorf("%s should have been cleaned", name)
+		}
+	}
+	if _, exists := state.WorkingDir["a.txt"]; !exists {
+		t.Error("Tracked files must never be cleaned")
+	}
+}
+
+func TestLsTree(t *testing.T) {
+	state := newReportTestState(t)
+	commitFile(t, state, "docs/scp/173.txt", "statue", "Add 173 file")
+	cmd := &LsTreeCommand{}
+
+	result := cmd.Execute([]string{"HEAD"}, state)
+	lines := strings.Split(result.Message, "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], "040000 tree ") || !strings.HasSuffix(lines[2], "\tdocs") {
+		t.Fatalf("Unexpected top-level tree %q", result.Message)
+	}
+	if !strings.HasPrefix(lines[0], "100644 blob "+state.StagingArea["a.txt"].Hash) {
+		t.Errorf("Blob entries should carry their hash, got %q", lines[0])
+	}
+
+	result = cmd.Execute([]string{"-r", "--name-only", "HEAD"}, state)
+	if result.Message != "a.txt\nb.txt\ndocs/scp/173.txt" {
+		t.Errorf("Recursive listing should show every file, got %q", result.Message)
+	}
+
+	result = cmd.Execute([]string{"HEAD", "docs/"}, state)
+	if !strings.HasSuffix(result.Message, "\tdocs/scp") {
+		t.Errorf("A directory path should list its contents, got %q", result.Message)
+	}
+
+	// A tree's hash changes when anything beneath it does
+	before := buildTrees(state, treeAt(state, headCommitID(state)))[""]
+	commitFile(t, state, "docs/scp/173.txt", "statue moved", "Update 173")
+	after := buildTrees(state, treeAt(state, headCommitID(state)))[""]
+	if treeHash(before) == treeHash(after) || before[2].Hash == after[2].Hash {
+		t.Error("Changing a nested file should change every tree above it")
+	}
+
+	if result = cmd.Execute([]string{"nope"}, state); result.Success {
+		t.Error("ls-tree of an unknown revision should fail")
+	}
+}
@@ -0,0 +1,258 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// grepOptions holds the parsed flags of a git grep invocation
+type grepOptions struct {
+	lineNumbers bool
+	ignoreCase  bool
+	filesOnly   bool
+	count       bool
+	fixed       bool
+	cached      bool
+	pattern     string
+	revisions   []string
+	paths       []string
+}
+
+// parseGrepArgs parses git grep flags, the pattern, revisions and "-- paths"
+func parseGrepArgs(args []string) (grepOptions, error) {
+	var opts grepOptions
+	havePattern := false
+	afterDashes := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if afterDashes {
+			opts.paths = append(opts.paths, arg)
+			continue
+		}
+
+		switch arg {
+		case "--":
+			afterDashes = true
+		case "-n", "--line-number":
+			opts.lineNumbers = true
+		case "-i", "--ignore-case":
+			opts.ignoreCase = true
+		case "-l", "--files-with-matches", "--name-only":
+			opts.filesOnly = true
+		case "-c", "--count":
+			opts.count = true
+		case "-F", "--fixed-strings":
+			opts.fixed = true
+		case "-E", "--extended-regexp":
+			// Patterns are always treated as extended regular expressions
+		case "--cached":
+			opts.cached = true
+		case "-e":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("error: switch `e' requires a value")
+			}
+			i++
+			opts.pattern = args[i]
+			havePattern = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return opts, fmt.Errorf("error: unknown switch `%s'", strings.TrimLeft(arg, "-"))
+			}
+			if !havePattern {
+				opts.pattern = arg
+				havePattern = true
+			} else {
+				opts.revisions = append(opts.revisions, arg)
+			}
+		}
+	}
+
+	if !havePattern {
+		return opts, fmt.Errorf("fatal: no pattern given")
+	}
+	if opts.cached && len(opts.revisions) > 0 {
+		return opts, fmt.Errorf("fatal: both --cached and trees are given")
+	}
+	return opts, nil
+}
+
+// grepSource is one file version to search, with the prefix git prints before it
+type grepSource struct {
+	prefix  string
+	name    string
+	content string
+}
+
+// GrepCommand implements git grep
+type GrepCommand struct{}
+
+func (c *GrepCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	opts, err := parseGrepArgs(args)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Malformed forensic query",
+			AnomalyDelta: 1,
+		}
+	}
+
+	expr := opts.pattern
+	if opts.fixed {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if opts.ignoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: command line, '%s': %v", opts.pattern, err),
+			SCPEffect:    "🔴 ERROR: Malformed forensic query",
+			AnomalyDelta: 1,
+		}
+	}
+
+	sources, err := grepSources(state, opts)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+
+	var out strings.Builder
+	matchedFiles := 0
+	for _, src := range sources {
+		matches := 0
+		for n, line := range splitLines(src.content) {
+			if !re.MatchString(line) {
+				continue
+			}
+			matches++
+			if opts.filesOnly || opts.count {
+				continue
+			}
+			if opts.lineNumbers {
+				out.WriteString(fmt.Sprintf("%s%s:%d:%s\n", src.prefix, src.name, n+1, line))
+			} else {
+				out.WriteString(fmt.Sprintf("%s%s:%s\n", src.prefix, src.name, line))
+			}
+		}
+
+		if matches == 0 {
+			continue
+		}
+		matchedFiles++
+		switch {
+		case opts.filesOnly:
+			out.WriteString(src.prefix + src.name + "\n")
+		case opts.count:
+			out.WriteString(fmt.Sprintf("%s%s:%d\n", src.prefix, src.name, matches))
+		}
+	}
+
+	if matchedFiles == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "",
+			SCPEffect: "🔍 No matching fragments found in containment records",
+		}
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(out.String(), "\n"),
+		SCPEffect: fmt.Sprintf("🔍 Forensic sweep located fragments in %d files", matchedFiles),
+	}
+}
+
+// grepSources collects the file versions to search: the tracked working
+// files by default, the index with --cached, or the trees of given revisions
+func grepSources(state *GameState, opts grepOptions) ([]grepSource, error) {
+	var sources []grepSource
+	wanted := func(filename string) bool {
+		if len(opts.paths) == 0 {
+			return true
+		}
+		for _, pattern := range opts.paths {
+			if pattern == filename || matchesPattern(pattern, filename) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case len(opts.revisions) > 0:
+		for _, rev := range opts.revisions {
+			id, err := resolveRevision(state, rev)
+			if err != nil {
+				return nil, err
+			}
+			tree := treeAt(state, id)
+			for _, filename := range sortedTreeNames(tree) {
+				if wanted(filename) {
+					sources = append(sources, grepSource{rev + ":", filename, state.Objects[tree[filename]]})
+				}
+			}
+		}
+
+	case opts.cached:
+		names := sortedTreeNames(treeAt(state, headCommitID(state)))
+		for filename := range state.StagingArea {
+			if !containsString(names, filename) {
+				names = append(names, filename)
+			}
+		}
+		sort.Strings(names)
+		for _, filename := range names {
+			if wanted(filename) {
+				content, _, _ := indexVersion(state, filename)
+				sources = append(sources, grepSource{"", filename, content})
+			}
+		}
+
+	default:
+		for _, filename := range sortedKeys(state.WorkingDir) {
+			if _, _, tracked := indexVersion(state, filename); tracked && wanted(filename) {
+				sources = append(sources, grepSource{"", filename, state.WorkingDir[filename].Content})
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+// sortedTreeNames returns the file names of a tree in lexical order
+func sortedTreeNames(tree map[string]string) []string {
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *GrepCommand) Help() string {
+	return "Search tracked files or past revisions for a pattern"
+}
+
+func (c *GrepCommand) RequiredArgs() int {
+	return 1
+}
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"sort"
+	"strings"
+)
+
+// Every command the player runs this level is recorded, so a level can check
+// what the researcher actually did and not only the state they left behind.
+
+// StateDiff is what a command changed in the repository
+type StateDiff struct {
+	BranchBefore string
+	BranchAfter  string
+	HeadBefore   string   // commit ID, empty before the first commit
+	HeadAfter    string   // commit ID, empty before the first commit
+	NewCommits   []string // commits created, oldest first
+	ChangedFiles []string // working files created, modified or deleted
+	StagedFiles  []string // files staged, restaged or unstaged
+}
+
+// CommandRecord is one command in the history
+type CommandRecord struct {
+	Git     bool     // a git command rather than a shell builtin
+	Name    string   // git subcommand ("log") or builtin ("ls")
+	Args    []string // arguments after aliases were expanded
+	Success bool
+	Diff    StateDiff
+
+	// Commits the arguments named when the command ran; "HEAD~1" may name
+	// a different commit later
+	Revisions []string
+}
+
+// String formats the record the way the player would have typed it
+func (r CommandRecord) String() string {
+	parts := append([]string{r.Name}, r.Args...)
+	if r.Git {
+		parts = append([]string{"git"}, parts...)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Matches reports whether the record satisfies a command spec as written in
+// Level.RequiredCommands: "git log -p" is any git log given -p, and a short
+// flag also matches inside a cluster, so "git commit -a" accepts "-am"
+func (r CommandRecord) Matches(spec string) bool {
+	parts := strings.Fields(spec)
+	if len(parts) == 0 {
+		return false
+	}
+	if parts[0] == "git" {
+		if !r.Git || len(parts) < 2 {
+			return false
+		}
+		parts = parts[1:]
+	} else if r.Git {
+		return false
+	}
+	if parts[0] != r.Name {
+		return false
+	}
+	for _, want := range parts[1:] {
+		if !r.hasArg(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasArg reports whether an argument was given, looking inside short flag clusters
+func (r CommandRecord) hasArg(want string) bool {
+	if containsString(r.Args, want) {
+		return true
+	}
+	if len(want) != 2 || want[0] != '-' || want[1] == '-' {
+		return false
+	}
+	for _, arg := range r.Args {
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && strings.ContainsRune(arg[1:], rune(want[1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsedCommand reports whether the player successfully ran a command
+// matching spec this level
+func (gs *GameState) UsedCommand(spec string) bool {
+	for _, record := range gs.CommandHistory {
+		if record.Success && record.Matches(spec) {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingCommands returns the specs the player has not yet used this level
+func (gs *GameState) MissingCommands(specs []string) []string {
+	var missing []string
+	for _, spec := range specs {
+		if !gs.UsedCommand(spec) {
+			missing = append(missing, spec)
+		}
+	}
+	return missing
+}
+
+// LastCommand returns the most recent successful command matching spec and
+// its position in the history, or -1 when there is none
+func (gs *GameState) LastCommand(spec string) (CommandRecord, int) {
+	for i := len(gs.CommandHistory) - 1; i >= 0; i-- {
+		if record := gs.CommandHistory[i]; record.Success && record.Matches(spec) {
+			return record, i
+		}
+	}
+	return CommandRecord{}, -1
+}
+
+// stateSnapshot is what a StateDiff is worked out from
+type stateSnapshot struct {
+	branch  string
+	head    string
+	commits map[string]bool
+	working map[string]string // file -> hash
+	staged  map[string]string // file -> hash
+}
+
+// takeSnapshot records the parts of the repository a command may change
+func takeSnapshot(state *GameState) stateSnapshot {
+	snapshot := stateSnapshot{
+		branch:  state.CurrentBranch,
+		head:    headCommitID(state),
+		commits: make(map[string]bool, len(state.Commits)),
+		working: make(map[string]string, len(state.WorkingDir)),
+		staged:  make(map[string]string, len(state.StagingArea)),
+	}
+	for _, commit := range state.Commits {
+		snapshot.commits[commit.ID] = true
+	}
+	for file, fs := range state.WorkingDir {
+		snapshot.working[file] = fs.Hash
+	}
+	for file, fs := range state.StagingArea {
+		snapshot.staged[file] = fs.Hash
+	}
+	return snapshot
+}
+
+// diffSince works out what changed in the repository since the snapshot
+func diffSince(before stateSnapshot, state *GameState) StateDiff {
+	after := takeSnapshot(state)
+	diff := StateDiff{
+		BranchBefore: before.branch,
+		BranchAfter:  after.branch,
+		HeadBefore:   before.head,
+		HeadAfter:    after.head,
+		ChangedFiles: changedKeys(before.working, after.working),
+		StagedFiles:  changedKeys(before.staged, after.staged),
+	}
+	for _, commit := range state.Commits {
+		if !before.commits[commit.ID] {
+			diff.NewCommits = append(diff.NewCommits, commit.ID)
+		}
+	}
+	return diff
+}
+
+// changedKeys lists the keys added, removed or changed between two maps, sorted
+func changedKeys(before, after map[string]string) []string {
+	var changed []string
+	for key, value := range after {
+		if old, exists := before[key]; !exists || old != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// namedCommits resolves the operands that name commits, skipping the rest
+func namedCommits(state *GameState, args []string) []string {
+	var ids []string
+	_, operands := splitFlags(args)
+	for _, operand := range operands {
+		if id, err := resolveRevision(state, operand); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// commandRun is a command being processed, kept until it can be settled
+type commandRun struct {
+	git        bool
+	name       string
+	args       []string
+	revisions  []string // commits the arguments named before the command ran
+	before     stateSnapshot
+	encounters int // entity announcements during the command
+}
+
+// settleCommand records a finished command in the history and applies its
+// effect on sanity. Commands still waiting on the editor or a prompt are
+// settled once they finish.
+func (e *Engine) settleCommand(result CommandResult, run commandRun) CommandResult {
+	if result.Edit != nil || result.Prompt != nil {
+		e.pendingCommand = &run
+		return result
+	}
+	result.SanityDelta += sanityDelta(e.State, result, run.name, run.args, run.encounters)
+	e.State.AdjustSanity(result.SanityDelta)
+	e.State.CommandHistory = append(e.State.CommandHistory, CommandRecord{
+		Git:       run.git,
+		Name:      run.name,
+		Args:      run.args,
+		Success:   result.Success,
+		Diff:      diffSince(run.before, e.State),
+		Revisions: run.revisions,
+	})
+	return result
+}
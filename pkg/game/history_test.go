@@ -0,0 +1,166 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandHistoryRecordsDiffs(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+	engine.ProcessCommand("echo notes > notes.txt")
+	engine.ProcessCommand("git add notes.txt")
+	engine.ProcessCommand("git commit -m 'Add notes'")
+	engine.ProcessCommand("git switch -c side")
+	engine.ProcessCommand("git frobnicate")
+
+	history := engine.State.CommandHistory
+	if len(history) != 6 {
+		t.Fatalf("Expected 6 records, got %d", len(history))
+	}
+	if echo := history[1]; echo.Git || echo.Name != "echo" || !reflect.DeepEqual(echo.Diff.ChangedFiles, []string{"notes.txt"}) {
+		t.Errorf("Unexpected builtin record %+v", echo)
+	}
+	if add := history[2]; !reflect.DeepEqual(add.Diff.StagedFiles, []string{"notes.txt"}) {
+		t.Errorf("git add should stage notes.txt, got %+v", add.Diff)
+	}
+	commit := history[3]
+	if commit.String() != "git commit -m Add notes" || len(commit.Diff.NewCommits) != 1 || commit.Diff.HeadAfter != commit.Diff.NewCommits[0] || commit.Diff.HeadBefore != "" {
+		t.Errorf("Unexpected commit record %q %+v", commit.String(), commit.Diff)
+	}
+	if switched := history[4].Diff; switched.BranchBefore != "main" || switched.BranchAfter != "side" {
+		t.Errorf("Unexpected switch diff %+v", switched)
+	}
+	if unknown := history[5]; unknown.Success || !unknown.Git {
+		t.Errorf("Unknown git commands are recorded as failures, got %+v", unknown)
+	}
+
+	engine.StartLevel(2)
+	if len(engine.State.CommandHistory) != 0 {
+		t.Error("Starting a level should clear the history")
+	}
+}
+
+func TestCommandHistoryWaitsForEditor(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+	engine.ProcessCommand("echo notes > notes.txt")
+	engine.ProcessCommand("git add notes.txt")
+
+	result := engine.ProcessCommand("git commit")
+	if len(engine.State.CommandHistory) != 3 {
+		t.Fatal("A commit waiting on the editor should not be recorded yet")
+	}
+	engine.FinishEdit(result.Edit, "Add notes\n")
+	last := engine.State.CommandHistory[len(engine.State.CommandHistory)-1]
+	if last.Name != "commit" || !last.Success || len(last.Diff.NewCommits) != 1 {
+		t.Errorf("The finished commit should be recorded with its diff, got %+v", last)
+	}
+}
+
+func TestCommandHistoryCancelledEditor(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+	engine.ProcessCommand("echo notes > notes.txt")
+	engine.ProcessCommand("git add notes.txt")
+
+	result := engine.ProcessCommand("git commit")
+	engine.CancelEdit(result.Edit)
+	result = engine.ProcessCommand("edit notes.txt")
+	engine.FinishEdit(result.Edit, "more notes\n")
+
+	if engine.State.UsedCommand("git commit") || len(engine.State.Commits) != 0 {
+		t.Error("A commit whose editor was closed should be recorded as failed")
+	}
+	last := engine.State.CommandHistory[len(engine.State.CommandHistory)-1]
+	if last.String() != "edit notes.txt" || !last.Success || len(last.Diff.ChangedFiles) != 1 {
+		t.Errorf("The saved edit should be recorded as itself, got %+v", last)
+	}
+}
+
+func TestUsedCommand(t *testing.T) {
+	state := NewGameState()
+	state.CommandHistory = []CommandRecord{
+		{Git: true, Name: "commit", Args: []string{"-am", "Fix"}, Success: true},
+		{Git: true, Name: "log", Args: []string{"-p"}, Success: false},
+		{Name: "ls", Args: []string{"-l"}, Success: true},
+	}
+
+	cases := map[string]bool{
+		"git commit":    true,
+		"git commit -a": true,
+		"git commit -m": true,
+		"git commit -p": false,
+		"git log":       false, // only successful commands count
+		"ls -l":         true,
+		"git ls":        false,
+		"git":           false,
+	}
+	for spec, want := range cases {
+		if got := state.UsedCommand(spec); got != want {
+			t.Errorf("UsedCommand(%q) = %v, want %v", spec, got, want)
+		}
+	}
+	if missing := state.MissingCommands([]string{"git commit -a", "git log", "git show"}); !reflect.DeepEqual(missing, []string{"git log", "git show"}) {
+		t.Errorf("Unexpected missing commands %v", missing)
+	}
+	if _, at := state.LastCommand("ls"); at != 2 {
+		t.Errorf("LastCommand should find ls at 2, got %d", at)
+	}
+}
+
+func TestLevelRequiresCommands(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.StartLevel(1); err != nil {
+		t.Fatal(err)
+	}
+	engine.ProcessCommand("git init")
+	engine.ProcessCommand("git config user.name Bright")
+	engine.ProcessCommand("git config user.email bright@site19.scp")
+	engine.ProcessCommand("git add README.txt anomaly.txt containment.log")
+	result := engine.ProcessCommand("git commit -m 'Initial containment'")
+	if strings.Contains(result.SCPEffect, "LEVEL COMPLETE") || engine.IsLevelComplete() {
+		t.Fatal("Level 1 should not complete without 'git add .' having been used")
+	}
+	if _, msg := engine.CurrentLevel.Validate(engine.State); msg != "Outstanding objective: Stage every discovered file" {
+		t.Errorf("Unexpected validation message %q", msg)
+	}
+
+	result = engine.ProcessCommand("git add .")
+	if !strings.Contains(result.SCPEffect, "LEVEL COMPLETE") {
+		t.Errorf("Level 1 should complete once every protocol was performed, got %q", result.SCPEffect)
+	}
+}
+
+func TestLevel3RequiresExaminingACommit(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.StartLevel(3); err != nil {
+		t.Fatal(err)
+	}
+	engine.ProcessCommand("git log")
+	engine.ProcessCommand("git log -p")
+	engine.ProcessCommand("git show")
+	if completed, msg := engine.CurrentLevel.Validate(engine.State); completed || !strings.Contains(msg, "specific commit") {
+		t.Errorf("Showing only HEAD should not complete the analysis, got %q", msg)
+	}
+
+	engine.ProcessCommand("git show HEAD~2")
+	if completed, msg := engine.CurrentLevel.Validate(engine.State); completed || !strings.Contains(msg, "forged") {
+		t.Errorf("A researcher's commit should not expose the entity, got %q", msg)
+	}
+
+	result := engine.ProcessCommand("git show HEAD~1")
+	if !strings.Contains(result.Message, EntityAuthor) || !strings.Contains(result.SCPEffect, "LEVEL COMPLETE") {
+		t.Errorf("Showing the entity's commit should complete the analysis, got %q", result.SCPEffect)
+	}
+
+	// HEAD~1 names another commit once the history moves on
+	for _, notes := range []string{"first", "second"} {
+		engine.ProcessCommand("echo " + notes + " >> analysis.txt")
+		engine.ProcessCommand("git commit -am 'Add " + notes + " notes'")
+	}
+	if !examinedEntityCommit(engine.State) {
+		t.Error("The commit shown should be remembered, not the revision typed")
+	}
+}
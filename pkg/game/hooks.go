@@ -0,0 +1,213 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HookDir is where hook scripts appear to live, for the edit and cat commands
+const HookDir = ".git/hooks/"
+
+// supportedHooks are the hooks commit and push run
+var supportedHooks = []string{"pre-commit", "commit-msg", "pre-push"}
+
+// hookContext is what a hook script gets to inspect
+type hookContext struct {
+	Files    map[string]string // file -> content being committed or pushed
+	Messages []string          // commit messages being committed or pushed
+	Branch   string
+}
+
+// runHook evaluates a hook script. Scripts are small rule lists rather than
+// shell; each line is one rule:
+//
+//	echo <text>               print text
+//	exit <code>               stop; any code but 0 rejects
+//	deny-content <regexp>     reject files whose content matches
+//	deny-file <glob>          reject files whose name matches
+//	require-message <regexp>  reject commit messages that do not match
+//	deny-message <regexp>     reject commit messages that match
+//	deny-branch <glob>        reject work on matching branches
+//
+// Rules run top to bottom and the first rejection stops the hook. A missing
+// hook always passes.
+func runHook(state *GameState, name string, ctx hookContext) (string, bool) {
+	script, exists := state.Hooks[name]
+	if !exists {
+		return "", true
+	}
+
+	var output strings.Builder
+	reject := func(format string, args ...interface{}) (string, bool) {
+		output.WriteString(fmt.Sprintf(name+": "+format, args...))
+		return output.String(), false
+	}
+
+	for n, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		var re *regexp.Regexp
+		if strings.HasSuffix(rule, "-content") || strings.HasSuffix(rule, "-message") {
+			var err error
+			if re, err = regexp.Compile(arg); err != nil {
+				return reject("line %d: bad pattern '%s'", n+1, arg)
+			}
+		}
+
+		switch rule {
+		case "echo":
+			output.WriteString(arg + "\n")
+		case "exit":
+			code, _ := strconv.Atoi(arg)
+			if code != 0 {
+				return strings.TrimRight(output.String(), "\n"), false
+			}
+			return strings.TrimRight(output.String(), "\n"), true
+		case "deny-content":
+			for _, filename := range sortedContentNames(ctx.Files) {
+				if re.MatchString(ctx.Files[filename]) {
+					return reject("%s contains forbidden content matching '%s'", filename, arg)
+				}
+			}
+		case "deny-file":
+			for _, filename := range sortedContentNames(ctx.Files) {
+				if matchesPattern(arg, filename) {
+					return reject("%s may not be committed (matches '%s')", filename, arg)
+				}
+			}
+		case "require-message":
+			for _, message := range ctx.Messages {
+				if !re.MatchString(message) {
+					return reject("commit message '%s' does not match '%s'", commitSubject(message), arg)
+				}
+			}
+		case "deny-message":
+			for _, message := range ctx.Messages {
+				if re.MatchString(message) {
+					return reject("commit message '%s' matches forbidden pattern '%s'", commitSubject(message), arg)
+				}
+			}
+		case "deny-branch":
+			if matchesPattern(arg, ctx.Branch) {
+				return reject("branch '%s' is protected", ctx.Branch)
+			}
+		default:
+			return reject("line %d: %s: command not found", n+1, rule)
+		}
+	}
+	return strings.TrimRight(output.String(), "\n"), true
+}
+
+// stagedContext builds the hook context for committing the staging area
+func stagedContext(state *GameState) hookContext {
+	files := make(map[string]string)
+	for filename, file := range state.StagingArea {
+		files[filename] = file.Content
+	}
+	return hookContext{Files: files, Branch: state.CurrentBranch}
+}
+
+// pushContext builds the hook context for commits a push would transmit
+func pushContext(state *GameState, remote *Remote, branch string, commits []string) hookContext {
+	ctx := hookContext{Files: make(map[string]string), Branch: branch}
+	for _, id := range commits {
+		if remote.hasCommit(id) {
+			continue
+		}
+		commit, found := state.findCommit(id)
+		if !found {
+			continue
+		}
+		ctx.Messages = append(ctx.Messages, commit.Message)
+		for filename, hash := range commit.Files {
+			ctx.Files[filename] = state.Objects[hash]
+		}
+	}
+	return ctx
+}
+
+// sortedContentNames returns the keys of a file -> content map in lexical order
+func sortedContentNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// noVerifyBypass marks a result whose hooks were skipped with --no-verify but
+// would have rejected it
+func noVerifyBypass(result CommandResult, hook, output string) CommandResult {
+	if !result.Success {
+		return result
+	}
+	result.SCPEffect = fmt.Sprintf("⚠️  WARNING: %s hook bypassed with --no-verify - it would have rejected this:\n   %s", hook, output)
+	result.AnomalyDelta += 2
+	return result
+}
+
+// HookCommand implements git hook run
+type HookCommand struct{}
+
+func (c *HookCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+	if len(args) < 2 || args[0] != "run" {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git hook run <hook-name>",
+			SCPEffect: "⚠️  WARNING: Specify the safeguard to test",
+		}
+	}
+
+	name := args[1]
+	if _, exists := state.Hooks[name]; !exists {
+		return CommandResult{
+			Success:   false,
+			Message:   fmt.Sprintf("error: cannot find a hook named %s", name),
+			SCPEffect: fmt.Sprintf("📋 No safeguard installed at %s%s", HookDir, name),
+		}
+	}
+
+	// Test against the staged files and the latest commit message
+	ctx := stagedContext(state)
+	if head, found := state.findCommit(headCommitID(state)); found {
+		ctx.Messages = []string{head.Message}
+	}
+	output, ok := runHook(state, name, ctx)
+	if !ok {
+		return CommandResult{
+			Success:   false,
+			Message:   output,
+			SCPEffect: fmt.Sprintf("🛡️  Safeguard %s would reject the current work", name),
+		}
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   output,
+		SCPEffect: fmt.Sprintf("🛡️  Safeguard %s passes", name),
+	}
+}
+
+func (c *HookCommand) Help() string {
+	return "Run a hook against the current work"
+}
+
+func (c *HookCommand) RequiredArgs() int {
+	return 2
+}
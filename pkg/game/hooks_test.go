@@ -0,0 +1,97 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreCommitHook(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	state.Hooks["pre-commit"] = "#!/bin/scp\ndeny-content ACTIVE THREAT\n"
+
+	state.WorkingDir["report.txt"] = FileState{Content: "Status: ACTIVE THREAT", Hash: hashContent("Status: ACTIVE THREAT")}
+	(&AddCommand{}).Execute([]string{"report.txt"}, state)
+
+	cmd := &CommitCommand{}
+	result := cmd.Execute([]string{"-m", "Report"}, state)
+	if result.Success || !strings.Contains(result.Message, "pre-commit: report.txt contains forbidden content") {
+		t.Fatalf("pre-commit hook should block the commit, got %q", result.Message)
+	}
+	if len(state.Branches["main"]) != 0 {
+		t.Error("No commit should be recorded when the hook rejects")
+	}
+
+	anomaly := result.AnomalyDelta
+	result = cmd.Execute([]string{"--no-verify", "-m", "Report"}, state)
+	if !result.Success || len(state.Branches["main"]) != 1 {
+		t.Fatalf("--no-verify should bypass the hook: %s", result.Message)
+	}
+	if result.AnomalyDelta <= anomaly || !strings.Contains(result.SCPEffect, "bypassed") {
+		t.Error("Bypassing a failing hook should be flagged")
+	}
+}
+
+func TestCommitMsgAndPrePushHooks(t *testing.T) {
+	state := newRemoteTestState()
+	state.Hooks["commit-msg"] = `require-message ^\[SCP-\d+\]`
+	state.Hooks["pre-push"] = "echo Checking transmission\ndeny-branch main"
+
+	state.WorkingDir["log.txt"] = FileState{Content: "entry", Hash: hashContent("entry")}
+	(&AddCommand{}).Execute([]string{"log.txt"}, state)
+	if result := (&CommitCommand{}).Execute([]string{"-m", "fix"}, state); result.Success {
+		t.Error("commit-msg hook should reject a message without an SCP prefix")
+	}
+	if result := (&CommitCommand{}).Execute([]string{"-m", "[SCP-173] Log entry"}, state); !result.Success {
+		t.Fatalf("Conforming message should be accepted: %s", result.Message)
+	}
+
+	result := (&PushCommand{}).Execute([]string{"origin", "main"}, state)
+	if result.Success || !strings.Contains(result.Message, "pre-push: branch 'main' is protected") {
+		t.Errorf("pre-push hook should veto the push, got %q", result.Message)
+	}
+	if len(state.Remotes["origin"].Branches["main"]) != 0 {
+		t.Error("Nothing should reach the remote when the push is vetoed")
+	}
+
+	delete(state.Hooks, "pre-push")
+	if result = (&PushCommand{}).Execute([]string{"origin", "main"}, state); !result.Success {
+		t.Errorf("Push should succeed without the hook: %s", result.Message)
+	}
+}
+
+func TestHookScriptErrors(t *testing.T) {
+	state := NewGameState()
+	state.Hooks["pre-commit"] = "rm -rf /"
+	output, ok := runHook(state, "pre-commit", hookContext{})
+	if ok || output != "pre-commit: line 1: rm: command not found" {
+		t.Errorf("Unknown rules should fail the hook, got %q", output)
+	}
+}
+
+func TestLevelHooksRemovedWhenLeft(t *testing.T) {
+	defer func(saved map[int]*Level) { loadedLevels = saved }(loadedLevels)
+	loadedLevels = map[int]*Level{
+		8: {ID: 8, Hooks: map[string]string{"pre-commit": "deny-content ACTIVE THREAT", "pre-push": "deny-branch main"}},
+		9: {ID: 9},
+	}
+
+	engine := NewEngine()
+	if err := engine.StartLevel(8); err != nil {
+		t.Fatal(err)
+	}
+	engine.State.Hooks["commit-msg"] = `require-message ^\[SCP-\d+\]`
+	engine.State.Hooks["pre-push"] = "deny-branch strategy-*"
+
+	if err := engine.StartLevel(9); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := engine.State.Hooks["pre-commit"]; exists {
+		t.Error("A level's hooks should be removed when the next level starts")
+	}
+	if len(engine.State.Hooks) != 2 {
+		t.Errorf("Hooks the player wrote or rewrote should be kept, got %v", engine.State.Hooks)
+	}
+}
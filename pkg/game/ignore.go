@@ -0,0 +1,57 @@
+package game
+
+import (
+	"path"
+	"strings"
+)
+
+// ignorePatterns reads the patterns from .gitignore in the working directory
+func ignorePatterns(state *GameState) []string {
+	file, exists := state.WorkingDir[".gitignore"]
+	if !exists {
+		return nil
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(file.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+// isIgnored reports whether an untracked file matches .gitignore, directly or
+// through one of its directories. Later patterns win, a leading '!'
+// re-includes a file, and a trailing '/' only matches directories.
+func isIgnored(state *GameState, filename string) bool {
+	ignored := false
+	candidates := append(parentDirs(filename), filename)
+	for _, pattern := range ignorePatterns(state) {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "/")
+		dirOnly := strings.HasSuffix(pattern, "/")
+		pattern = strings.TrimSuffix(pattern, "/")
+		for i, candidate := range candidates {
+			if dirOnly && i == len(candidates)-1 {
+				continue
+			}
+			if matchesPattern(pattern, candidate) {
+				ignored = !negate
+				break
+			}
+		}
+	}
+	return ignored
+}
+
+// matchesPattern matches a glob against the whole path or its base name
+func matchesPattern(pattern, filename string) bool {
+	if matched, _ := path.Match(pattern, filename); matched {
+		return true
+	}
+	matched, _ := path.Match(pattern, path.Base(filename))
+	return matched
+}
@@ -0,0 +1,526 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const patchHelp = `y - stage this hunk
+n - do not stage this hunk
+q - quit; do not stage this hunk or any of the remaining ones
+a - stage this hunk and all later hunks in the file
+d - do not stage this hunk or any of the later hunks in the file
+s - split the current hunk into smaller hunks
+e - manually edit the current hunk
+? - print help`
+
+// patchSession walks the hunks of modified files for git add -p, staging the
+// hunks the player accepts into the index
+type patchSession struct {
+	files  []string
+	file   int
+	base   string
+	ops    []diffOp
+	hunks  []Hunk
+	hunk   int
+	staged []Hunk // accepted hunks of the current file
+
+	stagedTotal int
+	// done, when set, produces the result once every file has been visited
+	// (git add -i uses it to return to its menu)
+	done func(state *GameState, summary string) CommandResult
+}
+
+// modifiedFiles lists tracked files whose working copy differs from the index
+func modifiedFiles(state *GameState) []string {
+	var files []string
+	for _, filename := range sortedKeys(state.WorkingDir) {
+		_, hash, tracked := indexVersion(state, filename)
+		if tracked && hash != state.WorkingDir[filename].Hash {
+			files = append(files, filename)
+		}
+	}
+	return files
+}
+
+// untrackedFiles lists working files that are neither committed nor staged
+func untrackedFiles(state *GameState) []string {
+	var files []string
+	for _, filename := range sortedKeys(state.WorkingDir) {
+		if _, _, tracked := indexVersion(state, filename); !tracked {
+			files = append(files, filename)
+		}
+	}
+	return files
+}
+
+// startPatch begins git add -p over the given paths (all modified files when empty)
+func startPatch(state *GameState, paths []string) CommandResult {
+	files := modifiedFiles(state)
+	if len(paths) > 0 {
+		matched, unmatched := expandPathspecs(sortedKeys(state.WorkingDir), paths)
+		if len(unmatched) > 0 {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: pathspec '%s' did not match any file(s) known to git", unmatched[0]),
+				SCPEffect:    "🔴 ERROR: Files not found in containment area",
+				AnomalyDelta: 1,
+			}
+		}
+		var selected []string
+		for _, filename := range matched {
+			if containsString(files, filename) {
+				selected = append(selected, filename)
+			}
+		}
+		files = selected
+	}
+
+	if len(files) == 0 {
+		return CommandResult{
+			Success:   true,
+			Message:   "No changes.",
+			SCPEffect: "✓ No modified lines awaiting review",
+		}
+	}
+
+	session := &patchSession{files: files}
+	session.loadFile(state)
+	return session.prompt(state, "")
+}
+
+// loadFile computes the hunks of the current file
+func (s *patchSession) loadFile(state *GameState) {
+	filename := s.files[s.file]
+	s.base, _, _ = indexVersion(state, filename)
+	s.ops = diffLines(s.base, state.WorkingDir[filename].Content)
+	s.hunks = buildHunks(s.ops, diffContext)
+	s.hunk = 0
+	s.staged = nil
+}
+
+// prompt shows the current hunk and asks what to do with it
+func (s *patchSession) prompt(state *GameState, notice string) CommandResult {
+	filename := s.files[s.file]
+
+	var message strings.Builder
+	if notice != "" {
+		message.WriteString(notice + "\n")
+	}
+	if s.hunk == 0 {
+		message.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", filename, filename, filename, filename))
+	}
+	message.WriteString(strings.TrimRight(formatHunk(s.ops, s.hunks[s.hunk]), "\n"))
+
+	options := "y,n,q,a,d"
+	if len(splitHunk(s.ops, s.hunks[s.hunk])) > 1 {
+		options += ",s"
+	}
+	options += ",e,?"
+
+	return CommandResult{
+		Success: true,
+		Message: message.String(),
+		Prompt: &PromptRequest{
+			Text:   fmt.Sprintf("(%d/%d) Stage this hunk [%s]? ", s.hunk+1, len(s.hunks), options),
+			Answer: s.answer,
+		},
+	}
+}
+
+// answer handles one reply to the hunk prompt
+func (s *patchSession) answer(input string, state *GameState) CommandResult {
+	switch strings.TrimSpace(input) {
+	case "y":
+		s.staged = append(s.staged, s.hunks[s.hunk])
+		s.hunk++
+	case "n":
+		s.hunk++
+	case "a":
+		s.staged = append(s.staged, s.hunks[s.hunk:]...)
+		s.hunk = len(s.hunks)
+	case "d":
+		s.hunk = len(s.hunks)
+	case "q":
+		s.finishFile(state)
+		return s.finish(state)
+	case "s":
+		pieces := splitHunk(s.ops, s.hunks[s.hunk])
+		if len(pieces) == 1 {
+			return s.prompt(state, "Sorry, cannot split this hunk")
+		}
+		rest := append(pieces, s.hunks[s.hunk+1:]...)
+		s.hunks = append(s.hunks[:s.hunk], rest...)
+		return s.prompt(state, fmt.Sprintf("Split into %d hunks.", len(pieces)))
+	case "e":
+		return s.edit(state)
+	default:
+		return s.prompt(state, patchHelp)
+	}
+
+	return s.advance(state)
+}
+
+// advance moves to the next hunk, or to the next file when this one is done
+func (s *patchSession) advance(state *GameState) CommandResult {
+	if s.hunk < len(s.hunks) {
+		return s.prompt(state, "")
+	}
+
+	s.finishFile(state)
+	s.file++
+	if s.file >= len(s.files) {
+		return s.finish(state)
+	}
+	s.loadFile(state)
+	return s.prompt(state, "")
+}
+
+// finishFile stages the accepted hunks of the current file
+func (s *patchSession) finishFile(state *GameState) {
+	if len(s.staged) == 0 {
+		return
+	}
+
+	filename := s.files[s.file]
+	content := applyHunks(s.ops, s.staged, strings.HasSuffix(s.base, "\n"))
+	state.StagingArea[filename] = FileState{
+		Content: content,
+		Staged:  true,
+		Hash:    state.storeObject(content),
+	}
+	s.stagedTotal += len(s.staged)
+	s.staged = nil
+}
+
+// finish ends the session and reports what was staged
+func (s *patchSession) finish(state *GameState) CommandResult {
+	summary := fmt.Sprintf("%d hunks staged", s.stagedTotal)
+	if s.done != nil {
+		return s.done(state, summary)
+	}
+
+	if s.stagedTotal == 0 {
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: "✓ No modifications admitted to the staging area",
+		}
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: fmt.Sprintf("✅ %s - unreviewed lines remain outside containment", summary),
+	}
+}
+
+// edit opens the current hunk in the editor and stages the edited version
+func (s *patchSession) edit(state *GameState) CommandResult {
+	h := s.hunks[s.hunk]
+
+	var template strings.Builder
+	template.WriteString("# Manual hunk edit mode -- see bottom for a quick guide.\n")
+	template.WriteString(formatHunk(s.ops, h))
+	template.WriteString("# ---\n")
+	template.WriteString("# To remove '-' lines, make them ' ' lines (context).\n")
+	template.WriteString("# To remove '+' lines, delete them.\n")
+	template.WriteString("# Lines starting with # will be removed.\n")
+
+	return CommandResult{
+		Success: true,
+		Message: "",
+		Edit: &EditRequest{
+			Title:   "addp-hunk-edit.diff",
+			Initial: template.String(),
+			Finish: func(text string, state *GameState) CommandResult {
+				edited, ok := s.parseEditedHunk(h, text)
+				if !ok {
+					return s.prompt(state, "Your edited hunk does not apply.")
+				}
+
+				// Replace the hunk's ops with the edited version
+				tail := append([]diffOp{}, s.ops[h.End:]...)
+				s.ops = append(append(s.ops[:h.Start], edited...), tail...)
+				shift := len(edited) - (h.End - h.Start)
+				for i := s.hunk + 1; i < len(s.hunks); i++ {
+					s.hunks[i].Start += shift
+					s.hunks[i].End += shift
+				}
+				s.hunks[s.hunk] = Hunk{Start: h.Start, End: h.Start + len(edited)}
+
+				s.staged = append(s.staged, s.hunks[s.hunk])
+				s.hunk++
+				return s.advance(state)
+			},
+		},
+	}
+}
+
+// parseEditedHunk reads an edited hunk back into diff ops. The old side must
+// be unchanged, otherwise the hunk cannot be applied to the index.
+func (s *patchSession) parseEditedHunk(h Hunk, text string) ([]diffOp, bool) {
+	var oldSide, newSide []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@@") {
+			continue
+		}
+		kind, content := byte(' '), ""
+		if line != "" {
+			kind, content = line[0], line[1:]
+		}
+		switch kind {
+		case ' ':
+			oldSide = append(oldSide, content)
+			newSide = append(newSide, content)
+		case '-':
+			oldSide = append(oldSide, content)
+		case '+':
+			newSide = append(newSide, content)
+		default:
+			return nil, false
+		}
+	}
+
+	var original []string
+	for _, op := range s.ops[h.Start:h.End] {
+		if op.Kind != '+' {
+			original = append(original, op.Text)
+		}
+	}
+	if strings.Join(original, "\n") != strings.Join(oldSide, "\n") || len(original) != len(oldSide) {
+		return nil, false
+	}
+
+	return diffLineSlices(oldSide, newSide), true
+}
+
+// startInteractive opens the git add -i command menu
+func startInteractive(state *GameState) CommandResult {
+	return interactiveMenu(state, interactiveStatus(state))
+}
+
+// interactiveMenu shows the command list and waits for a choice
+func interactiveMenu(state *GameState, notice string) CommandResult {
+	var message strings.Builder
+	if notice != "" {
+		message.WriteString(notice + "\n")
+	}
+	message.WriteString("*** Commands ***\n")
+	message.WriteString("  1: status\t  2: update\t  3: revert\t  4: add untracked\n")
+	message.WriteString("  5: patch\t  6: diff\t  7: quit\t  8: help")
+
+	return CommandResult{
+		Success: true,
+		Message: message.String(),
+		Prompt: &PromptRequest{
+			Text:   "What now> ",
+			Answer: interactiveChoice,
+		},
+	}
+}
+
+// interactiveChoice dispatches a git add -i menu selection
+func interactiveChoice(input string, state *GameState) CommandResult {
+	switch strings.TrimSpace(input) {
+	case "1", "s", "status":
+		return interactiveMenu(state, interactiveStatus(state))
+	case "2", "u", "update":
+		return interactiveSelect(state, "Update", modifiedFiles(state), func(state *GameState, files []string) string {
+			for _, filename := range files {
+				fileState := state.WorkingDir[filename]
+				fileState.Staged = true
+				state.StagingArea[filename] = fileState
+				state.storeObject(fileState.Content)
+			}
+			return fmt.Sprintf("updated %d paths", len(files))
+		})
+	case "3", "r", "revert":
+		return interactiveSelect(state, "Revert", sortedKeys(state.StagingArea), func(state *GameState, files []string) string {
+			for _, filename := range files {
+				delete(state.StagingArea, filename)
+			}
+			return fmt.Sprintf("reverted %d paths", len(files))
+		})
+	case "4", "a", "add untracked":
+		return interactiveSelect(state, "Add untracked", untrackedFiles(state), func(state *GameState, files []string) string {
+			for _, filename := range files {
+				fileState := state.WorkingDir[filename]
+				fileState.Staged = true
+				state.StagingArea[filename] = fileState
+				state.storeObject(fileState.Content)
+			}
+			return fmt.Sprintf("added %d paths", len(files))
+		})
+	case "5", "p", "patch":
+		files := modifiedFiles(state)
+		if len(files) == 0 {
+			return interactiveMenu(state, "No changes.")
+		}
+		return CommandResult{
+			Success: true,
+			Message: numberedFiles(files),
+			Prompt: &PromptRequest{
+				Text: "Patch update>> ",
+				Answer: func(input string, state *GameState) CommandResult {
+					selected := parseSelection(input, files)
+					if len(selected) == 0 {
+						return interactiveMenu(state, "")
+					}
+					session := &patchSession{
+						files: selected,
+						done: func(state *GameState, summary string) CommandResult {
+							return interactiveMenu(state, summary)
+						},
+					}
+					session.loadFile(state)
+					return session.prompt(state, "")
+				},
+			},
+		}
+	case "6", "d", "diff":
+		staged := sortedKeys(state.StagingArea)
+		if len(staged) == 0 {
+			return interactiveMenu(state, "No changes.")
+		}
+		return CommandResult{
+			Success: true,
+			Message: numberedFiles(staged),
+			Prompt: &PromptRequest{
+				Text: "Review diff>> ",
+				Answer: func(input string, state *GameState) CommandResult {
+					var diff strings.Builder
+					for _, filename := range parseSelection(input, staged) {
+						committedHash := lastCommittedHash(state, filename)
+						diff.WriteString(unifiedDiff(filename, state.Objects[committedHash], state.StagingArea[filename].Content, committedHash == ""))
+					}
+					return interactiveMenu(state, strings.TrimRight(diff.String(), "\n"))
+				},
+			},
+		}
+	case "7", "q", "quit":
+		return CommandResult{
+			Success:   true,
+			Message:   "Bye.",
+			SCPEffect: "📋 Interactive staging session closed",
+		}
+	default:
+		return interactiveMenu(state, "status        - show paths with changes\n"+
+			"update        - add working tree state to the staged set of changes\n"+
+			"revert        - revert staged set of changes back to the HEAD version\n"+
+			"patch         - pick hunks and update selectively\n"+
+			"diff          - view diff between HEAD and index\n"+
+			"add untracked - add contents of untracked files to the staged set of changes")
+	}
+}
+
+// interactiveSelect lists candidate files and applies an action to the chosen ones
+func interactiveSelect(state *GameState, label string, files []string, action func(*GameState, []string) string) CommandResult {
+	if len(files) == 0 {
+		return interactiveMenu(state, "No changes.")
+	}
+	return CommandResult{
+		Success: true,
+		Message: numberedFiles(files),
+		Prompt: &PromptRequest{
+			Text: label + ">> ",
+			Answer: func(input string, state *GameState) CommandResult {
+				selected := parseSelection(input, files)
+				if len(selected) == 0 {
+					return interactiveMenu(state, "")
+				}
+				return interactiveMenu(state, action(state, selected))
+			},
+		},
+	}
+}
+
+// interactiveStatus renders the staged/unstaged line counts per path
+func interactiveStatus(state *GameState) string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, filename := range append(sortedKeys(state.StagingArea), modifiedFiles(state)...) {
+		if !seen[filename] {
+			seen[filename] = true
+			paths = append(paths, filename)
+		}
+	}
+	if len(paths) == 0 {
+		return "No changes."
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("%5s %12s %12s %s\n", "", "staged", "unstaged", "path"))
+	for i, filename := range paths {
+		committed := state.Objects[lastCommittedHash(state, filename)]
+		index, _, _ := indexVersion(state, filename)
+
+		staged := "unchanged"
+		if entry, exists := state.StagingArea[filename]; exists {
+			staged = lineStats(committed, entry.Content)
+		}
+		unstaged := "nothing"
+		if working, exists := state.WorkingDir[filename]; exists && working.Content != index {
+			unstaged = lineStats(index, working.Content)
+		}
+		out.WriteString(fmt.Sprintf("%5s %12s %12s %s\n", fmt.Sprintf("%d:", i+1), staged, unstaged, filename))
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// lineStats summarizes a diff as "+added/-removed"
+func lineStats(oldText, newText string) string {
+	added, removed := 0, 0
+	for _, op := range diffLines(oldText, newText) {
+		switch op.Kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return fmt.Sprintf("+%d/-%d", added, removed)
+}
+
+// numberedFiles lists files with the 1-based numbers used for selection
+func numberedFiles(files []string) string {
+	var out strings.Builder
+	for i, filename := range files {
+		out.WriteString(fmt.Sprintf("%5s %s\n", fmt.Sprintf("%d:", i+1), filename))
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// parseSelection resolves "1", "1,3", "2-4" or "*" against a numbered list
+func parseSelection(input string, files []string) []string {
+	input = strings.TrimSpace(input)
+	if input == "*" {
+		return files
+	}
+
+	chosen := make([]bool, len(files))
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		from, to := field, field
+		if dash := strings.Index(field, "-"); dash > 0 {
+			from, to = field[:dash], field[dash+1:]
+		}
+		start, err1 := strconv.Atoi(from)
+		end, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for n := start; n <= end; n++ {
+			if n >= 1 && n <= len(files) {
+				chosen[n-1] = true
+			}
+		}
+	}
+
+	var selected []string
+	for i, ok := range chosen {
+		if ok {
+			selected = append(selected, files[i])
+		}
+	}
+	return selected
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// newPatchTestState commits a research log and then lets the entity inject
+// lines between the researcher's own edits
+func newPatchTestState(t *testing.T) *GameState {
+	t.Helper()
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+
+	original := "Day 1: discovery\nDay 2: quiet\nDay 3: quiet\nDay 4: quiet\nDay 5: quiet\nDay 6: quiet\nDay 7: quiet\nDay 8: quiet\nDay 9: quiet"
+	commitFile(t, state, "research.log", original, "Research log")
+
+	modified := "Day 1: discovery\nDay 2: noise in server room\nDay 3: quiet\nDay 4: quiet\nDay 5: quiet\nDay 6: quiet\nDay 7: quiet\nDay 8: quiet\nDay 9: quiet\nI AM WATCHING YOU"
+	state.WorkingDir["research.log"] = FileState{Content: modified, Hash: hashContent(modified)}
+	return state
+}
+
+func TestDiffHunks(t *testing.T) {
+	ops := diffLines("a\nb\nc", "a\nB\nc\nd")
+	hunks := buildHunks(ops, diffContext)
+	if len(hunks) != 1 {
+		t.Fatalf("Expected 1 hunk, got %d", len(hunks))
+	}
+	if header := hunkHeader(ops, hunks[0]); header != "@@ -1,3 +1,4 @@" {
+		t.Errorf("Unexpected hunk header %q", header)
+	}
+	if got := applyHunks(ops, hunks, false); got != "a\nB\nc\nd" {
+		t.Errorf("Applying every hunk should give the new text, got %q", got)
+	}
+	if got := applyHunks(ops, nil, false); got != "a\nb\nc" {
+		t.Errorf("Applying no hunks should give the old text, got %q", got)
+	}
+}
+
+func TestAddPatchStagesSelectedHunks(t *testing.T) {
+	state := newPatchTestState(t)
+
+	result := (&AddCommand{}).Execute([]string{"-p"}, state)
+	if result.Prompt == nil {
+		t.Fatalf("add -p should prompt for hunks, got %q", result.Message)
+	}
+	if !strings.Contains(result.Prompt.Text, "(1/2)") {
+		t.Errorf("Expected two hunks, got prompt %q", result.Prompt.Text)
+	}
+
+	// Stage the researcher's line, leave the entity's injection unstaged
+	result = result.Prompt.Answer("y", state)
+	if result.Prompt == nil || !strings.Contains(result.Message, "I AM WATCHING YOU") {
+		t.Fatalf("Second hunk should contain the injected line, got %q", result.Message)
+	}
+	result = result.Prompt.Answer("n", state)
+	if result.Prompt != nil {
+		t.Fatal("Session should end after the last hunk")
+	}
+
+	staged := state.StagingArea["research.log"].Content
+	if !strings.Contains(staged, "noise in server room") {
+		t.Error("Accepted hunk should be staged")
+	}
+	if strings.Contains(staged, "WATCHING") {
+		t.Error("Rejected hunk should not be staged")
+	}
+
+	status := (&StatusCommand{}).Execute([]string{}, state)
+	if !strings.Contains(status.Message, "Changes to be committed") || !strings.Contains(status.Message, "Changes not staged for commit") {
+		t.Errorf("Partially staged file should appear in both status sections:\n%s", status.Message)
+	}
+}
+
+func TestAddPatchSplitAndEdit(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "notes.txt", "one\ntwo\nthree", "Notes")
+	state.WorkingDir["notes.txt"] = FileState{Content: "ONE\ntwo\nTHREE", Hash: hashContent("ONE\ntwo\nTHREE")}
+
+	result := (&AddCommand{}).Execute([]string{"-p", "notes.txt"}, state)
+	if !strings.Contains(result.Prompt.Text, ",s,") {
+		t.Fatalf("Hunk with two changes should offer split, got %q", result.Prompt.Text)
+	}
+
+	result = result.Prompt.Answer("s", state)
+	if !strings.Contains(result.Prompt.Text, "(1/2)") {
+		t.Fatalf("Split should produce two hunks, got %q", result.Prompt.Text)
+	}
+
+	// Edit the first piece so only part of the change is staged
+	result = result.Prompt.Answer("e", state)
+	if result.Edit == nil {
+		t.Fatal("e should open the hunk editor")
+	}
+	result = result.Edit.Finish("-one\n+One\n two\n", state)
+	if result.Prompt == nil {
+		t.Fatalf("Edited hunk should apply and move on, got %q", result.Message)
+	}
+	result.Prompt.Answer("n", state)
+
+	if got := state.StagingArea["notes.txt"].Content; got != "One\ntwo\nthree" {
+		t.Errorf("Expected edited hunk to be staged, got %q", got)
+	}
+}
+
+func TestAddInteractiveUpdateAndRevert(t *testing.T) {
+	state := newPatchTestState(t)
+
+	result := (&AddCommand{}).Execute([]string{"-i"}, state)
+	if result.Prompt == nil || !strings.Contains(result.Message, "*** Commands ***") {
+		t.Fatalf("add -i should show the command menu, got %q", result.Message)
+	}
+
+	result = result.Prompt.Answer("2", state)
+	result = result.Prompt.Answer("1", state)
+	if _, staged := state.StagingArea["research.log"]; !staged {
+		t.Fatal("update should stage the selected path")
+	}
+
+	result = result.Prompt.Answer("3", state)
+	result = result.Prompt.Answer("*", state)
+	if len(state.StagingArea) != 0 {
+		t.Error("revert should unstage the selected path")
+	}
+
+	result = result.Prompt.Answer("7", state)
+	if result.Prompt != nil {
+		t.Error("quit should close the menu")
+	}
+}
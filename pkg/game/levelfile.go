@@ -0,0 +1,370 @@
+package game
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Levels beyond the built-in ones are written as level files: YAML, or JSON,
+// which the same decoder reads. The files under levels/ ship with the game;
+// more can be loaded from a directory with LoadLevelDir. See docs/guides/LEVEL_FILES.md.
+
+// LevelFile is the on-disk form of a level
+type LevelFile struct {
+	ID          int    `yaml:"id"`
+	Title       string `yaml:"title"`
+	SCPNumber   string `yaml:"scp_number"`
+	ObjectClass string `yaml:"object_class"`
+	Description string `yaml:"description"`
+	Objective   string `yaml:"objective"`
+
+	ContainmentProcedures string `yaml:"containment_procedures"`
+	IncidentReport        string `yaml:"incident_report"`
+
+	Files            map[string]string `yaml:"files"`
+	Repository       *RepositorySetup  `yaml:"repository"`
+	RequiredCommands []string          `yaml:"required_commands"`
+	Objectives       []ObjectiveFile   `yaml:"objectives"`
+	Completion       string            `yaml:"completion"`
+
+	Entity    []EntityBehavior  `yaml:"entity"`
+	Actors    []Actor           `yaml:"actors"`
+	Awareness AwarenessConfig   `yaml:"awareness"`
+	Hooks     map[string]string `yaml:"hooks"`
+
+	Score   int   `yaml:"score"`
+	Unlocks []int `yaml:"unlocks"`
+}
+
+// ObjectiveFile is an objective whose check is a predicate (see CompilePredicate)
+type ObjectiveFile struct {
+	Description string `yaml:"description"`
+	Check       string `yaml:"check"`
+	Hint        string `yaml:"hint"`
+	Points      int    `yaml:"points"`
+}
+
+// LevelFileError lists everything wrong with a level file
+type LevelFileError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *LevelFileError) Error() string {
+	return fmt.Sprintf("level file %s:\n  - %s", e.Path, strings.Join(e.Problems, "\n  - "))
+}
+
+// objectClasses are the object classes a level may declare
+var objectClasses = []string{"Safe", "Euclid", "Keter", "Thaumiel", "Apollyon"}
+
+// ParseLevelFile decodes and validates a level file; name is only used in errors
+func ParseLevelFile(name string, data []byte) (*Level, error) {
+	var file LevelFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, &LevelFileError{Path: name, Problems: []string{strings.TrimPrefix(err.Error(), "yaml: ")}}
+		}
+		// "line 4: field colour not found in type game.LevelFile" names a Go type
+		problems := make([]string, len(typeErr.Errors))
+		for i, msg := range typeErr.Errors {
+			if at := strings.Index(msg, " in type game."); at >= 0 && strings.Contains(msg, "not found") {
+				msg = msg[:at]
+			}
+			problems[i] = msg
+		}
+		return nil, &LevelFileError{Path: name, Problems: problems}
+	}
+
+	level, problems := file.build()
+	if len(problems) > 0 {
+		return nil, &LevelFileError{Path: name, Problems: problems}
+	}
+	return level, nil
+}
+
+// build validates the file and turns it into a Level, returning every
+// problem found rather than the first
+func (f *LevelFile) build() (*Level, []string) {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if f.ID <= 0 {
+		problem("id: must be a positive number")
+	}
+	for _, field := range []struct{ name, value string }{{"title", f.Title}, {"description", f.Description}, {"objective", f.Objective}} {
+		if strings.TrimSpace(field.value) == "" {
+			problem("%s: required", field.name)
+		}
+	}
+	if f.ObjectClass != "" && !containsString(objectClasses, f.ObjectClass) {
+		problem("object_class: %q is not one of %s", f.ObjectClass, strings.Join(objectClasses, ", "))
+	}
+	if f.Score < 0 {
+		problem("score: must not be negative")
+	}
+	for i, next := range f.Unlocks {
+		if next <= 0 {
+			problem("unlocks[%d]: must be a positive level number", i)
+		}
+	}
+
+	for _, file := range sortedNames(f.Files) {
+		if clean := path.Clean(file); clean != file || path.IsAbs(file) || strings.HasPrefix(file, "../") || file == ".git" || strings.HasPrefix(file, ".git/") {
+			problem("files: %q is not a plain path inside the repository", file)
+		}
+	}
+	for i, spec := range f.RequiredCommands {
+		if len(strings.Fields(spec)) == 0 {
+			problem("required_commands[%d]: empty command", i)
+		}
+	}
+	for _, name := range sortedNames(f.Hooks) {
+		if !containsString(supportedHooks, name) {
+			problem("hooks: unsupported hook %q (supported: %s)", name, strings.Join(supportedHooks, ", "))
+		}
+	}
+	if f.Awareness.Start < 0 || f.Awareness.Start > MaxAwareness {
+		problem("awareness.start: must be between 0 and %d", MaxAwareness)
+	}
+
+	// The goal: an ordered checklist of compiled predicates
+	if len(f.Objectives) == 0 {
+		problem("objectives: at least one objective is required")
+	}
+	objectives := make([]Objective, 0, len(f.Objectives))
+	seen := make(map[string]bool)
+	for i, o := range f.Objectives {
+		if strings.TrimSpace(o.Description) == "" {
+			problem("objectives[%d].description: required", i)
+		} else if seen[o.Description] {
+			problem("objectives[%d].description: %q is used twice", i, o.Description)
+		}
+		seen[o.Description] = true
+		if o.Points < 0 {
+			problem("objectives[%d].points: must not be negative", i)
+		}
+		check, err := CompilePredicate(o.Check)
+		if err != nil {
+			problem("objectives[%d].check: %v", i, err)
+		}
+		objectives = append(objectives, Objective{Description: o.Description, Hint: o.Hint, Points: o.Points, Check: check})
+	}
+
+	if f.Repository != nil {
+		problems = append(problems, f.Repository.validate()...)
+	}
+	problems = append(problems, validateEntity(f.Entity)...)
+	problems = append(problems, validateActors(f.Actors)...)
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	completion := f.Completion
+	if completion == "" {
+		completion = fmt.Sprintf("✅ %s complete.", f.Title)
+	}
+	return &Level{
+		ID:               f.ID,
+		Title:            f.Title,
+		SCPNumber:        f.SCPNumber,
+		Description:      f.Description,
+		Objective:        f.Objective,
+		ObjectClass:      f.ObjectClass,
+		ContainmentProcs: f.ContainmentProcedures,
+		IncidentReport:   f.IncidentReport,
+		InitialFiles:     f.Files,
+		Repository:       f.Repository,
+		RequiredCommands: f.RequiredCommands,
+		Objectives:       objectives,
+		Completion:       completion,
+		Entity:           f.Entity,
+		Actors:           f.Actors,
+		Awareness:        f.Awareness,
+		Hooks:            f.Hooks,
+		ScoreReward:      f.Score,
+		UnlocksNext:      f.Unlocks,
+	}, nil
+}
+
+// validateEntity checks a level file's entity scripts
+func validateEntity(behaviors []EntityBehavior) []string {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	names := make(map[string]bool)
+	for i, b := range behaviors {
+		at := fmt.Sprintf("entity[%d]", i)
+		if b.Name == "" {
+			problem("%s.name: required", at)
+		} else if names[b.Name] {
+			problem("%s.name: %q is used twice", at, b.Name)
+		}
+		names[b.Name] = true
+
+		t := b.Trigger
+		if t.AfterCommands <= 0 && t.EveryCommands <= 0 && t.AfterCommand == "" {
+			problem("%s.trigger: needs after_commands, every_commands or after_command", at)
+		}
+		if len(b.Actions) == 0 {
+			problem("%s.actions: at least one action is required", at)
+		}
+		for j, action := range b.Actions {
+			at := fmt.Sprintf("%s.actions[%d]", at, j)
+			switch action.Kind {
+			case EntityAppend, EntityRewrite, EntityCreate, EntityDelete, EntityForgeCommit:
+				if action.File == "" {
+					problem("%s.file: required for %s", at, action.Kind)
+				}
+			case EntityCreateBranch:
+				if action.Branch == "" {
+					problem("%s.branch: required for %s", at, action.Kind)
+				}
+			case EntityRewriteCommit:
+				if action.CommitMessage == "" {
+					problem("%s.commit_message: required for %s", at, action.Kind)
+				}
+			default:
+				problem("%s.kind: unknown kind %q", at, action.Kind)
+			}
+		}
+	}
+	return problems
+}
+
+// validateActors checks a level file's collaborator scripts
+func validateActors(actors []Actor) []string {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	names := make(map[string]bool)
+	for i, a := range actors {
+		at := fmt.Sprintf("actors[%d]", i)
+		if a.Name == "" {
+			problem("%s.name: required", at)
+		} else if names[a.Name] {
+			problem("%s.name: %q is used twice", at, a.Name)
+		}
+		names[a.Name] = true
+
+		t := a.Trigger
+		if t.AfterCommands <= 0 && t.EveryCommands <= 0 && !t.AfterPush {
+			problem("%s.trigger: needs after_commands, every_commands or after_push", at)
+		}
+		if len(a.Commits) == 0 {
+			problem("%s.commits: at least one commit is required", at)
+		}
+		for j, c := range a.Commits {
+			if c.Message == "" {
+				problem("%s.commits[%d].message: required", at, j)
+			}
+			if len(c.Files) == 0 {
+				problem("%s.commits[%d].files: at least one file is required", at, j)
+			}
+		}
+	}
+	return problems
+}
+
+// sortedNames returns a map's keys in order, so problems are reported stably
+func sortedNames(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isLevelFile reports whether a file name has a level file extension
+func isLevelFile(name string) bool {
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// loadedLevels are the levels read from level files, by ID. They take the
+// place of built-in levels with the same ID.
+var loadedLevels = make(map[int]*Level)
+
+//go:embed levels
+var embeddedLevels embed.FS
+
+func init() {
+	if err := loadLevels(embeddedLevels, "levels", "levels"); err != nil {
+		panic(err)
+	}
+}
+
+// LoadLevelDir loads every level file in a directory. Nothing is loaded
+// unless every file is valid.
+func LoadLevelDir(dir string) error {
+	if info, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("level directory: %w", err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("level directory: %s is not a directory", dir)
+	}
+	return loadLevels(os.DirFS(dir), ".", dir)
+}
+
+// loadLevels parses the level files in a directory of fsys and registers
+// them; errors name the files under display
+func loadLevels(fsys fs.FS, dir, display string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	levels := make(map[int]*Level)
+	files := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !isLevelFile(entry.Name()) {
+			continue
+		}
+		name := filepath.Join(display, entry.Name())
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		level, err := ParseLevelFile(name, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, exists := files[level.ID]; exists {
+			errs = append(errs, &LevelFileError{Path: name, Problems: []string{fmt.Sprintf("id: %d is already used by %s", level.ID, other)}})
+			continue
+		}
+		levels[level.ID], files[level.ID] = level, name
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for id, level := range levels {
+		loadedLevels[id] = level
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompilePredicate(t *testing.T) {
+	state := newReportTestState(t)
+	state.Branches["side"] = []string{state.Branches["main"][0]}
+	state.CommandHistory = []CommandRecord{{Git: true, Name: "log", Args: []string{"-p"}, Success: true}}
+	writeFile(state, "notes.txt", "the entity is listening")
+
+	cases := map[string]bool{
+		"initialized":                         true,
+		"commits >= 3":                        true,
+		"commits side == 1":                   true,
+		"branches > 2":                        false,
+		`used "git log -p"`:                   true,
+		`used "git show"`:                     false,
+		"on main and committed b.txt":         true,
+		"committed notes.txt":                 false,
+		`file notes.txt contains "listening"`: true,
+		"clean":                               false,
+		"not clean":                           true,
+		"branch missing or tags == 0":         true,
+		"branch side and tags > 0 or on side": false,
+	}
+	for expr, want := range cases {
+		p, err := CompilePredicate(expr)
+		if err != nil {
+			t.Errorf("CompilePredicate(%q): %v", expr, err)
+			continue
+		}
+		if got := p(state); got != want {
+			t.Errorf("%q = %v, want %v", expr, got, want)
+		}
+	}
+
+	for expr, want := range map[string]string{
+		"":                "empty predicate",
+		"comits > 1":      `unknown condition "comits"`,
+		"commits => 3":    `unknown comparison "=>"`,
+		"commits >= many": `"many" is not a number`,
+		"used":            `"used" takes 1 argument(s), got 0`,
+		"initialized and": "missing condition",
+	} {
+		if _, err := CompilePredicate(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("CompilePredicate(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+}
+
+func TestParseLevelFile(t *testing.T) {
+	level, err := ParseLevelFile("drill.json", []byte(`{
+		"id": 6, "title": "Drill", "description": "A drill", "objective": "Stash the report",
+		"files": {"drill.txt": "log"},
+		"objectives": [{"description": "Shelve the report", "check": "used \"git stash\"", "points": 40}],
+		"entity": [{"name": "restless", "trigger": {"every_commands": 4}, "actions": [{"kind": "append", "file": "drill.txt", "content": "boo"}]}],
+		"actors": [{"name": "Dr. Clef", "trigger": {"after_push": true}, "commits": [{"message": "Night report", "files": {"night.log": "quiet"}}]}],
+		"score": 250
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.ID != 6 || level.InitialFiles["drill.txt"] != "log" || level.ScoreReward != 250 || level.Completion != "✅ Drill complete." {
+		t.Errorf("Unexpected level %+v", level)
+	}
+	if level.Entity[0].Trigger.EveryCommands != 4 || level.Objectives[0].Points != 40 {
+		t.Errorf("Entity scripts and objectives should be read, got %+v", level)
+	}
+	if len(level.Actors) != 1 || !level.Actors[0].Trigger.AfterPush || level.Actors[0].Commits[0].Files["night.log"] != "quiet" {
+		t.Errorf("Actors should be read, got %+v", level.Actors)
+	}
+
+	_, err = ParseLevelFile("broken.yaml", []byte(`
+id: 0
+title: Broken
+description: d
+object_class: Unknown
+files:
+  ../escape.txt: x
+objectives:
+  - description: a
+    check: commits => 3
+  - description: a
+    check: clean
+entity:
+  - name: x
+    trigger: {}
+    actions: [{kind: append}]
+actors:
+  - name: Dr. Clef
+    trigger: {}
+    commits: [{files: {a.txt: x}}]
+`))
+	want := `level file broken.yaml:
+  - id: must be a positive number
+  - objective: required
+  - object_class: "Unknown" is not one of Safe, Euclid, Keter, Thaumiel, Apollyon
+  - files: "../escape.txt" is not a plain path inside the repository
+  - objectives[0].check: unknown comparison "=>"
+  - objectives[1].description: "a" is used twice
+  - entity[0].trigger: needs after_commands, every_commands or after_command
+  - entity[0].actions[0].file: required for append
+  - actors[0].trigger: needs after_commands, every_commands or after_push
+  - actors[0].commits[0].message: required`
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected every problem listed, got:\n%v", err)
+	}
+
+	if _, err = ParseLevelFile("typo.yaml", []byte("id: 7\ntitel: Typo\n")); err == nil || !strings.Contains(err.Error(), "line 2: field titel not found") {
+		t.Errorf("Unknown fields should be rejected, got %v", err)
+	}
+}
+
+func TestLoadLevelDir(t *testing.T) {
+	defer func(saved map[int]*Level) { loadedLevels = saved }(loadedLevels)
+	loadedLevels = make(map[int]*Level)
+
+	dir := t.TempDir()
+	valid := "id: 2\ntitle: Replacement\ndescription: d\nobjective: o\nobjectives:\n  - {description: init, check: initialized}\n"
+	os.WriteFile(filepath.Join(dir, "02-replacement.yaml"), []byte(valid), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("not a level"), 0o644)
+	os.WriteFile(filepath.Join(dir, "02-again.yml"), []byte(valid), 0o644)
+
+	err := LoadLevelDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "id: 2 is already used by") {
+		t.Fatalf("Duplicate IDs should be rejected, got %v", err)
+	}
+	if GetLevel(2) != &Level2 {
+		t.Error("Nothing should be loaded from a directory with invalid files")
+	}
+
+	os.Remove(filepath.Join(dir, "02-again.yml"))
+	if err := LoadLevelDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel(2).Title != "Replacement" {
+		t.Error("A level file should replace the built-in level with its ID")
+	}
+	if err := LoadLevelDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("A missing directory should be an error")
+	}
+}
+
+func TestEmbeddedLevel5(t *testing.T) {
+	if next := GetLevel(4).UnlocksNext; len(next) == 0 || GetLevel(next[0]) == nil {
+		t.Fatal("Level 4 should unlock the embedded Level 5")
+	}
+
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+	if err := engine.StartLevel(5); err != nil {
+		t.Fatal(err)
+	}
+	for _, command := range []string{
+		"git add milestone.txt breach.log",
+		"git commit -m 'Record milestone'",
+		"git tag -a containment-stable -m 'Stable containment'",
+		"git show containment-stable",
+	} {
+		engine.ProcessCommand(command)
+	}
+	if !strings.Contains(engine.State.WorkingDir["breach.log"].Content, "NOT WRITTEN BY STAFF") {
+		t.Fatal("Tagging should provoke the entity")
+	}
+	if engine.IsLevelComplete() {
+		t.Fatal("The entity's entry still has to be recorded")
+	}
+	result := engine.ProcessCommand("git commit -am 'Record entity entry'")
+	if !strings.Contains(result.SCPEffect, "LEVEL COMPLETE! ✅ Containment anchored.") {
+		t.Errorf("Level 5 should complete, got %q", result.SCPEffect)
+	}
+}
+
+func TestCampaignReachesLevel5(t *testing.T) {
+	engine := NewEngine()
+	scripts := map[int][]string{
+		1: {"git config user.name Bright", "git config user.email bright@site19.scp", "git init", "git add .", "git commit -m 'Initial containment'"},
+		2: {"git status", "git diff", "git add research.txt", "git add .", "git commit -a -m 'Document changes'"},
+		3: {"git log", "git log -p", "git show HEAD~1"},
+		4: {"git add .", "git commit -m 'Strategies'", "git branch", "git switch -c strategy-a", "git switch -c strategy-b",
+			"git switch strategy-a", "echo isolated >> strategy_a.txt", "git commit -am 'Isolate'", "git switch main", "git merge strategy-a"},
+		5: {"git add milestone.txt breach.log", "git commit -m 'Record milestone'", "git tag -a containment-stable -m 'Stable containment'",
+			"git show containment-stable", "git commit -am 'Record entity entry'"},
+	}
+
+	for level := 1; level <= 5; level++ {
+		if err := engine.StartLevel(level); err != nil {
+			t.Fatal(err)
+		}
+		for _, command := range scripts[level] {
+			engine.ProcessCommand(command)
+		}
+		if !engine.IsLevelComplete() {
+			_, msg := engine.CurrentLevel.Validate(engine.State)
+			t.Fatalf("Level %d should complete when played in campaign order: %s", level, msg)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package game
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // Level represents a game level with SCP theming
 type Level struct {
 	ID          int
@@ -13,18 +19,66 @@ type Level struct {
 	ContainmentProcs string
 	IncidentReport   string
 
-	// Game mechanics
+	// Game mechanics. With a Repository, InitialFiles are uncommitted
+	// changes on top of its history.
 	InitialFiles     map[string]string
+	Repository       *RepositorySetup
 	RequiredCommands []string
-	ValidateFunc     func(*GameState) (bool, string)
+
+	// The level's goal: an ordered checklist, and the message shown once it
+	// is done. ValidateFunc replaces the checklist for levels without one.
+	Objectives   []Objective
+	Completion   string
+	ValidateFunc func(*GameState) (bool, string)
+
+	// Scripted collaborators pushing to the shared remote
+	Actors []Actor
+
+	// The entity's own edits to the working directory, and how fast it learns
+	Entity    []EntityBehavior
+	Awareness AwarenessConfig
+
+	// Hook scripts installed when the level starts (see runHook)
+	Hooks map[string]string
 
 	// Rewards
 	ScoreReward int
 	UnlocksNext []int
 }
 
-// GetLevel returns the level definition for the given level number
+// Validate checks whether the level is complete: every objective has to be
+// met and every required command used this level. Otherwise it returns the
+// first thing still missing.
+func (l *Level) Validate(state *GameState) (bool, string) {
+	var completed bool
+	var msg string
+	switch {
+	case len(l.Objectives) > 0:
+		completed, msg = true, l.Completion
+		for _, status := range l.Checklist(state) {
+			if !status.Met {
+				completed, msg = false, "Outstanding objective: "+status.Description
+				break
+			}
+		}
+	case l.ValidateFunc != nil:
+		completed, msg = l.ValidateFunc(state)
+	}
+	if !completed {
+		return false, msg
+	}
+	if missing := state.MissingCommands(l.RequiredCommands); len(missing) > 0 {
+		return false, fmt.Sprintf("Protocols not yet performed: %s", strings.Join(missing, ", "))
+	}
+	return true, msg
+}
+
+// GetLevel returns the level definition for the given level number. Levels
+// loaded from level files take the place of built-in ones.
 func GetLevel(levelNum int) *Level {
+	if level, exists := loadedLevels[levelNum]; exists {
+		return level
+	}
 	switch levelNum {
 	case 1:
 		return &Level1
@@ -70,24 +124,37 @@ Multiple files showing autonomous behavior patterns.
 Dr. ████████ assigned as lead researcher.
 IMMEDIATE ACTION: Establish version control for all files.`,
 
-	ValidateFunc: func(state *GameState) (bool, string) {
-		// Check git config was used
-		if state.ConfigName == "" || state.ConfigEmail == "" {
-			return false, "Researcher identity not configured (use git config)"
-		}
-		if !state.IsInitialized {
-			return false, "Repository not initialized"
-		}
-		if len(state.Commits) == 0 {
-			return false, "No commits found - initial containment incomplete"
-		}
-		// Check that all files were committed
-		commit := state.Commits[len(state.Commits)-1]
-		if len(commit.Files) < 3 {
-			return false, "Not all files contained - use 'git add .' to stage all files"
-		}
-		return true, "✅ Initial containment established. All files secured."
+	Objectives: []Objective{
+		{
+			Description: "Configure researcher identity",
+			Hint:        `git config user.name "Your Name" and git config user.email "email@site.com"`,
+			Points:      25,
+			Check: func(state *GameState) bool {
+				return state.ConfigName != "" && state.ConfigEmail != "" && state.UsedCommand("git config")
+			},
+		},
+		{
+			Description: "Initialize the containment repository",
+			Hint:        "git init",
+			Points:      25,
+			Check:       func(state *GameState) bool { return state.IsInitialized },
+		},
+		{
+			Description: "Stage every discovered file",
+			Hint:        "git add .",
+			Points:      25,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git add .") },
+		},
+		{
+			Description: "Commit all files in an initial containment record",
+			Hint:        `git commit -m "Initial containment"`,
+			Points:      25,
+			Check: func(state *GameState) bool {
+				return len(state.Commits) > 0 && len(state.Commits[len(state.Commits)-1].Files) >= 3
+			},
+		},
 	},
+	Completion: "✅ Initial containment established. All files secured.",
 
 	ScoreReward: 100,
 	UnlocksNext: []int{2},
@@ -119,29 +186,61 @@ var Level2 = Level{
 
 IMPORTANT: The entity learns from our actions. Monitor all changes.`,
 
+	// The entity keeps writing while the researcher investigates
+	Entity: []EntityBehavior{
+		{
+			Name:    "self-modification",
+			Trigger: EntityTrigger{EveryCommands: 3},
+			Actions: []EntityAction{
+				{Kind: EntityAppend, File: "anomaly.txt", Content: "Line 3: It knows you are reading this\n", Message: "👁️  anomaly.txt flickers. Something was written while you looked away."},
+				{Kind: EntityAppend, File: "containment.log", Content: "Day 3: [ENTRY NOT WRITTEN BY STAFF]\n"},
+				{Kind: EntityCreate, File: "whispers.txt", Content: "we are in the history now\n", Message: "👁️  A file nobody created has appeared in the containment directory."},
+			},
+		},
+	},
+
 	IncidentReport: `INCIDENT LOG ████-2
 08:30 - Routine file check reveals autonomous modifications
 08:45 - Multiple files show timestamp changes without user input
 09:00 - Content analysis reveals structured patterns in modifications
 ACTION: Document all changes for pattern analysis`,
 
-	ValidateFunc: func(state *GameState) (bool, string) {
-		// This level starts with files already committed from Level 1
-		// So we need at least 2 commits (initial + modifications)
-		if len(state.Commits) < 2 {
-			return false, "Modifications not yet committed"
-		}
-
-		// Check that the player used git diff or status (tracked by command history)
-		// For now, we'll just check that they made a commit
-		lastCommit := state.Commits[len(state.Commits)-1]
-
-		if len(lastCommit.Files) < 3 {
-			return false, "Not all modified files were committed"
-		}
-
-		return true, "✅ All modifications documented. Pattern analysis complete."
+	Objectives: []Objective{
+		{
+			Description: "Check for autonomous modifications",
+			Hint:        "git status",
+			Points:      20,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git status") },
+		},
+		{
+			Description: "Examine the entity's changes",
+			Hint:        "git diff",
+			Points:      20,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git diff") },
+		},
+		{
+			Description: "Stage a modified file",
+			Hint:        "git add <file>",
+			Points:      20,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git add") },
+		},
+		{
+			Description: "Commit all tracked modifications at once",
+			Hint:        `git commit -a -m "Document changes"`,
+			Points:      20,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git commit -a") },
+		},
+		{
+			// The level starts with the files already committed in Level 1
+			Description: "Document every modified file",
+			Hint:        "commit anomaly.txt, containment.log and the new files together",
+			Points:      20,
+			Check: func(state *GameState) bool {
+				return len(state.Commits) >= 2 && len(state.Commits[len(state.Commits)-1].Files) >= 3
+			},
+		},
 	},
+	Completion: "✅ All modifications documented. Pattern analysis complete.",
 
 	ScoreReward: 150,
 	UnlocksNext: []int{3},
@@ -153,8 +252,8 @@ var Level3 = Level{
 	Title:       "Historical Analysis",
 	SCPNumber:   "SCP-████-B",
 	ObjectClass: "Euclid",
-	Description: "Investigate the entity's past behavior through commit history analysis.",
-	Objective:   "Use git log, git log -p, and git show to understand the anomaly's evolution",
+	Description: "Investigate the entity's past behavior through commit history analysis. Earlier researchers left their records in the archive - and one record was not written by any of them.",
+	Objective:   "Use git log, git log -p, and git show to understand the anomaly's evolution and expose the record the entity forged",
 
 	InitialFiles: map[string]string{
 		"anomaly.txt":  "ERROR ERROR ERROR ERROR\nThe pattern is changing...",
@@ -163,32 +262,111 @@ var Level3 = Level{
 		"analysis.txt": "Pattern analysis results pending...",
 	},
 
+	// The archive the previous research team left behind
+	Repository: &RepositorySetup{
+		Commits: []SetupCommit{
+			{
+				Author:  "Dr. Gears",
+				Date:    time.Date(2019, time.October, 28, 9, 14, 0, 0, time.UTC),
+				Message: "Begin observation of SCP-████",
+				Files: map[string]string{
+					"anomaly.txt":  "This file writes itself...",
+					"timeline.txt": "Tracking anomaly evolution over time",
+				},
+			},
+			{
+				Label:   "first-contact",
+				Author:  "Dr. Gears",
+				Date:    time.Date(2019, time.October, 29, 16, 40, 0, 0, time.UTC),
+				Message: "Log first autonomous modification",
+				Files: map[string]string{
+					"anomaly.txt":  "This file writes itself...\nThe pattern is changing...",
+					"research.log": "Day 3: Entity shows learning behavior",
+				},
+			},
+			{
+				Author:  "Dr. Clef",
+				Date:    time.Date(2019, time.October, 30, 11, 5, 0, 0, time.UTC),
+				Message: "Begin pattern analysis",
+				Files:   map[string]string{"analysis.txt": "Pattern analysis results pending..."},
+			},
+			{
+				Author:  EntityAuthor,
+				Date:    time.Date(2019, time.October, 31, 3, 33, 0, 0, time.UTC),
+				Message: "Routine maintenance",
+				Files:   map[string]string{"anomaly.txt": "ERROR ERROR ERROR ERROR\nThe pattern is changing..."},
+			},
+			{
+				Author:  "Dr. Bright",
+				Date:    time.Date(2019, time.October, 31, 9, 2, 0, 0, time.UTC),
+				Message: "Record detected patterns",
+				Files:   map[string]string{"research.log": "Day 3: Entity shows learning behavior\nDay 4: Patterns detected in modifications"},
+			},
+		},
+		Tags: map[string]string{"first-contact": "first-contact"},
+		Stash: []SetupStash{
+			{
+				Message: "unfinished analysis - do not apply",
+				Files:   map[string]string{"analysis.txt": "Pattern analysis: every change lands right after someone reads the log.\nIt is watching the watchers."},
+			},
+		},
+	},
+
 	RequiredCommands: []string{"git log", "git log -p", "git show"},
 
 	ContainmentProcs: `CONTAINMENT PROTOCOL SCP-████-CP3:
 1. Use 'git log' to view commit history
 2. Use 'git log -p' to see detailed changes in each commit
 3. Use 'git show <commit>' to examine specific commits
-4. Document patterns in entity behavior
+4. Identify any record not signed by Foundation staff
 
 CRITICAL: Understanding its history may reveal weaknesses.`,
 
+	// Once it has watched enough, the entity edits the record itself
+	Awareness: AwarenessConfig{Start: 10},
+	Entity: []EntityBehavior{
+		{
+			Name:    "revisionism",
+			Trigger: EntityTrigger{AfterCommand: "log", MinAwareness: 12},
+			Actions: []EntityAction{
+				{Kind: EntityRewriteCommit, CommitMessage: "Nothing happened here. Stop looking.", Message: "👁️  The latest containment record now reads differently. Run 'git log' again."},
+			},
+		},
+	},
+
 	IncidentReport: `INCIDENT LOG ████-3
 10:00 - Historical analysis authorized by O5 Council
 10:30 - Previous researchers' notes recovered from commits
 11:00 - Pattern emerging in entity's modifications
 ACTION: Deep forensic analysis of all commits`,
 
-	ValidateFunc: func(state *GameState) (bool, string) {
-		// For historical analysis level, we just need to ensure commits exist
-		if len(state.Commits) < 3 {
-			return false, "Insufficient historical data for analysis"
-		}
-
-		// This level is complete once enough history exists
-		// In a real implementation, we'd track if git log commands were used
-		return true, "✅ Historical analysis complete. Entity patterns documented."
+	Objectives: []Objective{
+		{
+			Description: "Review the containment timeline",
+			Hint:        "git log",
+			Points:      25,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git log") },
+		},
+		{
+			Description: "Examine the detailed change history",
+			Hint:        "git log -p",
+			Points:      25,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git log -p") },
+		},
+		{
+			Description: "Investigate a specific commit",
+			Hint:        "git show <commit>",
+			Points:      25,
+			Check:       examinedSpecificCommit,
+		},
+		{
+			Description: "Expose the record the entity forged",
+			Hint:        "one commit in git log was not written by any researcher - git show it",
+			Points:      25,
+			Check:       examinedEntityCommit,
+		},
 	},
+	Completion: "✅ Historical analysis complete. Entity patterns documented.",
 
 	ScoreReward: 200,
 	UnlocksNext: []int{4},
@@ -228,21 +406,63 @@ CRITICAL: The entity adapts. Multiple strategies increase success probability.`,
 13:00 - Multiple research teams assigned different approaches
 ACTION: Implement branching strategy immediately`,
 
-	ValidateFunc: func(state *GameState) (bool, string) {
-		// Check for multiple branches
-		if len(state.Branches) < 3 {
-			return false, "Insufficient parallel experiments (need at least 3 branches)"
-		}
-
-		// Check for merge (main branch should have commits from other branches)
-		mainCommits := state.Branches["main"]
-		if len(mainCommits) < 4 {
-			return false, "No successful strategies merged to main branch"
-		}
-
-		return true, "✅ Optimal containment strategy identified and implemented."
+	Objectives: []Objective{
+		{
+			Description: "Open three lines of containment",
+			Hint:        "git switch -c strategy-a (one branch per strategy)",
+			Points:      30,
+			Check:       func(state *GameState) bool { return len(state.Branches) >= 3 },
+		},
+		{
+			Description: "Survey the experiments in progress",
+			Hint:        "git branch",
+			Points:      30,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git branch") },
+		},
+		{
+			Description: "Move between strategies",
+			Hint:        "git switch <branch>",
+			Points:      30,
+			Check:       func(state *GameState) bool { return state.UsedCommand("git switch") },
+		},
+		{
+			Description: "Merge a successful strategy into main",
+			Hint:        "git switch main, then git merge <branch>",
+			Points:      30,
+			Check: func(state *GameState) bool {
+				return state.UsedCommand("git merge") && len(state.Branches["main"]) >= 4
+			},
+		},
 	},
+	Completion: "✅ Optimal containment strategy identified and implemented.",
 
 	ScoreReward: 250,
 	UnlocksNext: []int{5},
 }
+
+// examinedSpecificCommit reports whether the player has shown a commit by
+// name; examining the latest commit alone is not forensic analysis
+func examinedSpecificCommit(state *GameState) bool {
+	for _, record := range state.CommandHistory {
+		if _, revisions := splitFlags(record.Args); record.Success && record.Matches("git show") && len(revisions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// examinedEntityCommit reports whether the player has shown a commit the
+// entity authored
+func examinedEntityCommit(state *GameState) bool {
+	for _, record := range state.CommandHistory {
+		if !record.Success || !record.Matches("git show") {
+			continue
+		}
+		for _, id := range record.Revisions {
+			if commit, found := state.findCommit(id); found && commit.Author == EntityAuthor {
+				return true
+			}
+		}
+	}
+	return false
+}
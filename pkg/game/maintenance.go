@@ -0,0 +1,411 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// commitParents returns the parents of a commit: those recorded when it was
+// made, otherwise the commit before it on a branch that contains it
+func commitParents(state *GameState, id string) []string {
+	if parents, recorded := state.CommitGraph[id]; recorded {
+		return parents
+	}
+	for _, branches := range []map[string][]string{state.Branches, state.RemoteBranches} {
+		for _, name := range sortedBranchNames(branches) {
+			if i := indexOf(branches[name], id); i > 0 {
+				return []string{branches[name][i-1]}
+			}
+		}
+	}
+	return nil
+}
+
+// reachableCommits marks every commit reachable from branches,
+// remote-tracking branches and tags, and optionally from the reflog
+func reachableCommits(state *GameState, includeReflog bool) map[string]bool {
+	var roots []string
+	for _, branches := range []map[string][]string{state.Branches, state.RemoteBranches} {
+		for _, commits := range branches {
+			roots = append(roots, commits...)
+		}
+	}
+	for _, tag := range state.Tags {
+		roots = append(roots, tag.Commit)
+	}
+	if includeReflog {
+		roots = append(roots, reflogCommits(state)...)
+	}
+
+	reachable := make(map[string]bool)
+	for len(roots) > 0 {
+		id := roots[len(roots)-1]
+		roots = roots[:len(roots)-1]
+		if reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		roots = append(roots, commitParents(state, id)...)
+	}
+	return reachable
+}
+
+// reachableObjects marks the blobs still in use: those of reachable commits,
+// of commits held by remotes, of every index, and of notes
+func reachableObjects(state *GameState, commits map[string]bool) map[string]bool {
+	objects := make(map[string]bool)
+	for _, commit := range state.Commits {
+		if commits[commit.ID] {
+			for _, hash := range commit.Files {
+				objects[hash] = true
+			}
+		}
+	}
+	for _, remote := range state.Remotes {
+		for _, commit := range remote.Commits {
+			for _, hash := range commit.Files {
+				objects[hash] = true
+			}
+		}
+	}
+	indexes := []map[string]FileState{state.StagingArea}
+	for _, wt := range state.Worktrees {
+		indexes = append(indexes, wt.StagingArea)
+	}
+	for _, index := range indexes {
+		for _, file := range index {
+			objects[file.Hash] = true
+		}
+	}
+	for _, note := range state.Notes {
+		objects[hashContent(note)] = true
+	}
+	return objects
+}
+
+// unreachable lists what nothing refers to, split into objects that nothing
+// at all refers to (dangling) and objects only other unreachable ones use
+type unreachable struct {
+	Commits, Blobs                 []string
+	DanglingCommits, DanglingBlobs []string
+}
+
+func findUnreachable(state *GameState, includeReflog bool) unreachable {
+	var u unreachable
+	commits := reachableCommits(state, includeReflog)
+	objects := reachableObjects(state, commits)
+
+	usedByUnreachable := make(map[string]bool)
+	for _, commit := range state.Commits {
+		if commits[commit.ID] {
+			continue
+		}
+		u.Commits = append(u.Commits, commit.ID)
+		for _, parent := range commitParents(state, commit.ID) {
+			usedByUnreachable[parent] = true
+		}
+		for _, hash := range commit.Files {
+			usedByUnreachable[hash] = true
+		}
+	}
+	for hash := range state.Objects {
+		if !objects[hash] {
+			u.Blobs = append(u.Blobs, hash)
+		}
+	}
+	sort.Strings(u.Commits)
+	sort.Strings(u.Blobs)
+
+	for _, id := range u.Commits {
+		if !usedByUnreachable[id] {
+			u.DanglingCommits = append(u.DanglingCommits, id)
+		}
+	}
+	for _, hash := range u.Blobs {
+		if !usedByUnreachable[hash] {
+			u.DanglingBlobs = append(u.DanglingBlobs, hash)
+		}
+	}
+	return u
+}
+
+// pruneUnreachable deletes unreachable commits and blobs and returns how many
+// of each went
+func pruneUnreachable(state *GameState, u unreachable) (int, int) {
+	var kept []Commit
+	for _, commit := range state.Commits {
+		if !containsString(u.Commits, commit.ID) {
+			kept = append(kept, commit)
+		}
+	}
+	state.Commits = kept
+	for _, id := range u.Commits {
+		delete(state.CommitGraph, id)
+		delete(state.Packed, id)
+	}
+	for _, hash := range u.Blobs {
+		delete(state.Objects, hash)
+		delete(state.Packed, hash)
+	}
+	return len(u.Commits), len(u.Blobs)
+}
+
+// findObjectByPrefix finds a blob by full or abbreviated hash
+func findObjectByPrefix(state *GameState, prefix string) (string, bool) {
+	if len(prefix) < 4 {
+		return "", false
+	}
+	for hash := range state.Objects {
+		if strings.HasPrefix(hash, prefix) {
+			return hash, true
+		}
+	}
+	return "", false
+}
+
+// FsckCommand implements git fsck
+type FsckCommand struct{}
+
+func (c *FsckCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	showUnreachable, includeReflog := false, true
+	for _, arg := range args {
+		switch arg {
+		case "--unreachable":
+			showUnreachable = true
+		case "--no-reflogs":
+			includeReflog = false
+		case "--dangling", "--full", "--strict":
+		default:
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: unknown option `%s'", strings.TrimLeft(arg, "-")),
+				SCPEffect:    "🔴 ERROR: Malformed integrity check",
+				AnomalyDelta: 1,
+			}
+		}
+	}
+
+	u := findUnreachable(state, includeReflog)
+	kind, commits, blobs := "dangling", u.DanglingCommits, u.DanglingBlobs
+	if showUnreachable {
+		kind, commits, blobs = "unreachable", u.Commits, u.Blobs
+	}
+
+	var report strings.Builder
+	for _, id := range commits {
+		report.WriteString(fmt.Sprintf("%s commit %s\n", kind, id))
+	}
+	for _, hash := range blobs {
+		report.WriteString(fmt.Sprintf("%s blob %s\n", kind, hash))
+	}
+
+	result := CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(report.String(), "\n"),
+		SCPEffect: "✅ Archive integrity verified - every record is accounted for",
+	}
+	if len(commits)+len(blobs) > 0 {
+		result.SCPEffect = fmt.Sprintf("🔍 %d orphaned records found - inspect them with 'git show <id>' before gc destroys them", len(commits)+len(blobs))
+	}
+	return result
+}
+
+func (c *FsckCommand) Help() string {
+	return "Find dangling and unreachable objects"
+}
+
+func (c *FsckCommand) RequiredArgs() int {
+	return 0
+}
+
+// PruneCommand implements git prune
+type PruneCommand struct{}
+
+func (c *PruneCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	dryRun := containsString(args, "-n") || containsString(args, "--dry-run")
+	u := findUnreachable(state, true)
+
+	if dryRun {
+		var report strings.Builder
+		for _, id := range u.Commits {
+			report.WriteString(fmt.Sprintf("%s commit\n", id))
+		}
+		for _, hash := range u.Blobs {
+			report.WriteString(fmt.Sprintf("%s blob\n", hash))
+		}
+		return CommandResult{
+			Success:   true,
+			Message:   strings.TrimRight(report.String(), "\n"),
+			SCPEffect: fmt.Sprintf("📋 %d unreachable records would be destroyed", len(u.Commits)+len(u.Blobs)),
+		}
+	}
+
+	commits, blobs := pruneUnreachable(state, u)
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: pruneEffect(commits, blobs),
+	}
+}
+
+// pruneEffect describes what pruning destroyed
+func pruneEffect(commits, blobs int) string {
+	if commits+blobs == 0 {
+		return "🗑️  No unreachable records to destroy"
+	}
+	return fmt.Sprintf("🗑️  %d orphaned containment records and %d documents permanently destroyed", commits, blobs)
+}
+
+func (c *PruneCommand) Help() string {
+	return "Delete unreachable objects"
+}
+
+func (c *PruneCommand) RequiredArgs() int {
+	return 0
+}
+
+// GcCommand implements git gc. Everything reachable is packed; unreachable
+// objects are only pruned with --prune=now, since git's two-week grace
+// period outlasts any game session.
+type GcCommand struct{}
+
+func (c *GcCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	pruneNow := false
+	for _, arg := range args {
+		switch {
+		case arg == "--prune=now" || arg == "--prune=all":
+			pruneNow = true
+		case strings.HasPrefix(arg, "--prune"), arg == "--aggressive", arg == "--auto", arg == "--quiet", arg == "-q":
+		default:
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: unknown option `%s'", strings.TrimLeft(arg, "-")),
+				SCPEffect:    "🔴 ERROR: Malformed maintenance directive",
+				AnomalyDelta: 1,
+			}
+		}
+	}
+
+	commits, blobs := 0, 0
+	if pruneNow {
+		commits, blobs = pruneUnreachable(state, findUnreachable(state, true))
+	}
+
+	// Pack whatever is still reachable
+	reachable := reachableCommits(state, true)
+	objects := reachableObjects(state, reachable)
+	packed := 0
+	for id := range reachable {
+		if state.hasCommit(id) {
+			state.Packed[id] = true
+			packed++
+		}
+	}
+	for hash := range state.Objects {
+		if objects[hash] {
+			state.Packed[hash] = true
+			packed++
+		}
+	}
+
+	message := fmt.Sprintf("Enumerating objects: %d, done.\nCounting objects: 100%% (%d/%d), done.\nWriting objects: 100%% (%d/%d), done.\nTotal %d (delta 0), reused 0 (delta 0)", packed, packed, packed, packed, packed, packed)
+	return CommandResult{
+		Success:   true,
+		Message:   message,
+		SCPEffect: "🗜️  Archive compacted. " + pruneEffect(commits, blobs),
+	}
+}
+
+func (c *GcCommand) Help() string {
+	return "Pack objects and prune unreachable ones"
+}
+
+func (c *GcCommand) RequiredArgs() int {
+	return 0
+}
+
+// CountObjectsCommand implements git count-objects
+type CountObjectsCommand struct{}
+
+func (c *CountObjectsCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// Loose objects take a 4 KiB block each; packs store the raw bytes
+	loose, inPack, packBytes := 0, 0, 0
+	for _, commit := range state.Commits {
+		if state.Packed[commit.ID] {
+			inPack++
+			packBytes += len(commit.Message) + len(commit.Author) + 48*(len(commit.Files)+1)
+		} else {
+			loose++
+		}
+	}
+	for hash, content := range state.Objects {
+		if state.Packed[hash] {
+			inPack++
+			packBytes += len(content)
+		} else {
+			loose++
+		}
+	}
+
+	if !containsString(args, "-v") && !containsString(args, "--verbose") {
+		return CommandResult{
+			Success: true,
+			Message: fmt.Sprintf("%d objects, %d kilobytes", loose, loose*4),
+		}
+	}
+
+	packs := 0
+	if inPack > 0 {
+		packs = 1
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("count: %d\nsize: %d\nin-pack: %d\npacks: %d\nsize-pack: %d\nprune-packable: 0\ngarbage: 0\nsize-garbage: 0", loose, loose*4, inPack, packs, (packBytes+1023)/1024),
+		SCPEffect: "📋 Archive storage audited",
+	}
+}
+
+func (c *CountObjectsCommand) Help() string {
+	return "Count loose and packed objects"
+}
+
+func (c *CountObjectsCommand) RequiredArgs() int {
+	return 0
+}
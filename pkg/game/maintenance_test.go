@@ -0,0 +1,65 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFsckFindsDanglingObjects(t *testing.T) {
+	state := newReportTestState(t)
+	engine := &Engine{State: state}
+	engine.ProcessCommand("git switch -c evidence")
+	state.WorkingDir["sighting.txt"] = FileState{Content: "It moved.", Hash: hashContent("It moved.")}
+	engine.ProcessCommand("git add sighting.txt")
+	engine.ProcessCommand("git commit -m 'Entity sighting'")
+	evidence := headCommitID(state)
+	engine.ProcessCommand("git switch main")
+	engine.ProcessCommand("git branch -D evidence")
+
+	// A blob staged and then replaced is left dangling
+	state.WorkingDir["draft.txt"] = FileState{Content: "first", Hash: hashContent("first")}
+	engine.ProcessCommand("git add draft.txt")
+	state.WorkingDir["draft.txt"] = FileState{Content: "second", Hash: hashContent("second")}
+	engine.ProcessCommand("git add draft.txt")
+
+	result := engine.ProcessCommand("git reflog")
+	if !strings.Contains(result.Message, evidence[:7]+" HEAD@{1}: commit: Entity sighting") {
+		t.Errorf("The reflog should record the commit, got %q", result.Message)
+	}
+
+	fsck := &FsckCommand{}
+	if result = fsck.Execute(nil, state); result.Message != "dangling blob "+hashContent("first") {
+		t.Errorf("The reflog should protect the commit, got %q", result.Message)
+	}
+	if result = fsck.Execute([]string{"--no-reflogs"}, state); !strings.Contains(result.Message, "dangling commit "+evidence) {
+		t.Errorf("fsck --no-reflogs should report the deleted branch's commit, got %q", result.Message)
+	}
+	if result = (&ShowCommand{}).Execute([]string{hashContent("first")}, state); result.Message != "first" {
+		t.Errorf("show should print a dangling blob, got %q", result.Message)
+	}
+
+	// A dangling commit can be recovered with its ancestry
+	(&BranchCommand{}).Execute([]string{"recovered", evidence[:8]}, state)
+	if len(state.Branches["recovered"]) != len(state.Branches["main"])+1 {
+		t.Errorf("Recovered branch should keep the commit's ancestry, got %v", state.Branches["recovered"])
+	}
+	(&BranchCommand{}).Execute([]string{"-D", "recovered"}, state)
+
+	gc := &GcCommand{}
+	gc.Execute(nil, state)
+	if !state.hasCommit(evidence) {
+		t.Fatal("gc without --prune=now should keep unreachable objects")
+	}
+	if result = (&CountObjectsCommand{}).Execute([]string{"-v"}, state); !strings.HasPrefix(result.Message, "count: 1\n") {
+		t.Errorf("Only the dangling blob should stay loose, got %q", result.Message)
+	}
+
+	engine.ProcessCommand("git reflog expire --expire=now --all")
+	result = gc.Execute([]string{"--prune=now"}, state)
+	if state.hasCommit(evidence) || !strings.Contains(result.SCPEffect, "1 orphaned containment records and 2 documents") {
+		t.Errorf("gc --prune=now should destroy the evidence once the reflog expired, got %q", result.SCPEffect)
+	}
+	if result = fsck.Execute([]string{"--unreachable"}, state); result.Message != "" {
+		t.Errorf("Nothing should be unreachable after pruning, got %q", result.Message)
+	}
+}
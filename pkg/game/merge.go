@@ -0,0 +1,160 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Merging another branch changes the files that branch changed since the
+// two histories split. A file changed on both sides is merged line by line;
+// when the edits overlap the merge stops with conflict markers in the file,
+// and the player resolves them, stages the file and commits.
+
+// MergeState is a merge stopped on conflicts (MERGE_HEAD), concluded by
+// git commit or abandoned with git merge --abort
+type MergeState struct {
+	Source    string   // the branch being merged, as the player named it
+	Head      string   // its tip
+	Incoming  []string // its commits the current branch lacks
+	Files     []string // files the merge wrote
+	Conflicts []string // files left with conflict markers
+}
+
+// Message is the commit message git suggests for the merge
+func (m *MergeState) Message(branch string) string {
+	return fmt.Sprintf("Merge branch '%s' into %s", m.Source, branch)
+}
+
+// unresolved lists the conflicted files not yet staged
+func (m *MergeState) unresolved(state *GameState) []string {
+	var files []string
+	for _, filename := range m.Conflicts {
+		if _, staged := state.StagingArea[filename]; !staged {
+			files = append(files, filename)
+		}
+	}
+	return files
+}
+
+// mergeBase is the last commit two branch histories share, or "" when they
+// share none
+func mergeBase(ours, theirs []string) string {
+	base := ""
+	for _, id := range ours {
+		if containsString(theirs, id) {
+			base = id
+		}
+	}
+	return base
+}
+
+// treeOf is treeAt that allows an empty history
+func treeOf(state *GameState, id string) map[string]string {
+	if id == "" {
+		return make(map[string]string)
+	}
+	return treeAt(state, id)
+}
+
+// mergeTrees works out the content of every file the merge changes and
+// which of them conflict. theirLabel names the other side in conflict markers.
+func mergeTrees(state *GameState, base, ours, theirs, theirLabel string) (map[string]string, []string) {
+	baseTree, ourTree, theirTree := treeOf(state, base), treeOf(state, ours), treeOf(state, theirs)
+
+	files := make(map[string]string)
+	var conflicts []string
+	for filename, theirHash := range theirTree {
+		baseHash, ourHash := baseTree[filename], ourTree[filename]
+		switch {
+		case theirHash == baseHash || theirHash == ourHash:
+			// Nothing new from their side
+		case ourHash == baseHash:
+			files[filename] = state.Objects[theirHash]
+		default:
+			merged, conflicted := mergeLines(state.Objects[baseHash], state.Objects[ourHash], state.Objects[theirHash], "HEAD", theirLabel)
+			files[filename] = merged
+			if conflicted {
+				conflicts = append(conflicts, filename)
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return files, conflicts
+}
+
+// overwriteRefusal refuses a merge that would replace uncommitted work or
+// sweep it into the merge commit: an index that differs from HEAD, modified
+// tracked files and untracked files in the way. Working files that already
+// match the merged content would not be lost, so they are let through.
+func overwriteRefusal(state *GameState, files map[string]string) (CommandResult, bool) {
+	tree := treeOf(state, headCommitID(state))
+	var local, untracked []string
+	for _, filename := range sortedKeys(state.StagingArea) {
+		if state.StagingArea[filename].Hash != tree[filename] {
+			local = append(local, filename)
+		}
+	}
+	for _, filename := range sortedNames(files) {
+		merged := hashContent(files[filename])
+		file, exists := state.WorkingDir[filename]
+		committed, tracked := tree[filename]
+		switch {
+		case containsString(local, filename):
+			// Already refused for its staged change
+		case exists && tracked && file.Hash != committed && file.Hash != merged:
+			local = append(local, filename)
+		case exists && !tracked && file.Hash != merged:
+			untracked = append(untracked, filename)
+		}
+	}
+
+	switch {
+	case len(local) > 0:
+		sort.Strings(local)
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: Your local changes to the following files would be overwritten by merge:\n\t%s\nPlease commit your changes or stash them before you merge.\nAborting", strings.Join(local, "\n\t")),
+			SCPEffect:    "⚠️  Uncommitted research is in the way - commit or stash it first",
+			AnomalyDelta: 1,
+		}, true
+	case len(untracked) > 0:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: The following untracked working tree files would be overwritten by merge:\n\t%s\nPlease move or remove them before you merge.\nAborting", strings.Join(untracked, "\n\t")),
+			SCPEffect:    "⚠️  Unrecorded files are in the way - move or remove them first",
+			AnomalyDelta: 1,
+		}, true
+	}
+	return CommandResult{}, false
+}
+
+// abortMerge puts the files the merge wrote back the way HEAD has them and
+// forgets the merge
+func abortMerge(state *GameState) CommandResult {
+	if state.Merge == nil {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: There is no merge to abort (MERGE_HEAD missing).",
+			SCPEffect:    "⚠️  No merge in progress",
+			AnomalyDelta: 1,
+		}
+	}
+
+	tree := treeOf(state, headCommitID(state))
+	for _, filename := range state.Merge.Files {
+		if hash, tracked := tree[filename]; tracked {
+			state.WorkingDir[filename] = FileState{Content: state.Objects[hash], Hash: hash}
+		} else {
+			delete(state.WorkingDir, filename)
+		}
+		delete(state.StagingArea, filename)
+	}
+	state.Merge = nil
+
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: "↩️  Merge abandoned - the containment timeline is as it was",
+	}
+}
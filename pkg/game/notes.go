@@ -0,0 +1,267 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NotesCommand implements git notes. Notes annotate commits without
+// changing their IDs, so shared history is never rewritten.
+type NotesCommand struct{}
+
+func (c *NotesCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand, args = args[0], args[1:]
+	}
+
+	switch subcommand {
+	case "add", "append":
+		return c.add(args, state, subcommand == "append")
+	case "show":
+		return c.show(args, state)
+	case "list":
+		return c.list(args, state)
+	case "remove", "rm":
+		return c.remove(args, state)
+	default:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: unknown subcommand: `%s'\nusage: git notes [list | add | append | show | remove] [<object>]", subcommand),
+			SCPEffect:    "🔴 ERROR: Unknown annotation procedure",
+			AnomalyDelta: 1,
+		}
+	}
+}
+
+// noteTarget resolves the commit a notes subcommand applies to
+func noteTarget(state *GameState, positional []string) (string, error) {
+	rev := "HEAD"
+	if len(positional) > 0 {
+		rev = positional[0]
+	}
+	id, err := resolveRevision(state, rev)
+	if err != nil {
+		return "", fmt.Errorf("error: failed to resolve '%s' as a valid ref.", rev)
+	}
+	return id, nil
+}
+
+func (c *NotesCommand) add(args []string, state *GameState, appending bool) CommandResult {
+	force := false
+	var messages []string
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-f", "--force":
+			force = true
+		case "-m", "--message":
+			if i+1 >= len(args) {
+				return CommandResult{
+					Success:      false,
+					Message:      "error: switch `m' requires a value",
+					SCPEffect:    "⚠️  WARNING: Annotation text missing",
+					AnomalyDelta: 1,
+				}
+			}
+			i++
+			messages = append(messages, args[i])
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+
+	id, err := noteTarget(state, positional)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+
+	existing, exists := state.Notes[id]
+	if exists && !force && !appending {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: Cannot add notes. Found existing notes for object %s. Use '-f' to overwrite existing notes", id),
+			SCPEffect:    "⚠️  WARNING: Record already annotated",
+			AnomalyDelta: 1,
+		}
+	}
+
+	save := func(text string, state *GameState) CommandResult {
+		text = cleanupCommitMessage(text)
+		if text == "" {
+			if exists && !appending {
+				delete(state.Notes, id)
+				return CommandResult{
+					Success:   true,
+					Message:   fmt.Sprintf("Removing note for object %s", id),
+					SCPEffect: fmt.Sprintf("📝 Annotation detached from containment record %s", id[:7]),
+				}
+			}
+			return CommandResult{
+				Success:   false,
+				Message:   "Aborting: empty note",
+				SCPEffect: "⚠️  Empty annotation discarded",
+			}
+		}
+		if appending && exists {
+			text = existing + "\n\n" + text
+		}
+		state.Notes[id] = text
+		state.storeObject(text)
+
+		result := CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: fmt.Sprintf("📝 Annotation attached to containment record %s", id[:7]),
+		}
+		if exists && !appending {
+			result.Message = fmt.Sprintf("Overwriting existing notes for object %s", id)
+		}
+		return result
+	}
+
+	if len(messages) > 0 {
+		return save(strings.Join(messages, "\n\n"), state)
+	}
+
+	initial := ""
+	if appending || force {
+		initial = existing
+	}
+	return CommandResult{
+		Success: true,
+		Message: "hint: Waiting for your editor to close the file...",
+		Edit: &EditRequest{
+			Title:   "NOTES_EDITMSG",
+			Initial: initial + "\n\n#\n# Write/edit the notes for the following object:\n# " + id + "\n",
+			Finish:  save,
+		},
+	}
+}
+
+func (c *NotesCommand) show(args []string, state *GameState) CommandResult {
+	id, err := noteTarget(state, args)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+
+	note, exists := state.Notes[id]
+	if !exists {
+		return CommandResult{
+			Success:   false,
+			Message:   fmt.Sprintf("error: no note found for object %s.", id),
+			SCPEffect: "📋 Record carries no annotations",
+		}
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   note,
+		SCPEffect: fmt.Sprintf("📝 Annotation for containment record %s retrieved", id[:7]),
+	}
+}
+
+func (c *NotesCommand) list(args []string, state *GameState) CommandResult {
+	if len(args) > 0 {
+		id, err := noteTarget(state, args)
+		if err != nil {
+			return CommandResult{
+				Success:      false,
+				Message:      err.Error(),
+				SCPEffect:    "🔴 ERROR: Containment record not found",
+				AnomalyDelta: 1,
+			}
+		}
+		note, exists := state.Notes[id]
+		if !exists {
+			return CommandResult{
+				Success:   false,
+				Message:   fmt.Sprintf("error: no note found for object %s.", id),
+				SCPEffect: "📋 Record carries no annotations",
+			}
+		}
+		return CommandResult{
+			Success: true,
+			Message: hashContent(note),
+		}
+	}
+
+	ids := make([]string, 0, len(state.Notes))
+	for id := range state.Notes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var list strings.Builder
+	for _, id := range ids {
+		list.WriteString(fmt.Sprintf("%s %s\n", hashContent(state.Notes[id]), id))
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(list.String(), "\n"),
+		SCPEffect: fmt.Sprintf("📋 %d containment records annotated", len(ids)),
+	}
+}
+
+func (c *NotesCommand) remove(args []string, state *GameState) CommandResult {
+	id, err := noteTarget(state, args)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+	if _, exists := state.Notes[id]; !exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: Object %s has no note", id),
+			SCPEffect:    "📋 Record carries no annotations",
+			AnomalyDelta: 1,
+		}
+	}
+
+	delete(state.Notes, id)
+	return CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Removing note for object %s", id),
+		SCPEffect: fmt.Sprintf("📝 Annotation detached from containment record %s", id[:7]),
+	}
+}
+
+// notesSection renders the "Notes:" block git log and git show print
+func notesSection(state *GameState, id string) string {
+	note, exists := state.Notes[id]
+	if !exists {
+		return ""
+	}
+	return fmt.Sprintf("Notes:\n%s\n\n", indentMessage(note))
+}
+
+func (c *NotesCommand) Help() string {
+	return "Annotate commits without rewriting them"
+}
+
+func (c *NotesCommand) RequiredArgs() int {
+	return 0
+}
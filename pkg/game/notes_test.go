@@ -0,0 +1,82 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommitTrailers(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	state.ConfigName = "Dr. Bright"
+	state.ConfigEmail = "bright@site19.scp"
+
+	state.WorkingDir["incident.txt"] = FileState{Content: "breach", Hash: hashContent("breach")}
+	(&AddCommand{}).Execute([]string{"incident.txt"}, state)
+	result := (&CommitCommand{}).Execute([]string{"-s", "--trailer", "SCP-Clearance: 4", "-m", "Log breach"}, state)
+	if !result.Success {
+		t.Fatalf("commit failed: %s", result.Message)
+	}
+
+	head, _ := state.findCommit(headCommitID(state))
+	want := "Log breach\n\nSCP-Clearance: 4\nSigned-off-by: Dr. Bright <bright@site19.scp>"
+	if head.Message != want {
+		t.Errorf("Unexpected message %q", head.Message)
+	}
+
+	result = (&LogCommand{}).Execute([]string{"--format=%(trailers:key=SCP-Clearance,valueonly)"}, state)
+	if result.Message != "4\n\n" {
+		t.Errorf("Unexpected trailer output %q", result.Message)
+	}
+
+	if got := appendTrailers(head.Message, []Trailer{signoffTrailer(state)}); got != head.Message {
+		t.Errorf("A repeated sign-off should not be added twice, got %q", got)
+	}
+	if trailers := parseTrailers("Subject: not a trailer"); trailers != nil {
+		t.Errorf("The subject line is never a trailer, got %v", trailers)
+	}
+}
+
+func TestNotesCommand(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "incident.txt", "breach", "Log breach")
+	head := headCommitID(state)
+
+	cmd := &NotesCommand{}
+	if result := cmd.Execute([]string{"add", "-m", "Reviewed by O5-7"}, state); !result.Success {
+		t.Fatalf("notes add failed: %s", result.Message)
+	}
+	if headCommitID(state) != head {
+		t.Error("Adding a note must not rewrite the commit")
+	}
+
+	if result := cmd.Execute([]string{"add", "-m", "Second opinion"}, state); result.Success {
+		t.Error("Adding a second note without -f should fail")
+	}
+	cmd.Execute([]string{"append", "-m", "Second opinion"}, state)
+
+	result := cmd.Execute([]string{"show"}, state)
+	if result.Message != "Reviewed by O5-7\n\nSecond opinion" {
+		t.Errorf("Unexpected note %q", result.Message)
+	}
+
+	result = (&LogCommand{}).Execute(nil, state)
+	if !strings.Contains(result.Message, "Notes:\n    Reviewed by O5-7") {
+		t.Errorf("git log should display notes:\n%s", result.Message)
+	}
+
+	result = cmd.Execute([]string{"list"}, state)
+	if !strings.HasSuffix(result.Message, " "+head) {
+		t.Errorf("Unexpected notes list %q", result.Message)
+	}
+
+	cmd.Execute([]string{"remove"}, state)
+	if result = cmd.Execute([]string{"show", "HEAD"}, state); result.Success {
+		t.Error("Note should be gone after remove")
+	}
+}
@@ -0,0 +1,60 @@
+package game
+
+import "fmt"
+
+// A level's goal is an ordered checklist of objectives. The engine checks
+// them after every command, awards each one's points the first time it is
+// met and completes the level once they are all met. An awarded objective
+// stays met, so cleaning up afterwards (deleting a merged branch, say) does
+// not undo progress.
+
+// Objective is one step of a level's goal
+type Objective struct {
+	Description string
+	Hint        string // how to meet it, shown for the next open objective
+	Points      int    // awarded the first time the objective is met
+	Check       func(*GameState) bool
+}
+
+// ObjectiveStatus is an objective's line in the live checklist
+type ObjectiveStatus struct {
+	Objective
+	Met     bool // the objective holds now or was awarded earlier this level
+	Awarded bool // its points have been awarded this level
+}
+
+// Checklist evaluates the level's objectives against the state, in order
+func (l *Level) Checklist(state *GameState) []ObjectiveStatus {
+	checklist := make([]ObjectiveStatus, len(l.Objectives))
+	for i, objective := range l.Objectives {
+		awarded := state.AwardedObjectives[objective.Description]
+		checklist[i] = ObjectiveStatus{
+			Objective: objective,
+			Met:       awarded || objective.Check(state),
+			Awarded:   awarded,
+		}
+	}
+	return checklist
+}
+
+// awardObjectives scores the objectives met for the first time this level
+// and returns their announcements
+func (e *Engine) awardObjectives() []string {
+	if e.CurrentLevel == nil {
+		return nil
+	}
+	if e.State.AwardedObjectives == nil {
+		e.State.AwardedObjectives = make(map[string]bool)
+	}
+
+	var announcements []string
+	for _, status := range e.CurrentLevel.Checklist(e.State) {
+		if !status.Met || status.Awarded {
+			continue
+		}
+		e.State.AwardedObjectives[status.Description] = true
+		e.State.Score += status.Points
+		announcements = append(announcements, fmt.Sprintf("✅ OBJECTIVE MET: %s (+%d)", status.Description, status.Points))
+	}
+	return announcements
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func newObjectiveTestEngine() *Engine {
+	engine := NewEngine()
+	engine.CurrentLevel = &Level{
+		Objectives: []Objective{
+			{Description: "Initialize", Points: 10, Check: func(state *GameState) bool { return state.IsInitialized }},
+			{Description: "Check status", Points: 15, Check: func(state *GameState) bool { return state.UsedCommand("git status") }},
+			{Description: "Stage a file", Points: 20, Check: func(state *GameState) bool { return len(state.StagingArea) > 0 }},
+		},
+		Completion:  "✅ Done",
+		ScoreReward: 100,
+	}
+	return engine
+}
+
+func TestObjectivesScoreOnce(t *testing.T) {
+	engine := newObjectiveTestEngine()
+
+	result := engine.ProcessCommand("git init")
+	if !strings.Contains(result.SCPEffect, "✅ OBJECTIVE MET: Initialize (+10)") || engine.State.Score != 10 {
+		t.Errorf("Meeting an objective should score it, got %q and score %d", result.SCPEffect, engine.State.Score)
+	}
+	if result = engine.ProcessCommand("git status"); strings.Contains(result.SCPEffect, "Initialize") || engine.State.Score != 25 {
+		t.Errorf("An objective should only score once, got %q and score %d", result.SCPEffect, engine.State.Score)
+	}
+
+	checklist := engine.CurrentLevel.Checklist(engine.State)
+	if !checklist[0].Met || !checklist[1].Awarded || checklist[2].Met {
+		t.Errorf("Unexpected checklist %+v", checklist)
+	}
+	if completed, msg := engine.CurrentLevel.Validate(engine.State); completed || msg != "Outstanding objective: Stage a file" {
+		t.Errorf("Validation should name the first open objective, got %q", msg)
+	}
+}
+
+func TestObjectivesCompleteLevel(t *testing.T) {
+	engine := newObjectiveTestEngine()
+	engine.ProcessCommand("git init")
+	engine.ProcessCommand("git status")
+	engine.ProcessCommand("echo notes > notes.txt")
+
+	result := engine.ProcessCommand("git add notes.txt")
+	lines := strings.Split(result.SCPEffect, "\n")
+	if len(lines) != 2 || lines[0] != "✅ OBJECTIVE MET: Stage a file (+20)" || !strings.HasPrefix(lines[1], "🎉 LEVEL COMPLETE! ✅ Done") {
+		t.Errorf("The last objective should be announced with the level, got %q", result.SCPEffect)
+	}
+	if engine.State.Score != 45+100+engine.State.Sanity/4 {
+		t.Errorf("Expected objective points, the reward and the bonus, got %d", engine.State.Score)
+	}
+
+	// Awarded objectives stay met when the work is cleaned up afterwards
+	delete(engine.State.StagingArea, "notes.txt")
+	if !engine.IsLevelComplete() {
+		t.Error("Unstaging should not reopen an awarded objective")
+	}
+	if checklist := engine.CurrentLevel.Checklist(engine.State); !checklist[2].Met || !checklist[2].Awarded {
+		t.Errorf("An awarded objective should stay met, got %+v", checklist[2])
+	}
+}
+
+func TestObjectivesMetByShellBuiltins(t *testing.T) {
+	engine := NewEngine()
+	engine.CurrentLevel = &Level{
+		Objectives: []Objective{
+			{Description: "Write the report", Points: 10, Check: func(state *GameState) bool { _, exists := state.WorkingDir["report.txt"]; return exists }},
+		},
+		Completion: "✅ Written",
+	}
+
+	result := engine.ProcessCommand("touch report.txt")
+	if !strings.Contains(result.SCPEffect, "✅ OBJECTIVE MET: Write the report (+10)") || !strings.Contains(result.SCPEffect, "LEVEL COMPLETE") {
+		t.Errorf("An objective met by a builtin should be announced, got %q", result.SCPEffect)
+	}
+	if !engine.State.AwardedObjectives["Write the report"] {
+		t.Error("An objective met by a builtin should be awarded")
+	}
+}
+
+func TestLevel4Objectives(t *testing.T) {
+	state := newReportTestState(t)
+	state.Branches["strategy-a"] = append([]string{}, state.Branches["main"]...)
+	state.Branches["strategy-b"] = append([]string{}, state.Branches["main"]...)
+	state.CommandHistory = []CommandRecord{
+		{Git: true, Name: "branch", Success: true},
+		{Git: true, Name: "switch", Args: []string{"strategy-a"}, Success: true},
+		{Git: true, Name: "merge", Args: []string{"strategy-a"}, Success: true},
+	}
+
+	if completed, msg := Level4.Validate(state); completed || msg != "Outstanding objective: Merge a successful strategy into main" {
+		t.Errorf("Main needs the merged strategy, got %q", msg)
+	}
+	commitFile(t, state, "strategy_a.txt", "isolated", "Merge strategy-a")
+	if completed, msg := Level4.Validate(state); !completed {
+		t.Errorf("Level 4 should be complete, got %q", msg)
+	}
+}
+
+func TestLevel4KeepsObjectivesAfterCleanup(t *testing.T) {
+	engine := NewEngine()
+	if err := engine.StartLevel(4); err != nil {
+		t.Fatal(err)
+	}
+	for _, command := range []string{
+		"git init",
+		"git add .",
+		"git commit -m 'Baseline'",
+		"git switch -c strategy-a",
+		"git switch -c strategy-b",
+		"git switch main",
+		"git branch -D strategy-b",
+	} {
+		engine.ProcessCommand(command)
+	}
+	if status := engine.CurrentLevel.Checklist(engine.State)[0]; !status.Awarded || !status.Met {
+		t.Errorf("Deleting a branch should not reopen an awarded objective, got %+v", status)
+	}
+}
+
+func TestDetachedHeadIsNotABranch(t *testing.T) {
+	state := newReportTestState(t)
+	state.Branches["strategy-a"] = append([]string{}, state.Branches["main"]...)
+	detachHead(state, state.Branches["main"][0])
+
+	if Level4.Objectives[0].Check(state) {
+		t.Error("A detached HEAD should not count towards three lines of containment")
+	}
+	if branches, _ := CompilePredicate("branches == 2"); !branches(state) {
+		t.Errorf("The branches counter should leave out HEAD, got %d", len(state.Branches))
+	}
+
+	main := strings.Join(state.Branches["main"], " ")
+	commitFile(t, state, "probe.txt", "detached", "Probe")
+	if _, exists := state.Branches[DetachedHead]; exists || strings.Join(state.Branches["main"], " ") != main {
+		t.Error("Commits on a detached HEAD should not touch the branches")
+	}
+	list := (&BranchCommand{}).Execute(nil, state).Message
+	if !strings.HasPrefix(list, "* (HEAD detached at "+headCommitID(state)[:7]+")") {
+		t.Errorf("git branch should list the detached HEAD first, got %q", list)
+	}
+}
@@ -0,0 +1,888 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// patchDateFormat is the RFC 2822 date format used in mail headers
+const patchDateFormat = "Mon, 2 Jan 2006 15:04:05 -0700"
+
+// filePatch is the diff of one file inside a patch
+type filePatch struct {
+	Name    string
+	IsNew   bool
+	OldHash string // blob the patch was made against, if recorded
+	Hunks   []patchHunk
+}
+
+// patchHunk is one "@@" section: the lines it expects and the lines it leaves
+type patchHunk struct {
+	OldStart int
+	OldLines []string
+	NewLines []string
+}
+
+// mailPatch is one message of a format-patch mailbox
+type mailPatch struct {
+	Author  string
+	Subject string
+	Body    string
+	Files   []filePatch
+}
+
+// formatPatchMail renders a commit as a format-patch mail message
+func formatPatchMail(state *GameState, commit Commit, parentTree, tree map[string]string, n, total int) string {
+	var mail strings.Builder
+	mail.WriteString(fmt.Sprintf("From %s Mon Sep 17 00:00:00 2001\n", commit.ID))
+	mail.WriteString(fmt.Sprintf("From: %s\n", commit.Author))
+	mail.WriteString(fmt.Sprintf("Date: %s\n", commit.Timestamp.Format(patchDateFormat)))
+	prefix := "[PATCH]"
+	if total > 1 {
+		prefix = fmt.Sprintf("[PATCH %d/%d]", n, total)
+	}
+	subject, body, _ := strings.Cut(commit.Message, "\n")
+	mail.WriteString(fmt.Sprintf("Subject: %s %s\n\n", prefix, subject))
+	if body = strings.TrimSpace(body); body != "" {
+		mail.WriteString(body + "\n\n")
+	}
+	mail.WriteString("---\n")
+
+	var diffs strings.Builder
+	for _, filename := range sortedTreeNames(tree) {
+		oldHash := parentTree[filename]
+		if oldHash == tree[filename] {
+			continue
+		}
+		mail.WriteString(fmt.Sprintf(" %s | %s\n", filename, lineStats(state.Objects[oldHash], state.Objects[tree[filename]])))
+		diffs.WriteString(patchDiff(filename, oldHash, tree[filename], state.Objects[oldHash], state.Objects[tree[filename]]))
+	}
+	mail.WriteString("\n")
+	mail.WriteString(diffs.String())
+	mail.WriteString("-- \nSCP Foundation Git Anomaly\n")
+	return mail.String()
+}
+
+// patchDiff is a unified diff carrying the blob hashes of both sides, which
+// lets git apply --3way find the original file
+func patchDiff(filename, oldHash, newHash, oldText, newText string) string {
+	diff := unifiedDiff(filename, oldText, newText, oldHash == "")
+	if diff == "" {
+		return ""
+	}
+	if oldHash == "" {
+		oldHash = "00000000"
+	}
+	header, rest, _ := strings.Cut(diff, "\n")
+	return fmt.Sprintf("%s\nindex %s..%s 100644\n%s", header, oldHash, newHash, rest)
+}
+
+// commitParentTree returns the tree of the commit before id in its history
+func commitParentTree(state *GameState, id string) map[string]string {
+	history := commitHistory(state, id)
+	if len(history) < 2 {
+		return map[string]string{}
+	}
+	return treeAt(state, history[len(history)-2])
+}
+
+// patchFileName builds the "0001-subject-words.patch" name git uses
+func patchFileName(n int, message string) string {
+	slug := nonAlnum.ReplaceAllString(commitSubject(message), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > 52 {
+		slug = strings.TrimRight(slug[:52], "-")
+	}
+	return fmt.Sprintf("%04d-%s.patch", n, slug)
+}
+
+var nonAlnum = regexp.MustCompile(`[^A-Za-z0-9.]+`)
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseMailbox splits patch text into messages. Plain diffs without mail
+// headers come back as a single message with no author or subject.
+func parseMailbox(text string) ([]mailPatch, error) {
+	var patches []mailPatch
+	var current *mailPatch
+	var file *filePatch
+	inHeaders, inBody, inDiff := false, false, false
+	var body []string
+
+	finishFile := func() {
+		if file != nil && current != nil {
+			current.Files = append(current.Files, *file)
+		}
+		file = nil
+	}
+	finishMail := func() {
+		finishFile()
+		if current != nil {
+			current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			patches = append(patches, *current)
+		}
+		current, body = nil, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "From ") && strings.HasSuffix(line, "2001"):
+			finishMail()
+			current = &mailPatch{}
+			inHeaders, inBody, inDiff = true, false, false
+
+		case inHeaders:
+			switch {
+			case line == "":
+				inHeaders, inBody = false, true
+			case strings.HasPrefix(line, "From: "):
+				current.Author = strings.TrimPrefix(line, "From: ")
+			case strings.HasPrefix(line, "Subject: "):
+				subject := strings.TrimPrefix(line, "Subject: ")
+				if strings.HasPrefix(subject, "[") {
+					if end := strings.Index(subject, "]"); end >= 0 {
+						subject = strings.TrimSpace(subject[end+1:])
+					}
+				}
+				current.Subject = subject
+			}
+
+		case strings.HasPrefix(line, "diff --git "):
+			if current == nil {
+				current = &mailPatch{}
+			}
+			finishFile()
+			inBody, inDiff = false, true
+			fields := strings.Fields(line)
+			file = &filePatch{Name: strings.TrimPrefix(fields[len(fields)-1], "b/")}
+
+		case inBody:
+			if line == "---" {
+				inBody = false
+				continue
+			}
+			body = append(body, line)
+
+		case inDiff && file != nil:
+			switch {
+			case strings.HasPrefix(line, "new file mode"):
+				file.IsNew = true
+			case strings.HasPrefix(line, "index "):
+				hashes := strings.Fields(line)[1]
+				file.OldHash, _, _ = strings.Cut(hashes, "..")
+				if strings.Trim(file.OldHash, "0") == "" {
+					file.OldHash = ""
+				}
+			case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+				// File names are taken from the diff --git line
+			case strings.HasPrefix(line, "@@"):
+				m := hunkHeaderPattern.FindStringSubmatch(line)
+				if m == nil {
+					return nil, fmt.Errorf("error: corrupt patch at line %d", i+1)
+				}
+				start, _ := strconv.Atoi(m[1])
+				oldCount, newCount := hunkCount(m[2]), hunkCount(m[4])
+				hunk := patchHunk{OldStart: start}
+				for (len(hunk.OldLines) < oldCount || len(hunk.NewLines) < newCount) && i+1 < len(lines) {
+					i++
+					line := lines[i]
+					if line == "" {
+						// Editors strip the space from empty context lines
+						line = " "
+					}
+					text := line[1:]
+					switch line[0] {
+					case ' ':
+						hunk.OldLines = append(hunk.OldLines, text)
+						hunk.NewLines = append(hunk.NewLines, text)
+					case '-':
+						hunk.OldLines = append(hunk.OldLines, text)
+					case '+':
+						hunk.NewLines = append(hunk.NewLines, text)
+					case '\\':
+						// "\ No newline at end of file"
+					default:
+						return nil, fmt.Errorf("error: corrupt patch at line %d", i+1)
+					}
+				}
+				file.Hunks = append(file.Hunks, hunk)
+			case line == "-- ":
+				inDiff = false
+			}
+		}
+	}
+	finishMail()
+
+	var found []mailPatch
+	for _, p := range patches {
+		if len(p.Files) > 0 || p.Subject != "" {
+			found = append(found, p)
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("error: No valid patches in input")
+	}
+	return found, nil
+}
+
+// hunkCount reads the optional line count of a hunk header, which defaults to one
+func hunkCount(field string) int {
+	if field == "" {
+		return 1
+	}
+	count, _ := strconv.Atoi(field)
+	return count
+}
+
+// applyFilePatch applies the hunks of one file to its current content. Hunks
+// are looked for at their recorded line first, then anywhere after the
+// previous hunk, the way git tolerates offsets.
+func applyFilePatch(content string, fp filePatch, trailingNewline bool) (string, error) {
+	lines := splitLines(content)
+	var result []string
+	pos := 0
+	for _, hunk := range fp.Hunks {
+		at := -1
+		if want := hunk.OldStart - 1; want >= pos && linesMatch(lines, want, hunk.OldLines) {
+			at = want
+		} else if len(hunk.OldLines) == 0 && hunk.OldStart == 0 {
+			at = 0
+		} else {
+			for i := pos; i+len(hunk.OldLines) <= len(lines); i++ {
+				if linesMatch(lines, i, hunk.OldLines) {
+					at = i
+					break
+				}
+			}
+		}
+		if at < 0 {
+			return "", fmt.Errorf("error: patch failed: %s:%d", fp.Name, hunk.OldStart)
+		}
+		result = append(result, lines[pos:at]...)
+		result = append(result, hunk.NewLines...)
+		pos = at + len(hunk.OldLines)
+	}
+	result = append(result, lines[pos:]...)
+
+	text := strings.Join(result, "\n")
+	if trailingNewline && len(result) > 0 {
+		text += "\n"
+	}
+	return text, nil
+}
+
+// linesMatch reports whether want appears in lines starting at index at
+func linesMatch(lines []string, at int, want []string) bool {
+	if at < 0 || at+len(want) > len(lines) {
+		return false
+	}
+	for i, line := range want {
+		if lines[at+i] != line {
+			return false
+		}
+	}
+	return true
+}
+
+// patchOutcome is the result of applying one file of a patch
+type patchOutcome struct {
+	Name       string
+	Content    string
+	Conflicted bool
+	ThreeWay   bool
+}
+
+// applyPatchFiles applies every file of a patch against the given versions.
+// Nothing is changed on failure. With threeWay, a hunk that no longer fits
+// falls back to merging against the blob the patch was made from.
+func applyPatchFiles(state *GameState, files []filePatch, current func(string) (string, bool), threeWay bool) ([]patchOutcome, error) {
+	var outcomes []patchOutcome
+	for _, fp := range files {
+		content, exists := current(fp.Name)
+		if fp.IsNew && exists {
+			return nil, fmt.Errorf("error: %s: already exists in working directory", fp.Name)
+		}
+		if !fp.IsNew && !exists {
+			return nil, fmt.Errorf("error: %s: does not exist in index", fp.Name)
+		}
+
+		trailing := strings.HasSuffix(content, "\n")
+		patched, err := applyFilePatch(content, fp, trailing)
+		if err == nil {
+			outcomes = append(outcomes, patchOutcome{Name: fp.Name, Content: patched})
+			continue
+		}
+
+		preimage, stored := state.Objects[fp.OldHash]
+		if !threeWay || !stored {
+			if threeWay {
+				err = fmt.Errorf("%v\nerror: repository lacks the necessary blob to perform 3-way merge.", err)
+			}
+			return nil, fmt.Errorf("%v\nerror: %s: patch does not apply", err, fp.Name)
+		}
+		postimage, err := applyFilePatch(preimage, fp, strings.HasSuffix(preimage, "\n"))
+		if err != nil {
+			return nil, fmt.Errorf("%v\nerror: %s: patch does not apply", err, fp.Name)
+		}
+		merged, conflicted := mergeLines(preimage, content, postimage, "ours", "theirs")
+		outcomes = append(outcomes, patchOutcome{Name: fp.Name, Content: merged, Conflicted: conflicted, ThreeWay: true})
+	}
+	return outcomes, nil
+}
+
+// readPatchFiles collects the named patch files from the working directory
+func readPatchFiles(state *GameState, names []string) (string, error) {
+	var text strings.Builder
+	for _, name := range names {
+		file, exists := state.WorkingDir[name]
+		if !exists {
+			return "", fmt.Errorf("error: can't open patch '%s': No such file or directory", name)
+		}
+		text.WriteString(file.Content)
+		if !strings.HasSuffix(file.Content, "\n") {
+			text.WriteString("\n")
+		}
+	}
+	return text.String(), nil
+}
+
+// FormatPatchCommand implements git format-patch
+type FormatPatchCommand struct{}
+
+func (c *FormatPatchCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	count := 0
+	toStdout := false
+	outputDir := ""
+	var revs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--stdout":
+			toStdout = true
+		case arg == "-o" || arg == "--output-directory":
+			if i+1 < len(args) {
+				i++
+				outputDir = strings.TrimSuffix(args[i], "/") + "/"
+			}
+		case len(arg) > 1 && arg[0] == '-' && arg[1] >= '0' && arg[1] <= '9':
+			count, _ = strconv.Atoi(arg[1:])
+		default:
+			revs = append(revs, arg)
+		}
+	}
+
+	ids, err := patchSeries(state, count, revs)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+	if len(ids) == 0 {
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: "📋 No containment records in that range to export",
+		}
+	}
+
+	var output strings.Builder
+	for n, id := range ids {
+		commit, _ := state.findCommit(id)
+		mail := formatPatchMail(state, commit, commitParentTree(state, id), treeAt(state, id), n+1, len(ids))
+		if toStdout {
+			output.WriteString(mail)
+			continue
+		}
+		name := outputDir + patchFileName(n+1, commit.Message)
+		state.WorkingDir[name] = FileState{Content: mail, Hash: hashContent(mail)}
+		output.WriteString(name + "\n")
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(output.String(), "\n"),
+		SCPEffect: fmt.Sprintf("📤 %d containment records packaged for courier transfer", len(ids)),
+	}
+}
+
+// patchSeries picks the commits to export: the last count commits of a
+// revision, an A..B range, or everything since a single revision
+func patchSeries(state *GameState, count int, revs []string) ([]string, error) {
+	if len(revs) > 1 {
+		return nil, fmt.Errorf("fatal: only one revision range is supported")
+	}
+
+	if count > 0 {
+		rev := "HEAD"
+		if len(revs) == 1 {
+			rev = revs[0]
+		}
+		tip, err := resolveRevision(state, rev)
+		if err != nil {
+			return nil, err
+		}
+		history := commitHistory(state, tip)
+		if count > len(history) {
+			count = len(history)
+		}
+		return history[len(history)-count:], nil
+	}
+
+	if len(revs) == 0 {
+		return nil, fmt.Errorf("fatal: specify a commit count (-1) or a revision range")
+	}
+
+	since, until, isRange := strings.Cut(revs[0], "..")
+	if !isRange {
+		until = "HEAD"
+	}
+	if until == "" {
+		until = "HEAD"
+	}
+	sinceID, err := resolveRevision(state, since)
+	if err != nil {
+		return nil, err
+	}
+	untilID, err := resolveRevision(state, until)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := commitHistory(state, sinceID)
+	var ids []string
+	for _, id := range commitHistory(state, untilID) {
+		if !containsString(excluded, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (c *FormatPatchCommand) Help() string {
+	return "Export commits as mailbox patch files"
+}
+
+func (c *FormatPatchCommand) RequiredArgs() int {
+	return 0
+}
+
+// ApplyCommand implements git apply
+type ApplyCommand struct{}
+
+func (c *ApplyCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	check, threeWay, cached, index := false, false, false, false
+	var names []string
+	for _, arg := range args {
+		switch arg {
+		case "--check":
+			check = true
+		case "--3way", "-3":
+			threeWay = true
+		case "--cached":
+			cached = true
+		case "--index":
+			index = true
+		default:
+			names = append(names, arg)
+		}
+	}
+	if len(names) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git apply [--check] [--3way] [--cached] <patch>...",
+			SCPEffect: "⚠️  WARNING: Specify the patch file to apply",
+		}
+	}
+
+	text, err := readPatchFiles(state, names)
+	if err == nil {
+		var patches []mailPatch
+		if patches, err = parseMailbox(text); err == nil {
+			var files []filePatch
+			for _, p := range patches {
+				files = append(files, p.Files...)
+			}
+			return c.apply(state, files, check, threeWay, cached, index)
+		}
+	}
+	return CommandResult{
+		Success:      false,
+		Message:      err.Error(),
+		SCPEffect:    "🔴 ERROR: Courier package unreadable",
+		AnomalyDelta: 1,
+	}
+}
+
+func (c *ApplyCommand) apply(state *GameState, files []filePatch, check, threeWay, cached, index bool) CommandResult {
+	// --cached works on the index alone; otherwise the working tree is patched
+	current := func(name string) (string, bool) {
+		if cached {
+			content, _, tracked := indexVersion(state, name)
+			return content, tracked
+		}
+		file, exists := state.WorkingDir[name]
+		return file.Content, exists
+	}
+
+	outcomes, err := applyPatchFiles(state, files, current, threeWay)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Courier patch does not fit this containment site",
+			AnomalyDelta: 2,
+		}
+	}
+	if check {
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: fmt.Sprintf("✓ Courier patch verified: %d files would apply cleanly", len(outcomes)),
+		}
+	}
+
+	var message strings.Builder
+	conflicts := 0
+	for _, outcome := range outcomes {
+		hash := state.storeObject(outcome.Content)
+		if !cached {
+			state.WorkingDir[outcome.Name] = FileState{Content: outcome.Content, Hash: hash}
+		}
+		// --3way records clean results in the index, like --index does
+		if (cached || index || outcome.ThreeWay) && !outcome.Conflicted {
+			state.StagingArea[outcome.Name] = FileState{Content: outcome.Content, Hash: hash, Staged: true}
+		}
+		switch {
+		case outcome.Conflicted:
+			conflicts++
+			message.WriteString(fmt.Sprintf("Applied patch to '%s' with conflicts.\nU %s\n", outcome.Name, outcome.Name))
+		case outcome.ThreeWay:
+			message.WriteString(fmt.Sprintf("Applied patch to '%s' cleanly.\n", outcome.Name))
+		}
+	}
+
+	if conflicts > 0 {
+		return CommandResult{
+			Success:      false,
+			Message:      strings.TrimRight(message.String(), "\n"),
+			SCPEffect:    fmt.Sprintf("⚠️  %d files need manual reconciliation - resolve the markers, then stage them", conflicts),
+			AnomalyDelta: 1,
+		}
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: fmt.Sprintf("✅ Courier patch applied to %d files", len(outcomes)),
+	}
+}
+
+func (c *ApplyCommand) Help() string {
+	return "Apply a patch to the working directory or index"
+}
+
+func (c *ApplyCommand) RequiredArgs() int {
+	return 1
+}
+
+// AmSession is a git am stopped on a patch (.git/rebase-apply), resumed with
+// --continue or --skip or abandoned with --abort
+type AmSession struct {
+	Patches   []mailPatch
+	Current   int // index of the patch that stopped
+	ThreeWay  bool
+	Branch    string   // branch the patches are applied to
+	History   []string // its commits before am started
+	Files     []string // files am has written
+	Conflicts []string // files left with conflict markers
+}
+
+// wrote records that am changed a file in the working tree
+func (s *AmSession) wrote(filename string) {
+	if !containsString(s.Files, filename) {
+		s.Files = append(s.Files, filename)
+	}
+}
+
+// restore puts the files am wrote among filenames back the way HEAD has them
+func (s *AmSession) restore(state *GameState, filenames []string) {
+	tree := treeOf(state, headCommitID(state))
+	for _, filename := range filenames {
+		if !containsString(s.Files, filename) {
+			continue
+		}
+		if hash, tracked := tree[filename]; tracked {
+			state.WorkingDir[filename] = FileState{Content: state.Objects[hash], Hash: hash}
+		} else {
+			delete(state.WorkingDir, filename)
+		}
+	}
+	state.StagingArea = make(map[string]FileState)
+}
+
+// AmCommand implements git am
+type AmCommand struct{}
+
+func (c *AmCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	threeWay := false
+	var names []string
+	for _, arg := range args {
+		switch arg {
+		case "--3way", "-3":
+			threeWay = true
+		case "--continue", "--skip", "--abort":
+			return c.resume(state, arg)
+		default:
+			names = append(names, arg)
+		}
+	}
+	if len(names) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git am [--3way] <mbox>...\n   or: git am (--continue | --skip | --abort)",
+			SCPEffect: "⚠️  WARNING: Specify the courier mailbox to apply",
+		}
+	}
+	if state.Am != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      "error: previous rebase directory .git/rebase-apply still exists but mbox given.",
+			SCPEffect:    "⚠️  WARNING: A courier delivery is already half-integrated - continue, skip or abort it first",
+			AnomalyDelta: 1,
+		}
+	}
+	if len(state.StagingArea) > 0 {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: Dirty index: cannot apply patches (dirty: %s)", strings.Join(sortedKeys(state.StagingArea), " ")),
+			SCPEffect:    "⚠️  WARNING: Commit or unstage pending work before accepting courier records",
+			AnomalyDelta: 1,
+		}
+	}
+
+	text, err := readPatchFiles(state, names)
+	var patches []mailPatch
+	if err == nil {
+		patches, err = parseMailbox(text)
+	}
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Courier package unreadable",
+			AnomalyDelta: 1,
+		}
+	}
+
+	session := &AmSession{
+		Patches:  patches,
+		ThreeWay: threeWay,
+		Branch:   state.CurrentBranch,
+		History:  append([]string{}, currentHistory(state)...),
+	}
+	return c.run(state, session, &strings.Builder{})
+}
+
+// run applies the session's patches from the current one on, committing
+// each. A patch that does not apply stops the run and leaves the session in
+// state for the player to resolve.
+func (c *AmCommand) run(state *GameState, session *AmSession, message *strings.Builder) CommandResult {
+	for ; session.Current < len(session.Patches); session.Current++ {
+		patch := session.Patches[session.Current]
+		message.WriteString(fmt.Sprintf("Applying: %s\n", patch.Subject))
+
+		// Patching would lose changes made to the files since they were staged
+		var err error
+		for _, fp := range patch.Files {
+			file, exists := state.WorkingDir[fp.Name]
+			if _, hash, tracked := indexVersion(state, fp.Name); tracked && (!exists || file.Hash != hash) {
+				err = fmt.Errorf("error: %s: does not match index", fp.Name)
+				break
+			}
+		}
+
+		var outcomes []patchOutcome
+		if err == nil {
+			current := func(name string) (string, bool) {
+				content, _, tracked := indexVersion(state, name)
+				return content, tracked
+			}
+			outcomes, err = applyPatchFiles(state, patch.Files, current, session.ThreeWay)
+		}
+		session.Conflicts = nil
+		for _, outcome := range outcomes {
+			if outcome.Conflicted {
+				session.Conflicts = append(session.Conflicts, outcome.Name)
+			}
+		}
+		if err != nil || len(session.Conflicts) > 0 {
+			if err != nil {
+				message.WriteString(err.Error() + "\n")
+			} else {
+				// Leave the markers in the working tree for the player to resolve
+				for _, outcome := range outcomes {
+					hash := state.storeObject(outcome.Content)
+					state.WorkingDir[outcome.Name] = FileState{Content: outcome.Content, Hash: hash}
+					session.wrote(outcome.Name)
+					if !outcome.Conflicted {
+						state.StagingArea[outcome.Name] = FileState{Content: outcome.Content, Hash: hash, Staged: true}
+					}
+				}
+				message.WriteString("error: Failed to merge in the changes.\n")
+			}
+			message.WriteString(fmt.Sprintf("Patch failed at %04d %s\n", session.Current+1, patch.Subject))
+			message.WriteString("hint: When you have resolved this problem, run \"git am --continue\".\n")
+			message.WriteString("hint: If you prefer to skip this patch, run \"git am --skip\" instead.\n")
+			message.WriteString("hint: To restore the original branch and stop patching, run \"git am --abort\".")
+			state.Am = session
+			return CommandResult{
+				Success:      false,
+				Message:      message.String(),
+				SCPEffect:    fmt.Sprintf("🔴 Courier record %d of %d rejected - %d applied before it", session.Current+1, len(session.Patches), session.Current),
+				AnomalyDelta: 2,
+			}
+		}
+
+		for _, outcome := range outcomes {
+			hash := state.storeObject(outcome.Content)
+			state.WorkingDir[outcome.Name] = FileState{Content: outcome.Content, Hash: hash}
+			state.StagingArea[outcome.Name] = FileState{Content: outcome.Content, Hash: hash, Staged: true}
+			session.wrote(outcome.Name)
+		}
+		if result := commitPatch(state, patch); !result.Success {
+			state.Am = session
+			return result
+		}
+	}
+
+	state.Am = nil
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: fmt.Sprintf("✅ %d courier records integrated into the containment timeline", len(session.Patches)),
+	}
+}
+
+// resume carries on with the stopped session: --continue commits the
+// resolved patch, --skip drops it and --abort puts the branch back
+func (c *AmCommand) resume(state *GameState, flag string) CommandResult {
+	session := state.Am
+	if session == nil {
+		return CommandResult{
+			Success:      false,
+			Message:      "error: Resolve operation not in progress, we are not resuming.",
+			SCPEffect:    "⚠️  No courier delivery in progress",
+			AnomalyDelta: 1,
+		}
+	}
+	patch := session.Patches[session.Current]
+
+	switch flag {
+	case "--abort":
+		state.CurrentBranch = session.Branch
+		setCurrentHistory(state, session.History)
+		session.restore(state, session.Files)
+		state.Am = nil
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: "↩️  Courier delivery abandoned - the containment timeline is as it was",
+		}
+
+	case "--skip":
+		var filenames []string
+		for _, fp := range patch.Files {
+			filenames = append(filenames, fp.Name)
+		}
+		session.restore(state, filenames)
+
+	default:
+		var unresolved []string
+		for _, filename := range session.Conflicts {
+			if _, staged := state.StagingArea[filename]; !staged {
+				unresolved = append(unresolved, filename)
+			}
+		}
+		if len(unresolved) > 0 {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("Applying: %s\nYou still have unmerged paths in your index.\nYou should 'git add' each file with resolved conflicts to mark them as such.\nUnmerged paths: %s", patch.Subject, strings.Join(unresolved, ", ")),
+				SCPEffect:    "⚠️  Reconcile the marked records and stage them before continuing",
+				AnomalyDelta: 1,
+			}
+		}
+		if len(state.StagingArea) == 0 {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("Applying: %s\nNo changes - did you forget to use 'git add'?\nIf there is nothing left to stage, chances are that something else\nalready introduced the same changes; you might want to skip this patch.", patch.Subject),
+				SCPEffect:    "⚠️  Nothing staged for this courier record",
+				AnomalyDelta: 1,
+			}
+		}
+		if result := commitPatch(state, patch); !result.Success {
+			return result
+		}
+	}
+
+	session.Current++
+	var message strings.Builder
+	if flag == "--continue" {
+		message.WriteString(fmt.Sprintf("Applying: %s\n", patch.Subject))
+	}
+	return c.run(state, session, &message)
+}
+
+// commitPatch commits the staged patch with its message, keeping the author
+// of the original patch
+func commitPatch(state *GameState, patch mailPatch) CommandResult {
+	commitMessage := patch.Subject
+	if patch.Body != "" {
+		commitMessage += "\n\n" + patch.Body
+	}
+	result := createCommit(state, commitMessage, false)
+	if result.Success && patch.Author != "" {
+		state.Commits[len(state.Commits)-1].Author = patch.Author
+	}
+	return result
+}
+
+func (c *AmCommand) Help() string {
+	return "Apply mailbox patches as commits"
+}
+
+func (c *AmCommand) RequiredArgs() int {
+	return 1
+}
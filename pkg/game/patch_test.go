@@ -0,0 +1,187 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatPatchAndAm(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "protocol.txt", "Step 1\nStep 2\nStep 3", "Base protocol")
+	(&BranchCommand{}).Execute([]string{"isolated"}, state)
+
+	state.ConfigName = "Dr. Clef"
+	commitFile(t, state, "protocol.txt", "Step 1\nStep 2 (revised)\nStep 3", "Revise step 2\n\nApproved by O5.")
+
+	result := (&FormatPatchCommand{}).Execute([]string{"-1"}, state)
+	if result.Message != "0001-Revise-step-2.patch" {
+		t.Fatalf("Unexpected patch file name %q", result.Message)
+	}
+	mail := state.WorkingDir["0001-Revise-step-2.patch"].Content
+	for _, want := range []string{"From: Dr. Clef", "Subject: [PATCH] Revise step 2", "Approved by O5.", "-Step 2\n+Step 2 (revised)"} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("Patch should contain %q:\n%s", want, mail)
+		}
+	}
+
+	state.ConfigName = "Dr. Bright"
+	(&SwitchCommand{}).Execute([]string{"isolated"}, state)
+	state.WorkingDir = checkoutTree(state, headCommitID(state))
+	state.WorkingDir["fix.patch"] = FileState{Content: mail, Hash: hashContent(mail)}
+
+	if result = (&ApplyCommand{}).Execute([]string{"--check", "fix.patch"}, state); !result.Success {
+		t.Fatalf("Patch should apply cleanly: %s", result.Message)
+	}
+	if result = (&AmCommand{}).Execute([]string{"fix.patch"}, state); !result.Success {
+		t.Fatalf("am failed: %s", result.Message)
+	}
+
+	head, _ := state.findCommit(headCommitID(state))
+	if head.Message != "Revise step 2\n\nApproved by O5." || head.Author != "Dr. Clef" {
+		t.Errorf("am should keep the message and author, got %q by %q", head.Message, head.Author)
+	}
+	if state.WorkingDir["protocol.txt"].Content != "Step 1\nStep 2 (revised)\nStep 3" {
+		t.Errorf("Unexpected patched content %q", state.WorkingDir["protocol.txt"].Content)
+	}
+}
+
+func TestApplyThreeWay(t *testing.T) {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "log.txt", "a\nb\nc", "Base")
+	commitFile(t, state, "log.txt", "a\nB\nc", "Upper-case b")
+	(&FormatPatchCommand{}).Execute([]string{"-1"}, state)
+	patch := state.WorkingDir["0001-Upper-case-b.patch"]
+
+	// A different edit of the same line makes the patch stale
+	state.Branches["site-2"] = state.Branches["main"][:1]
+	(&SwitchCommand{}).Execute([]string{"site-2"}, state)
+	state.WorkingDir["log.txt"] = FileState{Content: "a\nbee\nc", Hash: hashContent("a\nbee\nc")}
+	state.WorkingDir["fix.patch"] = patch
+
+	result := (&ApplyCommand{}).Execute([]string{"fix.patch"}, state)
+	if result.Success || !strings.Contains(result.Message, "patch does not apply") {
+		t.Fatalf("Stale patch should be rejected, got %q", result.Message)
+	}
+
+	result = (&ApplyCommand{}).Execute([]string{"--3way", "fix.patch"}, state)
+	if !strings.Contains(result.Message, "with conflicts") {
+		t.Errorf("Expected a conflicted 3-way apply, got %q", result.Message)
+	}
+	want := "a\n<<<<<<< ours\nbee\n=======\nB\n>>>>>>> theirs\nc"
+	if got := state.WorkingDir["log.txt"].Content; got != want {
+		t.Errorf("Unexpected merge result %q", got)
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	merged, conflicted := mergeLines("1\n2\n3\n4\n5", "one\n2\n3\n4\n5", "1\n2\n3\n4\nfive", "ours", "theirs")
+	if conflicted || merged != "one\n2\n3\n4\nfive" {
+		t.Errorf("Independent edits should merge cleanly, got %q", merged)
+	}
+}
+
+// newAmConflictState exports two patches from main and checks out site-2,
+// where log.txt has a different edit of the line the first patch changes
+func newAmConflictState(t *testing.T) *GameState {
+	t.Helper()
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	commitFile(t, state, "log.txt", "a\nb\nc", "Base")
+	commitFile(t, state, "log.txt", "a\nB\nc", "Upper-case b")
+	commitFile(t, state, "notes.txt", "Observed", "Add notes")
+	(&FormatPatchCommand{}).Execute([]string{"-2"}, state)
+	first, second := state.WorkingDir["0001-Upper-case-b.patch"], state.WorkingDir["0002-Add-notes.patch"]
+
+	state.Branches["site-2"] = state.Branches["main"][:1]
+	(&SwitchCommand{}).Execute([]string{"site-2"}, state)
+	state.WorkingDir = checkoutTree(state, headCommitID(state))
+	commitFile(t, state, "log.txt", "a\nbee\nc", "Spell out b")
+	state.WorkingDir["0001.patch"] = first
+	state.WorkingDir["0002.patch"] = second
+	return state
+}
+
+func TestAmConflictContinue(t *testing.T) {
+	state := newAmConflictState(t)
+	am := &AmCommand{}
+
+	result := am.Execute([]string{"--3way", "0001.patch", "0002.patch"}, state)
+	if result.Success || !strings.Contains(result.Message, "Patch failed at 0001 Upper-case b") || state.Am == nil {
+		t.Fatalf("The stale patch should stop am, got %q", result.Message)
+	}
+	if status := (&StatusCommand{}).Execute(nil, state); !strings.Contains(status.Message, "You are in the middle of an am session.") {
+		t.Errorf("Status should report the am session, got %q", status.Message)
+	}
+	if result = am.Execute([]string{"--continue"}, state); result.Success {
+		t.Fatal("--continue should refuse while the conflict is unresolved")
+	}
+	if result = am.Execute([]string{"0002.patch"}, state); result.Success {
+		t.Error("A new am cannot start while one is in progress")
+	}
+
+	writeFile(state, "log.txt", "a\nBee\nc")
+	(&AddCommand{}).Execute([]string{"log.txt"}, state)
+	if result = am.Execute([]string{"--continue"}, state); !result.Success {
+		t.Fatalf("--continue should commit the resolution and apply the rest: %s", result.Message)
+	}
+	if state.Am != nil || len(state.StagingArea) != 0 {
+		t.Error("Finishing the patches should end the session")
+	}
+	history := state.Branches["site-2"]
+	resolved, _ := state.findCommit(history[len(history)-2])
+	notes, _ := state.findCommit(history[len(history)-1])
+	if len(history) != 4 || resolved.Message != "Upper-case b" || notes.Message != "Add notes" {
+		t.Fatalf("Both patches should be committed in order, got %v", history)
+	}
+	if tree := treeAt(state, notes.ID); tree["log.txt"] != hashContent("a\nBee\nc") || tree["notes.txt"] != hashContent("Observed") {
+		t.Errorf("The commits should record the resolution and the second patch, got %v", tree)
+	}
+}
+
+func TestAmConflictAbort(t *testing.T) {
+	state := newAmConflictState(t)
+	before := append([]string{}, state.Branches["site-2"]...)
+	am := &AmCommand{}
+
+	am.Execute([]string{"--3way", "0001.patch", "0002.patch"}, state)
+	if result := am.Execute([]string{"--abort"}, state); !result.Success {
+		t.Fatalf("--abort should succeed: %s", result.Message)
+	}
+	if state.Am != nil || strings.Join(state.Branches["site-2"], " ") != strings.Join(before, " ") {
+		t.Errorf("Aborting should restore the original branch, got %v", state.Branches["site-2"])
+	}
+	if state.WorkingDir["log.txt"].Content != "a\nbee\nc" || len(state.StagingArea) != 0 {
+		t.Errorf("Aborting should restore the files, got %q", state.WorkingDir["log.txt"].Content)
+	}
+	if result := am.Execute([]string{"--abort"}, state); result.Success {
+		t.Error("--abort without a session should fail")
+	}
+}
+
+func TestAmRefusesLocalModifications(t *testing.T) {
+	state := newAmConflictState(t)
+	state.Branches["site-2"] = state.Branches["site-2"][:1]
+	state.WorkingDir["log.txt"] = FileState{Content: "a\nb\nc\nunsaved", Hash: hashContent("a\nb\nc\nunsaved")}
+	am := &AmCommand{}
+
+	result := am.Execute([]string{"0001.patch"}, state)
+	if result.Success || !strings.Contains(result.Message, "error: log.txt: does not match index") {
+		t.Fatalf("A modified file should not be patched, got %q", result.Message)
+	}
+	if state.WorkingDir["log.txt"].Content != "a\nb\nc\nunsaved" {
+		t.Error("The local modification should be left alone")
+	}
+
+	am.Execute([]string{"--abort"}, state)
+	if state.WorkingDir["log.txt"].Content != "a\nb\nc\nunsaved" {
+		t.Error("Aborting should not throw away changes am never touched")
+	}
+}
@@ -0,0 +1,208 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Level files express objective checks in a small predicate language.
+// A predicate is a condition on the game state:
+//
+//	initialized                  the repository exists
+//	configured                   user.name and user.email are set
+//	clean                        git status reports nothing to commit
+//	used "git log -p"            a command was run successfully this level
+//	on main                      the current branch
+//	branch strategy-a            a branch exists
+//	tag v1.0                     a tag exists
+//	file notes.txt               a working file exists
+//	file notes.txt contains "x"  a working file contains some text
+//	committed notes.txt          a file is in HEAD's tree
+//	commits >= 3                 a counter compared to a number
+//
+// Counters are commits (optionally of one branch: "commits main >= 4"),
+// branches, tags, staged, awareness, anomaly and sanity; comparisons are
+// ==, !=, <, <=, > and >=. Predicates combine with "not", "and" and "or",
+// "and" binding tighter than "or".
+
+// Predicate is a compiled objective check
+type Predicate func(*GameState) bool
+
+// predicateCounters are the quantities a predicate can compare
+var predicateCounters = map[string]func(state *GameState, arg string) int{
+	"commits": func(state *GameState, branch string) int {
+		if branch != "" {
+			return len(state.Branches[branch])
+		}
+		return len(state.Commits)
+	},
+	"branches":  func(state *GameState, _ string) int { return len(state.Branches) },
+	"tags":      func(state *GameState, _ string) int { return len(state.Tags) },
+	"staged":    func(state *GameState, _ string) int { return len(state.StagingArea) },
+	"awareness": func(state *GameState, _ string) int { return state.Awareness },
+	"anomaly":   func(state *GameState, _ string) int { return state.AnomalyLevel },
+	"sanity":    func(state *GameState, _ string) int { return state.Sanity },
+}
+
+// predicateComparisons are the operators a counter can be compared with
+var predicateComparisons = map[string]func(a, b int) bool{
+	"==": func(a, b int) bool { return a == b },
+	"!=": func(a, b int) bool { return a != b },
+	"<":  func(a, b int) bool { return a < b },
+	"<=": func(a, b int) bool { return a <= b },
+	">":  func(a, b int) bool { return a > b },
+	">=": func(a, b int) bool { return a >= b },
+}
+
+// CompilePredicate compiles a predicate expression
+func CompilePredicate(expr string) (Predicate, error) {
+	words := splitCommandLine(expr)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty predicate")
+	}
+	return compileOr(words)
+}
+
+// compileOr compiles alternatives separated by "or"
+func compileOr(words []string) (Predicate, error) {
+	var alternatives []Predicate
+	for _, part := range splitWords(words, "or") {
+		p, err := compileAnd(part)
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, p)
+	}
+	return func(state *GameState) bool {
+		for _, p := range alternatives {
+			if p(state) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// compileAnd compiles conditions separated by "and"
+func compileAnd(words []string) (Predicate, error) {
+	var conditions []Predicate
+	for _, part := range splitWords(words, "and") {
+		p, err := compileCondition(part)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, p)
+	}
+	return func(state *GameState) bool {
+		for _, p := range conditions {
+			if !p(state) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// splitWords splits words at every occurrence of sep
+func splitWords(words []string, sep string) [][]string {
+	var parts [][]string
+	start := 0
+	for i, word := range words {
+		if word == sep {
+			parts = append(parts, words[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, words[start:])
+}
+
+// compileCondition compiles a single, possibly negated, condition
+func compileCondition(words []string) (Predicate, error) {
+	if len(words) == 0 {
+		return nil, fmt.Errorf("missing condition around \"and\" or \"or\"")
+	}
+	if words[0] == "not" {
+		p, err := compileCondition(words[1:])
+		if err != nil {
+			return nil, err
+		}
+		return func(state *GameState) bool { return !p(state) }, nil
+	}
+
+	name, args := words[0], words[1:]
+	if counter, exists := predicateCounters[name]; exists {
+		return compileComparison(name, counter, args)
+	}
+
+	arity := map[string]int{
+		"initialized": 0, "configured": 0, "clean": 0,
+		"used": 1, "on": 1, "branch": 1, "tag": 1, "file": 1, "committed": 1,
+	}
+	want, known := arity[name]
+	if !known {
+		return nil, fmt.Errorf("unknown condition %q", name)
+	}
+	if name == "file" && len(args) == 3 && args[1] == "contains" {
+		file, text := args[0], args[2]
+		return func(state *GameState) bool {
+			fs, exists := state.WorkingDir[file]
+			return exists && strings.Contains(fs.Content, text)
+		}, nil
+	}
+	if len(args) != want {
+		return nil, fmt.Errorf("%q takes %d argument(s), got %d", name, want, len(args))
+	}
+
+	switch name {
+	case "initialized":
+		return func(state *GameState) bool { return state.IsInitialized }, nil
+	case "configured":
+		return func(state *GameState) bool { return state.ConfigName != "" && state.ConfigEmail != "" }, nil
+	case "clean":
+		return func(state *GameState) bool { return state.IsInitialized && statusClean(state) }, nil
+	case "used":
+		spec := args[0]
+		if len(strings.Fields(spec)) == 0 {
+			return nil, fmt.Errorf("\"used\" needs a command, e.g. used \"git log\"")
+		}
+		return func(state *GameState) bool { return state.UsedCommand(spec) }, nil
+	case "on":
+		return func(state *GameState) bool { return state.CurrentBranch == args[0] }, nil
+	case "branch":
+		return func(state *GameState) bool { _, exists := state.Branches[args[0]]; return exists }, nil
+	case "tag":
+		return func(state *GameState) bool { _, exists := state.Tags[args[0]]; return exists }, nil
+	case "file":
+		return func(state *GameState) bool { _, exists := state.WorkingDir[args[0]]; return exists }, nil
+	default: // committed
+		return func(state *GameState) bool {
+			head := headCommitID(state)
+			if head == "" {
+				return false
+			}
+			_, exists := treeAt(state, head)[args[0]]
+			return exists
+		}, nil
+	}
+}
+
+// compileComparison compiles "counter [arg] op number"
+func compileComparison(name string, counter func(*GameState, string) int, args []string) (Predicate, error) {
+	var arg string
+	if len(args) == 3 && name == "commits" {
+		arg, args = args[0], args[1:]
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%q should be compared to a number, e.g. %s >= 3", name, name)
+	}
+	compare, exists := predicateComparisons[args[0]]
+	if !exists {
+		return nil, fmt.Errorf("unknown comparison %q", args[0])
+	}
+	limit, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", args[1])
+	}
+	return func(state *GameState) bool { return compare(counter(state, arg), limit) }, nil
+}
@@ -0,0 +1,173 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReflogEntry records one movement of HEAD
+type ReflogEntry struct {
+	ID        string
+	Message   string // e.g. "commit: Add notes" or "checkout: moving from main to fix"
+	Timestamp time.Time
+}
+
+// reflogMessage describes why HEAD moved, in git's "<action>: <detail>" form
+func reflogMessage(state *GameState, gitCmd string, args []string, fromBranch, before string) string {
+	switch gitCmd {
+	case "commit":
+		commit, _ := state.findCommit(headCommitID(state))
+		action := "commit"
+		switch {
+		case containsString(args, "--amend"):
+			action = "commit (amend)"
+		case before == "":
+			action = "commit (initial)"
+		}
+		return fmt.Sprintf("%s: %s", action, commitSubject(commit.Message))
+	case "checkout", "switch":
+		return fmt.Sprintf("checkout: moving from %s to %s", fromBranch, state.CurrentBranch)
+	default:
+		return fmt.Sprintf("%s: %s", gitCmd, strings.Join(args, " "))
+	}
+}
+
+// trackReflog records HEAD movements made by a command, including those made
+// once the command's editor or prompt completes
+func trackReflog(result CommandResult, state *GameState, gitCmd string, args []string, fromBranch, before string) CommandResult {
+	if result.Edit != nil {
+		edit := *result.Edit
+		finish := edit.Finish
+		edit.Finish = func(text string, current *GameState) CommandResult {
+			return trackReflog(finish(text, current), state, gitCmd, args, fromBranch, before)
+		}
+		result.Edit = &edit
+		return result
+	}
+	if result.Prompt != nil {
+		prompt := *result.Prompt
+		answer := prompt.Answer
+		prompt.Answer = func(input string, current *GameState) CommandResult {
+			return trackReflog(answer(input, current), state, gitCmd, args, fromBranch, before)
+		}
+		result.Prompt = &prompt
+		return result
+	}
+
+	if head := headCommitID(state); head != "" && (head != before || state.CurrentBranch != fromBranch) {
+		state.Reflog = append(state.Reflog, ReflogEntry{
+			ID:        head,
+			Message:   reflogMessage(state, gitCmd, args, fromBranch, before),
+			Timestamp: time.Now(),
+		})
+	}
+	return result
+}
+
+// reflogCommits returns the commits the reflog still protects from pruning
+func reflogCommits(state *GameState) []string {
+	var ids []string
+	for _, entry := range state.Reflog {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+// ReflogCommand implements git reflog
+type ReflogCommand struct{}
+
+func (c *ReflogCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	if len(args) > 0 && args[0] == "expire" {
+		return c.expire(args[1:], state)
+	}
+	if len(args) > 0 && args[0] != "show" {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: unknown subcommand: `%s'\nusage: git reflog [show | expire]", args[0]),
+			SCPEffect:    "🔴 ERROR: Unknown movement log operation",
+			AnomalyDelta: 1,
+		}
+	}
+
+	var log strings.Builder
+	for i := len(state.Reflog) - 1; i >= 0; i-- {
+		entry := state.Reflog[i]
+		log.WriteString(fmt.Sprintf("%s HEAD@{%d}: %s\n", entry.ID[:7], len(state.Reflog)-1-i, entry.Message))
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(log.String(), "\n"),
+		SCPEffect: fmt.Sprintf("📋 %d movements of the containment HEAD on record", len(state.Reflog)),
+	}
+}
+
+// expire drops reflog entries. Game time is too short for git's 90 and 30
+// day defaults to matter, so only "now" expires anything.
+func (c *ReflogCommand) expire(args []string, state *GameState) CommandResult {
+	all, unreachableOnly := false, false
+	for _, arg := range args {
+		switch {
+		case arg == "--all":
+			all = true
+		case arg == "--expire=now" || arg == "--expire=all":
+			unreachableOnly = false
+		case arg == "--expire-unreachable=now" || arg == "--expire-unreachable=all":
+			unreachableOnly = true
+		default:
+			if strings.HasPrefix(arg, "--expire") {
+				return CommandResult{
+					Success:   true,
+					Message:   "",
+					SCPEffect: "📋 No movement records are old enough to expire",
+				}
+			}
+		}
+	}
+	if !all && !containsString(args, "HEAD") {
+		return CommandResult{
+			Success: false,
+			Message: "error: no reflog specified to delete (use --all or HEAD)",
+		}
+	}
+	if !containsString(args, "--expire=now") && !containsString(args, "--expire=all") && !unreachableOnly {
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: "📋 No movement records are old enough to expire",
+		}
+	}
+
+	reachable := reachableCommits(state, false)
+	var kept []ReflogEntry
+	for _, entry := range state.Reflog {
+		if unreachableOnly && reachable[entry.ID] {
+			kept = append(kept, entry)
+		}
+	}
+	expired := len(state.Reflog) - len(kept)
+	state.Reflog = kept
+
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: fmt.Sprintf("⚠️  %d movement records expired - abandoned work is no longer protected from gc", expired),
+	}
+}
+
+func (c *ReflogCommand) Help() string {
+	return "Show or expire the log of HEAD movements"
+}
+
+func (c *ReflogCommand) RequiredArgs() int {
+	return 0
+}
@@ -0,0 +1,485 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultRemoteURL is the address of the shared Foundation archive
+const DefaultRemoteURL = "foundation://site-19/archive/scp-████.git"
+
+// Remote represents a simulated remote repository shared with other researchers
+type Remote struct {
+	Name     string
+	URL      string
+	Branches map[string][]string // branch -> commit IDs
+	Commits  []Commit            // every commit the remote has received
+}
+
+// NewRemote creates an empty remote repository
+func NewRemote(name, url string) *Remote {
+	return &Remote{
+		Name:     name,
+		URL:      url,
+		Branches: make(map[string][]string),
+		Commits:  []Commit{},
+	}
+}
+
+// hasCommit reports whether the remote already stores the commit
+func (r *Remote) hasCommit(id string) bool {
+	for _, commit := range r.Commits {
+		if commit.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// findCommit returns the remote copy of a commit
+func (r *Remote) findCommit(id string) (Commit, bool) {
+	for _, commit := range r.Commits {
+		if commit.ID == id {
+			return commit, true
+		}
+	}
+	return Commit{}, false
+}
+
+// shortRange formats an "old..new" range the way push and fetch report it
+func shortRange(oldCommits, newCommits []string) string {
+	from := "0000000"
+	if len(oldCommits) > 0 {
+		from = oldCommits[len(oldCommits)-1][:7]
+	}
+	to := "0000000"
+	if len(newCommits) > 0 {
+		to = newCommits[len(newCommits)-1][:7]
+	}
+	return from + ".." + to
+}
+
+// RemoteCommand implements git remote
+type RemoteCommand struct{}
+
+func (c *RemoteCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// List remotes
+	if len(args) == 0 || args[0] == "-v" {
+		verbose := len(args) > 0
+		var names []string
+		for name := range state.Remotes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var list strings.Builder
+		for _, name := range names {
+			if verbose {
+				url := state.Remotes[name].URL
+				list.WriteString(fmt.Sprintf("%s\t%s (fetch)\n%s\t%s (push)\n", name, url, name, url))
+			} else {
+				list.WriteString(name + "\n")
+			}
+		}
+
+		return CommandResult{
+			Success:   true,
+			Message:   list.String(),
+			SCPEffect: "📡 Linked Foundation archives listed",
+		}
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return CommandResult{
+				Success:   false,
+				Message:   "usage: git remote add <name> <url>",
+				SCPEffect: "⚠️  WARNING: Specify archive name and address",
+			}
+		}
+
+		name := args[1]
+		if _, exists := state.Remotes[name]; exists {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: remote %s already exists.", name),
+				SCPEffect:    "⚠️  WARNING: Archive link already established",
+				AnomalyDelta: 1,
+			}
+		}
+
+		state.Remotes[name] = NewRemote(name, args[2])
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: fmt.Sprintf("✅ Secure link to archive '%s' established", name),
+		}
+
+	case "remove", "rm":
+		if len(args) < 2 {
+			return CommandResult{
+				Success:   false,
+				Message:   "usage: git remote remove <name>",
+				SCPEffect: "⚠️  WARNING: Specify archive to unlink",
+			}
+		}
+
+		name := args[1]
+		if _, exists := state.Remotes[name]; !exists {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: No such remote: '%s'", name),
+				SCPEffect:    "🔴 ERROR: Unknown archive",
+				AnomalyDelta: 1,
+			}
+		}
+
+		delete(state.Remotes, name)
+		for ref := range state.RemoteBranches {
+			if strings.HasPrefix(ref, name+"/") {
+				delete(state.RemoteBranches, ref)
+			}
+		}
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: fmt.Sprintf("✅ Archive '%s' unlinked", name),
+		}
+
+	default:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: Unknown subcommand: %s", args[0]),
+			SCPEffect:    "🔴 ERROR: Unknown archive operation",
+			AnomalyDelta: 1,
+		}
+	}
+}
+
+func (c *RemoteCommand) Help() string {
+	return "Manage links to shared Foundation archives"
+}
+
+func (c *RemoteCommand) RequiredArgs() int {
+	return 0
+}
+
+// PushCommand implements git push
+type PushCommand struct{}
+
+func (c *PushCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// Parse flags and positional arguments
+	setUpstream := false
+	force := false
+	noVerify := false
+	var positional []string
+	for _, arg := range args {
+		switch arg {
+		case "-u", "--set-upstream":
+			setUpstream = true
+		case "-f", "--force":
+			force = true
+		case "--no-verify":
+			noVerify = true
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	remoteName := "origin"
+	if len(positional) > 0 {
+		remoteName = positional[0]
+	}
+	branchName := state.CurrentBranch
+	if len(positional) > 1 {
+		branchName = positional[1]
+	}
+
+	remote, exists := state.Remotes[remoteName]
+	if !exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' does not appear to be a git repository", remoteName),
+			SCPEffect:    "🔴 ERROR: No archive link with that designation",
+			AnomalyDelta: 2,
+		}
+	}
+
+	localCommits, exists := state.Branches[branchName]
+	if !exists || len(localCommits) == 0 {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: src refspec %s does not match any", branchName),
+			SCPEffect:    "🔴 ERROR: Nothing to transmit to the archive",
+			AnomalyDelta: 1,
+		}
+	}
+
+	remoteCommits := remote.Branches[branchName]
+	header := fmt.Sprintf("To %s\n", remote.URL)
+
+	// Reject non-fast-forward updates unless forced
+	if !containsAll(localCommits, remoteCommits) && !force {
+		return CommandResult{
+			Success: false,
+			Message: header +
+				fmt.Sprintf(" ! [rejected]        %s -> %s (fetch first)\n", branchName, branchName) +
+				fmt.Sprintf("error: failed to push some refs to '%s'\n", remote.URL) +
+				"hint: Updates were rejected because the remote contains work that you do\n" +
+				"hint: not have locally. Integrate the remote changes (e.g.\n" +
+				"hint: 'git pull ...') before pushing again.",
+			SCPEffect:    "🔴 TRANSMISSION REJECTED: Another researcher has updated the archive",
+			AnomalyDelta: 2,
+		}
+	}
+
+	if len(remoteCommits) == len(localCommits) && containsAll(remoteCommits, localCommits) {
+		return CommandResult{
+			Success:   true,
+			Message:   "Everything up-to-date",
+			SCPEffect: "✓ Archive already holds all containment records",
+		}
+	}
+
+	// The pre-push hook can veto what is about to be transmitted
+	hookOutput, hookOK := runHook(state, "pre-push", pushContext(state, remote, branchName, localCommits))
+	if !hookOK && !noVerify {
+		return CommandResult{
+			Success: false,
+			Message: hookOutput + "\n" +
+				fmt.Sprintf("error: failed to push some refs to '%s'", remote.URL),
+			SCPEffect:    "🛡️  TRANSMISSION BLOCKED: pre-push safeguard vetoed the push (see above)",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// Transfer commits the remote does not have yet
+	for _, id := range localCommits {
+		if remote.hasCommit(id) {
+			continue
+		}
+		if commit, found := state.findCommit(id); found {
+			remote.Commits = append(remote.Commits, commit)
+		}
+	}
+
+	var update string
+	anomalyDelta := 0
+	switch {
+	case len(remoteCommits) == 0:
+		update = fmt.Sprintf(" * [new branch]      %s -> %s", branchName, branchName)
+	case force && !containsAll(localCommits, remoteCommits):
+		update = fmt.Sprintf(" + %s %s -> %s (forced update)", shortRange(remoteCommits, localCommits), branchName, branchName)
+		anomalyDelta = 5
+	default:
+		update = fmt.Sprintf("   %s  %s -> %s", shortRange(remoteCommits, localCommits), branchName, branchName)
+	}
+
+	remote.Branches[branchName] = append([]string{}, localCommits...)
+	state.RemoteBranches[remoteName+"/"+branchName] = append([]string{}, localCommits...)
+
+	message := header + update
+	if setUpstream {
+		state.Upstreams[branchName] = remoteName + "/" + branchName
+		message += fmt.Sprintf("\nbranch '%s' set up to track '%s/%s'.", branchName, remoteName, branchName)
+	}
+
+	effect := fmt.Sprintf("✅ Containment records transmitted to archive '%s'", remoteName)
+	if anomalyDelta > 0 {
+		effect = "⚠️  WARNING: Archive history overwritten - other researchers' work may be lost"
+	}
+
+	if hookOK && hookOutput != "" {
+		message = hookOutput + "\n" + message
+	}
+
+	result := CommandResult{
+		Success:      true,
+		Message:      message,
+		SCPEffect:    effect,
+		AnomalyDelta: anomalyDelta,
+	}
+	if !hookOK {
+		result = noVerifyBypass(result, "pre-push", hookOutput)
+	}
+	return result
+}
+
+func (c *PushCommand) Help() string {
+	return "Transmit local commits to a shared archive"
+}
+
+func (c *PushCommand) RequiredArgs() int {
+	return 0
+}
+
+// FetchCommand implements git fetch
+type FetchCommand struct{}
+
+func (c *FetchCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	remoteName := "origin"
+	if len(args) > 0 {
+		remoteName = args[0]
+	}
+
+	updates, err := fetchRemote(state, remoteName)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: No archive link with that designation",
+			AnomalyDelta: 2,
+		}
+	}
+
+	if len(updates) == 0 {
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: "✓ No new records in the archive",
+		}
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("From %s\n%s", state.Remotes[remoteName].URL, strings.Join(updates, "\n")),
+		SCPEffect: "📡 New containment records retrieved from the archive",
+	}
+}
+
+func (c *FetchCommand) Help() string {
+	return "Download records from a shared archive"
+}
+
+func (c *FetchCommand) RequiredArgs() int {
+	return 0
+}
+
+// fetchRemote copies new commits from a remote and updates its remote-tracking
+// branches, returning one line per updated ref
+func fetchRemote(state *GameState, remoteName string) ([]string, error) {
+	remote, exists := state.Remotes[remoteName]
+	if !exists {
+		return nil, fmt.Errorf("fatal: '%s' does not appear to be a git repository", remoteName)
+	}
+
+	var branches []string
+	for branch := range remote.Branches {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	var updates []string
+	for _, branch := range branches {
+		remoteCommits := remote.Branches[branch]
+		for _, id := range remoteCommits {
+			if state.hasCommit(id) {
+				continue
+			}
+			if commit, found := remote.findCommit(id); found {
+				state.Commits = append(state.Commits, commit)
+			}
+		}
+
+		ref := remoteName + "/" + branch
+		tracked, known := state.RemoteBranches[ref]
+		if known && len(tracked) == len(remoteCommits) && containsAll(tracked, remoteCommits) {
+			continue
+		}
+
+		if known {
+			updates = append(updates, fmt.Sprintf("   %s  %s -> %s", shortRange(tracked, remoteCommits), branch, ref))
+		} else {
+			updates = append(updates, fmt.Sprintf(" * [new branch]      %s -> %s", branch, ref))
+		}
+		state.RemoteBranches[ref] = append([]string{}, remoteCommits...)
+	}
+
+	return updates, nil
+}
+
+// PullCommand implements git pull (fetch followed by merge)
+type PullCommand struct{}
+
+func (c *PullCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	remoteName := "origin"
+	if len(args) > 0 {
+		remoteName = args[0]
+	}
+	branchName := state.CurrentBranch
+	if len(args) > 1 {
+		branchName = args[1]
+	}
+
+	updates, err := fetchRemote(state, remoteName)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: No archive link with that designation",
+			AnomalyDelta: 2,
+		}
+	}
+
+	ref := remoteName + "/" + branchName
+	if _, exists := state.RemoteBranches[ref]; !exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: couldn't find remote ref %s", branchName),
+			SCPEffect:    "🔴 ERROR: Archive has no such containment branch",
+			AnomalyDelta: 1,
+		}
+	}
+
+	result := (&MergeCommand{}).Execute([]string{ref}, state)
+	if len(updates) > 0 {
+		result.Message = fmt.Sprintf("From %s\n%s\n%s", state.Remotes[remoteName].URL, strings.Join(updates, "\n"), result.Message)
+	}
+	return result
+}
+
+func (c *PullCommand) Help() string {
+	return "Fetch from an archive and merge into the current branch"
+}
+
+func (c *PullCommand) RequiredArgs() int {
+	return 0
+}
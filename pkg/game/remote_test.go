@@ -0,0 +1,263 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func newRemoteTestState() *GameState {
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	state.Remotes["origin"] = NewRemote("origin", DefaultRemoteURL)
+	return state
+}
+
+func commitFile(t *testing.T, state *GameState, filename, content, message string) {
+	t.Helper()
+	state.WorkingDir[filename] = FileState{Content: content, Hash: hashContent(content)}
+	if result := (&AddCommand{}).Execute([]string{filename}, state); !result.Success {
+		t.Fatalf("add %s failed: %s", filename, result.Message)
+	}
+	if result := (&CommitCommand{}).Execute([]string{"-m", message}, state); !result.Success {
+		t.Fatalf("commit failed: %s", result.Message)
+	}
+}
+
+func TestPushAndFetch(t *testing.T) {
+	state := newRemoteTestState()
+	commitFile(t, state, "notes.txt", "day 1", "Initial notes")
+
+	push := &PushCommand{}
+	result := push.Execute([]string{"-u", "origin", "main"}, state)
+	if !result.Success {
+		t.Fatalf("Push should succeed on new branch: %s", result.Message)
+	}
+	if len(state.Remotes["origin"].Branches["main"]) != 1 {
+		t.Error("Remote branch should contain the pushed commit")
+	}
+	if state.Upstreams["main"] != "origin/main" {
+		t.Error("Push -u should record the upstream branch")
+	}
+
+	result = push.Execute([]string{}, state)
+	if !strings.Contains(result.Message, "Everything up-to-date") {
+		t.Errorf("Second push should be up to date, got %q", result.Message)
+	}
+
+	result = (&FetchCommand{}).Execute([]string{"nowhere"}, state)
+	if result.Success {
+		t.Error("Fetch from unknown remote should fail")
+	}
+}
+
+func TestPushRejectedAfterActorCommit(t *testing.T) {
+	state := newRemoteTestState()
+	commitFile(t, state, "notes.txt", "day 1", "Initial notes")
+	(&PushCommand{}).Execute([]string{}, state)
+
+	actor := &Actor{
+		Name:    "Dr. Chen",
+		Trigger: ActorTrigger{AfterPush: true},
+		Commits: []ActorCommit{{Message: "Update protocol", Files: map[string]string{"protocol.txt": "seal door"}}},
+	}
+	if msg := actor.act(state); msg == "" {
+		t.Fatal("Actor should push once the remote branch exists")
+	}
+
+	commitFile(t, state, "notes.txt", "day 2", "More notes")
+	result := (&PushCommand{}).Execute([]string{}, state)
+	if result.Success {
+		t.Fatal("Push should be rejected when the remote has unseen commits")
+	}
+	if !strings.Contains(result.Message, "[rejected]") {
+		t.Errorf("Expected rejection message, got %q", result.Message)
+	}
+
+	result = (&PullCommand{}).Execute([]string{}, state)
+	if !result.Success {
+		t.Fatalf("Pull should merge the remote changes: %s", result.Message)
+	}
+	if state.WorkingDir["protocol.txt"].Content != "seal door" {
+		t.Error("Pulled file should appear in the working directory")
+	}
+
+	result = (&PushCommand{}).Execute([]string{}, state)
+	if !result.Success {
+		t.Errorf("Push should succeed after integrating remote work: %s", result.Message)
+	}
+}
+
+func TestMergeFastForward(t *testing.T) {
+	state := newRemoteTestState()
+	commitFile(t, state, "a.txt", "a", "First")
+	(&BranchCommand{}).Execute([]string{"feature"}, state)
+	(&SwitchCommand{}).Execute([]string{"feature"}, state)
+	commitFile(t, state, "b.txt", "b", "Second")
+	(&SwitchCommand{}).Execute([]string{"main"}, state)
+
+	commitsBefore := len(state.Commits)
+	result := (&MergeCommand{}).Execute([]string{"feature"}, state)
+	if !strings.Contains(result.Message, "Fast-forward") {
+		t.Errorf("Expected fast-forward merge, got %q", result.Message)
+	}
+	if len(state.Commits) != commitsBefore {
+		t.Error("Fast-forward merge should not create a merge commit")
+	}
+	if len(state.Branches["main"]) != 2 {
+		t.Errorf("Expected main to have 2 commits, got %d", len(state.Branches["main"]))
+	}
+}
+
+// newDivergedTestState has main and feature each changing a.txt since
+// "Base"; main is checked out with its files in the working directory
+func newDivergedTestState(t *testing.T, ours, theirs string) *GameState {
+	t.Helper()
+	state := newRemoteTestState()
+	commitFile(t, state, "a.txt", "1\n2\n3\n", "Base")
+	(&BranchCommand{}).Execute([]string{"feature"}, state)
+	(&SwitchCommand{}).Execute([]string{"feature"}, state)
+	commitFile(t, state, "a.txt", theirs, "Feature edit")
+	commitFile(t, state, "b.txt", "new", "Feature file")
+	(&SwitchCommand{}).Execute([]string{"main"}, state)
+	state.WorkingDir = checkoutTree(state, headCommitID(state))
+	commitFile(t, state, "a.txt", ours, "Main edit")
+	return state
+}
+
+func TestMergeRefusesToOverwriteLocalChanges(t *testing.T) {
+	state := newDivergedTestState(t, "one\n2\n3\n", "1\n2\nthree\n")
+	cmd := &MergeCommand{}
+
+	writeFile(state, "a.txt", "unsaved research\n")
+	result := cmd.Execute([]string{"feature"}, state)
+	if result.Success || !strings.Contains(result.Message, "Your local changes to the following files would be overwritten by merge:\n\ta.txt") {
+		t.Fatalf("A modified file should block the merge, got %q", result.Message)
+	}
+	if state.WorkingDir["a.txt"].Content != "unsaved research\n" || len(state.Branches["main"]) != 2 {
+		t.Error("A refused merge should leave the work and history alone")
+	}
+
+	writeFile(state, "a.txt", "one\n2\n3\n")
+	writeFile(state, "x.txt", "staged elsewhere")
+	(&AddCommand{}).Execute([]string{"x.txt"}, state)
+	if result = cmd.Execute([]string{"feature"}, state); result.Success || !strings.Contains(result.Message, "would be overwritten by merge:\n\tx.txt") {
+		t.Errorf("Staged work should block the merge rather than join the merge commit, got %q", result.Message)
+	}
+	delete(state.StagingArea, "x.txt")
+	delete(state.WorkingDir, "x.txt")
+
+	writeFile(state, "a.txt", "one\n2\n3\n")
+	writeFile(state, "b.txt", "mine")
+	if result = cmd.Execute([]string{"feature"}, state); result.Success || !strings.Contains(result.Message, "untracked working tree files would be overwritten by merge:\n\tb.txt") {
+		t.Errorf("An untracked file in the way should block the merge, got %q", result.Message)
+	}
+
+	delete(state.WorkingDir, "b.txt")
+	if result = cmd.Execute([]string{"feature"}, state); !result.Success || state.WorkingDir["a.txt"].Content != "one\n2\nthree\n" {
+		t.Fatalf("Edits to different lines should merge cleanly, got %q", result.Message)
+	}
+	head, _ := state.findCommit(headCommitID(state))
+	if len(state.CommitGraph[head.ID]) != 2 || treeAt(state, head.ID)["a.txt"] != hashContent("one\n2\nthree\n") {
+		t.Errorf("The merge commit should record the merged file, got %+v", head)
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	state := newDivergedTestState(t, "one\n2\n3\n", "uno\n2\n3\n")
+	feature := state.Branches["feature"]
+
+	result := (&MergeCommand{}).Execute([]string{"feature"}, state)
+	if result.Success || !strings.Contains(result.Message, "CONFLICT (content): Merge conflict in a.txt") {
+		t.Fatalf("Edits to the same line should conflict, got %q", result.Message)
+	}
+	if want := "<<<<<<< HEAD\none\n=======\nuno\n>>>>>>> feature\n2\n3\n"; state.WorkingDir["a.txt"].Content != want {
+		t.Errorf("The conflict should be written with markers, got %q", state.WorkingDir["a.txt"].Content)
+	}
+	if _, staged := state.StagingArea["b.txt"]; !staged {
+		t.Error("Files that merged cleanly should be staged")
+	}
+	if status := (&StatusCommand{}).Execute(nil, state); !strings.Contains(status.Message, "both modified:   a.txt") {
+		t.Errorf("Status should list the unmerged file, got %q", status.Message)
+	}
+	if result = (&CommitCommand{}).Execute([]string{"-m", "Merge"}, state); result.Success {
+		t.Fatal("Committing with unresolved conflicts should fail")
+	}
+	if result = (&MergeCommand{}).Execute([]string{"feature"}, state); result.Success {
+		t.Error("A second merge cannot start while one is in progress")
+	}
+
+	commitFile(t, state, "a.txt", "one and uno\n2\n3\n", "Merge branch 'feature' into main")
+	if state.Merge != nil {
+		t.Fatal("Committing the resolution should conclude the merge")
+	}
+	head := headCommitID(state)
+	if parents := state.CommitGraph[head]; len(parents) != 2 || parents[1] != feature[len(feature)-1] {
+		t.Errorf("The merge commit should have both tips as parents, got %v", parents)
+	}
+	tree := treeAt(state, head)
+	if tree["a.txt"] != hashContent("one and uno\n2\n3\n") || tree["b.txt"] != hashContent("new") {
+		t.Errorf("The merge commit should record the resolution, got %v", tree)
+	}
+	if !containsAll(state.Branches["main"], feature) {
+		t.Error("The feature commits should be part of main's history")
+	}
+}
+
+func TestMergeAbort(t *testing.T) {
+	state := newDivergedTestState(t, "one\n2\n3\n", "uno\n2\n3\n")
+	(&MergeCommand{}).Execute([]string{"feature"}, state)
+	writeFile(state, "x.txt", "unrelated")
+	(&AddCommand{}).Execute([]string{"x.txt"}, state)
+
+	if result := (&MergeCommand{}).Execute([]string{"--abort"}, state); !result.Success {
+		t.Fatalf("merge --abort should succeed: %s", result.Message)
+	}
+	if state.Merge != nil || state.WorkingDir["a.txt"].Content != "one\n2\n3\n" {
+		t.Error("Aborting should put the files back the way HEAD has them")
+	}
+	if _, staged := state.StagingArea["x.txt"]; !staged || len(state.StagingArea) != 1 {
+		t.Errorf("Aborting should keep unrelated staged work, got %v", sortedKeys(state.StagingArea))
+	}
+	delete(state.StagingArea, "x.txt")
+	delete(state.WorkingDir, "x.txt")
+	if _, exists := state.WorkingDir["b.txt"]; exists {
+		t.Error("Aborting should remove files the merge brought in")
+	}
+	if !statusClean(state) {
+		t.Error("The working tree should be clean after aborting")
+	}
+}
+
+func TestActorTriggers(t *testing.T) {
+	engine := NewEngine()
+	engine.CurrentLevel = &Level{
+		ValidateFunc: func(*GameState) (bool, string) { return false, "" },
+		Actors: []Actor{{
+			Name:    "Dr. Chen",
+			Trigger: ActorTrigger{AfterCommands: 2},
+			Commits: []ActorCommit{{Message: "Observation log", Files: map[string]string{"log.txt": "entity quiet"}}},
+		}},
+	}
+	engine.State = newRemoteTestState()
+	commitFile(t, engine.State, "notes.txt", "day 1", "Initial notes")
+
+	engine.ProcessCommand("git push")
+	if got := len(engine.State.Remotes["origin"].Branches["main"]); got != 1 {
+		t.Fatalf("Actor should not act before its trigger, remote has %d commits", got)
+	}
+
+	result := engine.ProcessCommand("git status")
+	if !strings.Contains(result.SCPEffect, "Dr. Chen pushed") {
+		t.Errorf("Expected actor announcement, got %q", result.SCPEffect)
+	}
+	if got := len(engine.State.Remotes["origin"].Branches["main"]); got != 2 {
+		t.Errorf("Expected actor commit on remote, got %d commits", got)
+	}
+
+	engine.ProcessCommand("git status")
+	if got := len(engine.State.Remotes["origin"].Branches["main"]); got != 2 {
+		t.Errorf("Actor with no remaining commits should stay idle, got %d commits", got)
+	}
+}
@@ -0,0 +1,346 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ShortlogCommand implements git shortlog
+type ShortlogCommand struct{}
+
+func (c *ShortlogCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	summary := false
+	numbered := false
+	rev := "HEAD"
+	for _, arg := range args {
+		switch {
+		case arg == "--summary":
+			summary = true
+		case arg == "--numbered":
+			numbered = true
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && len(arg) > 1:
+			for _, flag := range arg[1:] {
+				switch flag {
+				case 's':
+					summary = true
+				case 'n':
+					numbered = true
+				default:
+					return CommandResult{
+						Success:      false,
+						Message:      fmt.Sprintf("error: unknown switch `%c'", flag),
+						SCPEffect:    "🔴 ERROR: Malformed report request",
+						AnomalyDelta: 1,
+					}
+				}
+			}
+		default:
+			rev = arg
+		}
+	}
+
+	tip, err := resolveRevision(state, rev)
+	if err != nil {
+		if len(currentHistory(state)) == 0 {
+			return CommandResult{
+				Success:   true,
+				Message:   "",
+				SCPEffect: "📋 No containment history to summarize",
+			}
+		}
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// Group subjects by author, oldest first like git
+	subjects := make(map[string][]string)
+	var authors []string
+	for _, id := range commitHistory(state, tip) {
+		commit, found := state.findCommit(id)
+		if !found {
+			continue
+		}
+		if _, seen := subjects[commit.Author]; !seen {
+			authors = append(authors, commit.Author)
+		}
+		subjects[commit.Author] = append(subjects[commit.Author], commitSubject(commit.Message))
+	}
+
+	sort.SliceStable(authors, func(i, j int) bool {
+		if numbered && len(subjects[authors[i]]) != len(subjects[authors[j]]) {
+			return len(subjects[authors[i]]) > len(subjects[authors[j]])
+		}
+		return authors[i] < authors[j]
+	})
+
+	var report strings.Builder
+	for _, author := range authors {
+		if summary {
+			report.WriteString(fmt.Sprintf("%6d\t%s\n", len(subjects[author]), author))
+			continue
+		}
+		report.WriteString(fmt.Sprintf("%s (%d):\n", author, len(subjects[author])))
+		for _, subject := range subjects[author] {
+			report.WriteString(fmt.Sprintf("      %s\n", subject))
+		}
+		report.WriteString("\n")
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(report.String(), "\n"),
+		SCPEffect: fmt.Sprintf("📊 Personnel activity report compiled for %d researchers", len(authors)),
+	}
+}
+
+func (c *ShortlogCommand) Help() string {
+	return "Summarize commits by author"
+}
+
+func (c *ShortlogCommand) RequiredArgs() int {
+	return 0
+}
+
+// DescribeCommand implements git describe
+type DescribeCommand struct{}
+
+func (c *DescribeCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	useLightweight := false
+	always := false
+	rev := "HEAD"
+	for _, arg := range args {
+		switch arg {
+		case "--tags":
+			useLightweight = true
+		case "--always":
+			always = true
+		default:
+			rev = arg
+		}
+	}
+
+	target, err := resolveRevision(state, rev)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: Not a valid object name %s", rev),
+			SCPEffect:    "🔴 ERROR: Containment record not found",
+			AnomalyDelta: 1,
+		}
+	}
+
+	name, depth, found := nearestTag(state, target, useLightweight)
+	switch {
+	case found && depth == 0:
+		return CommandResult{
+			Success:   true,
+			Message:   name,
+			SCPEffect: fmt.Sprintf("🏷️  Record is designated '%s'", name),
+		}
+	case found:
+		return CommandResult{
+			Success:   true,
+			Message:   fmt.Sprintf("%s-%d-g%s", name, depth, target[:7]),
+			SCPEffect: fmt.Sprintf("🏷️  Record lies %d commits past designation '%s'", depth, name),
+		}
+	case always:
+		return CommandResult{
+			Success:   true,
+			Message:   target[:7],
+			SCPEffect: "🏷️  No designation reaches this record; showing its identifier",
+		}
+	}
+
+	message := "fatal: No names found, cannot describe anything."
+	if !useLightweight && len(state.Tags) > 0 {
+		if _, _, lightweight := nearestTag(state, target, true); lightweight {
+			message = fmt.Sprintf("fatal: No annotated tags can describe '%s'.\nHowever, there were unannotated tags: try --tags.", target)
+		}
+	}
+	return CommandResult{
+		Success:   false,
+		Message:   message,
+		SCPEffect: "⚠️  No designated record precedes this point in the timeline",
+	}
+}
+
+// nearestTag finds the tag reachable from target with the fewest commits
+// between them. The distance counts commits reachable from target but not
+// from the tag, as git does.
+func nearestTag(state *GameState, target string, includeLightweight bool) (string, int, bool) {
+	history := commitHistory(state, target)
+	best := ""
+	bestDepth := -1
+	for _, name := range sortedTagNames(state) {
+		tag := state.Tags[name]
+		if !tag.Annotated && !includeLightweight {
+			continue
+		}
+		if !containsString(history, tag.Commit) {
+			continue
+		}
+
+		tagged := commitHistory(state, tag.Commit)
+		depth := 0
+		for _, id := range history {
+			if !containsString(tagged, id) {
+				depth++
+			}
+		}
+		if bestDepth < 0 || depth < bestDepth {
+			best, bestDepth = name, depth
+		}
+	}
+	return best, bestDepth, bestDepth >= 0
+}
+
+func (c *DescribeCommand) Help() string {
+	return "Name a commit after the nearest tag"
+}
+
+func (c *DescribeCommand) RequiredArgs() int {
+	return 0
+}
+
+// ShowBranchCommand implements git show-branch
+type ShowBranchCommand struct{}
+
+func (c *ShowBranchCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = sortedBranchNames(state.Branches)
+	}
+
+	var histories [][]string
+	for _, name := range names {
+		commits, exists := resolveBranch(state, name)
+		if !exists {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("fatal: bad sha1 reference %s", name),
+				SCPEffect:    "🔴 ERROR: Unknown containment branch",
+				AnomalyDelta: 1,
+			}
+		}
+		if len(commits) == 0 {
+			return CommandResult{
+				Success:   false,
+				Message:   fmt.Sprintf("fatal: no commits on branch '%s'", name),
+				SCPEffect: "📋 No containment history available",
+			}
+		}
+		histories = append(histories, commits)
+	}
+
+	var report strings.Builder
+
+	// Header: one line per branch with its tip subject
+	for i, name := range names {
+		marker := "!"
+		if name == state.CurrentBranch {
+			marker = "*"
+		}
+		tip, _ := state.findCommit(histories[i][len(histories[i])-1])
+		report.WriteString(fmt.Sprintf("%s%s [%s] %s\n", strings.Repeat(" ", i), marker, name, commitSubject(tip.Message)))
+	}
+	report.WriteString(strings.Repeat("-", len(names)) + "\n")
+
+	// Body: newest first, stopping after the first commit every branch shares
+	for i := len(state.Commits) - 1; i >= 0; i-- {
+		commit := state.Commits[i]
+		var columns strings.Builder
+		label := ""
+		shared := true
+		for j, commits := range histories {
+			pos := indexOf(commits, commit.ID)
+			if pos < 0 {
+				columns.WriteString(" ")
+				shared = false
+				continue
+			}
+			switch {
+			case strings.HasPrefix(commit.Message, "Merge "):
+				columns.WriteString("-")
+			case names[j] == state.CurrentBranch:
+				columns.WriteString("*")
+			default:
+				columns.WriteString("+")
+			}
+			if label == "" {
+				label = names[j]
+				if back := len(commits) - 1 - pos; back > 0 {
+					label = fmt.Sprintf("%s~%d", names[j], back)
+				}
+			}
+		}
+		if label == "" {
+			continue
+		}
+		report.WriteString(fmt.Sprintf("%s [%s] %s\n", columns.String(), label, commitSubject(commit.Message)))
+		if shared {
+			break
+		}
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(report.String(), "\n"),
+		SCPEffect: fmt.Sprintf("📊 Divergence report across %d containment branches", len(names)),
+	}
+}
+
+// commitSubject returns the first line of a commit message
+func commitSubject(message string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+	return subject
+}
+
+// indexOf returns the position of s in list, or -1
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *ShowBranchCommand) Help() string {
+	return "Show branches and the commits they do not share"
+}
+
+func (c *ShowBranchCommand) RequiredArgs() int {
+	return 0
+}
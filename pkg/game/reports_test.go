@@ -0,0 +1,81 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func newReportTestState(t *testing.T) *GameState {
+	t.Helper()
+	state := NewGameState()
+	state.IsInitialized = true
+	state.CurrentBranch = "main"
+	state.Branches["main"] = []string{}
+	state.ConfigName = "Dr. Bright"
+	commitFile(t, state, "a.txt", "1", "First entry")
+	commitFile(t, state, "a.txt", "2", "Second entry")
+	state.ConfigName = "Dr. Clef"
+	commitFile(t, state, "b.txt", "1", "Clef's entry")
+	return state
+}
+
+func TestShortlogCommand(t *testing.T) {
+	state := newReportTestState(t)
+
+	result := (&ShortlogCommand{}).Execute([]string{"-sn"}, state)
+	if result.Message != "     2\tDr. Bright\n     1\tDr. Clef" {
+		t.Errorf("Unexpected summary %q", result.Message)
+	}
+
+	result = (&ShortlogCommand{}).Execute(nil, state)
+	if !strings.HasPrefix(result.Message, "Dr. Bright (2):\n      First entry\n      Second entry") {
+		t.Errorf("Expected subjects grouped by author, got %q", result.Message)
+	}
+}
+
+func TestDescribeCommand(t *testing.T) {
+	state := newReportTestState(t)
+	cmd := &DescribeCommand{}
+
+	result := cmd.Execute(nil, state)
+	if result.Success || !strings.Contains(result.Message, "No names found") {
+		t.Errorf("Describe without tags should fail, got %q", result.Message)
+	}
+	head := headCommitID(state)
+	if result = cmd.Execute([]string{"--always"}, state); result.Message != head[:7] {
+		t.Errorf("--always should fall back to the abbreviated ID, got %q", result.Message)
+	}
+
+	(&TagCommand{}).Execute([]string{"v0.9", "HEAD~1"}, state)
+	result = cmd.Execute(nil, state)
+	if !strings.Contains(result.Message, "try --tags") {
+		t.Errorf("Lightweight tags should only be suggested, got %q", result.Message)
+	}
+	if result = cmd.Execute([]string{"--tags"}, state); result.Message != "v0.9-1-g"+head[:7] {
+		t.Errorf("Unexpected description %q", result.Message)
+	}
+
+	(&TagCommand{}).Execute([]string{"-a", "v1.0", "-m", "Stable containment"}, state)
+	if result = cmd.Execute(nil, state); result.Message != "v1.0" {
+		t.Errorf("A tagged commit should be described by its tag, got %q", result.Message)
+	}
+}
+
+func TestShowBranchCommand(t *testing.T) {
+	state := newReportTestState(t)
+	(&SwitchCommand{}).Execute([]string{"-c", "feature"}, state)
+	commitFile(t, state, "c.txt", "1", "Feature work")
+	(&SwitchCommand{}).Execute([]string{"main"}, state)
+
+	result := (&ShowBranchCommand{}).Execute(nil, state)
+	want := []string{
+		"! [feature] Feature work",
+		" * [main] Clef's entry",
+		"--",
+		"+  [feature] Feature work",
+		"+* [feature~1] Clef's entry",
+	}
+	if result.Message != strings.Join(want, "\n") {
+		t.Errorf("Unexpected show-branch output:\n%s", result.Message)
+	}
+}
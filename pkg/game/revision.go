@@ -0,0 +1,166 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolveRevision turns a revision such as HEAD, HEAD~2, main^, origin/main,
+// a tag name or an abbreviated commit ID into a full commit ID.
+// Ancestry follows the order of commits recorded on the branch.
+func resolveRevision(state *GameState, rev string) (string, error) {
+	base, back, err := splitAncestry(rev)
+	if err != nil {
+		return "", err
+	}
+
+	var history []string
+	switch {
+	case base == "HEAD" || base == "@":
+		history = currentHistory(state)
+	default:
+		if commits, exists := resolveBranch(state, base); exists {
+			history = commits
+		} else if tag, exists := state.Tags[base]; exists {
+			history = commitHistory(state, tag.Commit)
+		} else if id, found := findCommitByPrefix(state, base); found {
+			history = commitHistory(state, id)
+		} else {
+			return "", fmt.Errorf("fatal: ambiguous argument '%s': unknown revision or path not in the working tree.", rev)
+		}
+	}
+
+	if len(history) == 0 {
+		return "", fmt.Errorf("fatal: ambiguous argument '%s': unknown revision or path not in the working tree.", rev)
+	}
+	if back >= len(history) {
+		return "", fmt.Errorf("fatal: ambiguous argument '%s': unknown revision or path not in the working tree.", rev)
+	}
+	return history[len(history)-1-back], nil
+}
+
+// splitAncestry separates "name~2^" into the name and the number of steps back
+func splitAncestry(rev string) (string, int, error) {
+	cut := strings.IndexAny(rev, "~^")
+	if cut < 0 {
+		return rev, 0, nil
+	}
+
+	base, suffix := rev[:cut], rev[cut:]
+	back := 0
+	for len(suffix) > 0 {
+		op := suffix[0]
+		suffix = suffix[1:]
+
+		digits := 0
+		for digits < len(suffix) && suffix[digits] >= '0' && suffix[digits] <= '9' {
+			digits++
+		}
+		steps := 1
+		if digits > 0 {
+			steps, _ = strconv.Atoi(suffix[:digits])
+			suffix = suffix[digits:]
+		}
+		if op == '^' && digits > 0 && steps != 1 {
+			return "", 0, fmt.Errorf("fatal: ambiguous argument '%s': only first parents are recorded", rev)
+		}
+		back += steps
+	}
+	return base, back, nil
+}
+
+// findCommitByPrefix finds a commit by full or abbreviated ID
+func findCommitByPrefix(state *GameState, prefix string) (string, bool) {
+	if len(prefix) < 4 {
+		return "", false
+	}
+	for _, commit := range state.Commits {
+		if strings.HasPrefix(commit.ID, prefix) {
+			return commit.ID, true
+		}
+	}
+	return "", false
+}
+
+// currentHistory returns the commits HEAD reaches: the current branch, or
+// the detached HEAD
+func currentHistory(state *GameState) []string {
+	if state.CurrentBranch == DetachedHead {
+		return state.Detached
+	}
+	return state.Branches[state.CurrentBranch]
+}
+
+// setCurrentHistory moves HEAD along with the current branch, or on its own
+// when it is detached
+func setCurrentHistory(state *GameState, history []string) {
+	if state.CurrentBranch == DetachedHead {
+		state.Detached = history
+		return
+	}
+	state.Branches[state.CurrentBranch] = history
+}
+
+// headCommitID returns the tip of the current branch, or "" before the first commit
+func headCommitID(state *GameState) string {
+	history := currentHistory(state)
+	if len(history) == 0 {
+		return ""
+	}
+	return history[len(history)-1]
+}
+
+// commitHistory returns the commits leading up to and including id, taken
+// from the current branch when it contains the commit, otherwise from any
+// branch that does
+func commitHistory(state *GameState, id string) []string {
+	candidates := [][]string{currentHistory(state)}
+	for _, name := range sortedBranchNames(state.Branches) {
+		candidates = append(candidates, state.Branches[name])
+	}
+	for _, name := range sortedBranchNames(state.RemoteBranches) {
+		candidates = append(candidates, state.RemoteBranches[name])
+	}
+
+	for _, commits := range candidates {
+		for i, commitID := range commits {
+			if commitID == id {
+				return commits[:i+1]
+			}
+		}
+	}
+
+	// Commits no branch reaches still know their parents
+	if parents := state.CommitGraph[id]; len(parents) > 0 {
+		return append(append([]string{}, commitHistory(state, parents[0])...), id)
+	}
+	return []string{id}
+}
+
+// treeAt reconstructs the full file listing (filename -> hash) at a commit by
+// layering the files recorded by each commit in its history
+func treeAt(state *GameState, id string) map[string]string {
+	tree := make(map[string]string)
+	for _, commitID := range commitHistory(state, id) {
+		commit, found := state.findCommit(commitID)
+		if !found {
+			continue
+		}
+		for filename, hash := range commit.Files {
+			tree[filename] = hash
+		}
+	}
+	return tree
+}
+
+// sortedBranchNames returns the names of a branch map in lexical order
+func sortedBranchNames(branches map[string][]string) []string {
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,93 @@
+package game
+
+// Researcher sanity falls when the player destroys work, keeps making
+// mistakes or meets the entity, and recovers with clean commits. At 0 the
+// researcher collapses and the game is over.
+
+// MaxSanity is the sanity a researcher starts with
+const MaxSanity = 100
+
+// Sanity costs and recoveries
+const (
+	destructiveSanityCost = 8 // reset --hard, clean -f, push --force...
+	errorStreakSanityCost = 2 // per failure in a row after the first
+	maxErrorSanityCost    = 10
+	cleanCommitRecovery   = 5 // a commit that leaves nothing uncommitted
+)
+
+// isDestructive reports whether a successful command threw work away.
+// Shell builtins are checked by name, git commands by subcommand.
+func isDestructive(command string, args []string) bool {
+	hasShortFlag := func(flag rune) bool {
+		for _, arg := range args {
+			if len(arg) > 1 && arg[0] == '-' && arg[1] != '-' {
+				for _, r := range arg[1:] {
+					if r == flag {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+
+	switch command {
+	case "reset":
+		return containsString(args, "--hard")
+	case "clean":
+		return (hasShortFlag('f') || containsString(args, "--force")) && !hasShortFlag('n') && !containsString(args, "--dry-run")
+	case "push":
+		return hasShortFlag('f') || containsString(args, "--force") || containsString(args, "--force-with-lease")
+	case "branch":
+		return hasShortFlag('D')
+	case "stash":
+		return len(args) > 0 && (args[0] == "drop" || args[0] == "clear")
+	case "gc":
+		return containsString(args, "--prune=now") || containsString(args, "--prune=all")
+	case "prune":
+		return !hasShortFlag('n') && !containsString(args, "--dry-run")
+	case "reflog":
+		return len(args) > 0 && args[0] == "expire"
+	case "rm":
+		return hasShortFlag('r') || hasShortFlag('R')
+	}
+	return false
+}
+
+// encounterSanityCost is what meeting the entity costs, rising with its tone
+func encounterSanityCost(state *GameState) int {
+	switch CurrentAwarenessStage(state).Tone {
+	case ToneHostile:
+		return 7
+	case ToneCurious:
+		return 5
+	default:
+		return 3
+	}
+}
+
+// sanityDelta works out how a finished command affected the researcher:
+// command is a git subcommand or a shell builtin, encounters the entity's
+// announcements that turn
+func sanityDelta(state *GameState, result CommandResult, command string, args []string, encounters int) int {
+	delta := -encounters * encounterSanityCost(state)
+
+	if !result.Success {
+		state.ErrorStreak++
+		if cost := errorStreakSanityCost * (state.ErrorStreak - 1); cost > maxErrorSanityCost {
+			delta -= maxErrorSanityCost
+		} else {
+			delta -= cost
+		}
+		return delta
+	}
+
+	state.ErrorStreak = 0
+	if isDestructive(command, args) {
+		delta -= destructiveSanityCost
+	}
+	if command == "commit" && !repositoryDirty(state) {
+		delta += cleanCommitRecovery
+	}
+	return delta
+}
@@ -0,0 +1,103 @@
+package game
+
+import "testing"
+
+func TestSanityErrorStreak(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+
+	wants := []int{0, -2, -4, -6, -8, -10, -10}
+	for i, want := range wants {
+		if result := engine.ProcessCommand("cat missing.txt"); result.SanityDelta != want {
+			t.Errorf("Failure %d should cost %d sanity, got %d", i+1, -want, -result.SanityDelta)
+		}
+	}
+	if engine.State.Sanity != MaxSanity-40 {
+		t.Errorf("Expected sanity %d, got %d", MaxSanity-40, engine.State.Sanity)
+	}
+
+	engine.ProcessCommand("ls")
+	if result := engine.ProcessCommand("cat missing.txt"); result.SanityDelta != 0 {
+		t.Error("A success should end the error streak")
+	}
+}
+
+func TestSanityDestructiveAndCleanCommits(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+	engine.State.Sanity = 50
+	engine.ProcessCommand("echo notes > notes.txt")
+	engine.ProcessCommand("echo scratch > scratch.txt")
+	engine.ProcessCommand("git add notes.txt")
+
+	if result := engine.ProcessCommand("git commit -m 'Add notes'"); result.SanityDelta != 0 {
+		t.Errorf("A commit leaving untracked files behind should not restore sanity, got %+d", result.SanityDelta)
+	}
+	if result := engine.ProcessCommand("git clean -n"); result.SanityDelta != 0 {
+		t.Error("A dry run destroys nothing")
+	}
+	if result := engine.ProcessCommand("git clean -f"); result.SanityDelta != -destructiveSanityCost {
+		t.Errorf("clean -f should cost %d sanity, got %+d", destructiveSanityCost, result.SanityDelta)
+	}
+
+	engine.ProcessCommand("echo more >> notes.txt")
+	if result := engine.ProcessCommand("git commit -a -m 'Extend notes'"); result.SanityDelta != cleanCommitRecovery {
+		t.Errorf("A clean commit should restore %d sanity, got %+d", cleanCommitRecovery, result.SanityDelta)
+	}
+	if engine.State.Sanity != 50-destructiveSanityCost+cleanCommitRecovery {
+		t.Errorf("Unexpected sanity %d", engine.State.Sanity)
+	}
+}
+
+func TestSanityEditorCommitsSettleWhenFinished(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+	engine.State.Sanity = 50
+	engine.ProcessCommand("echo notes > notes.txt")
+	engine.ProcessCommand("git add notes.txt")
+
+	result := engine.ProcessCommand("git commit")
+	if result.Edit == nil || result.SanityDelta != 0 {
+		t.Fatal("git commit without -m should wait on the editor before settling sanity")
+	}
+	if result = engine.FinishEdit(result.Edit, "Add notes\n"); result.SanityDelta != cleanCommitRecovery {
+		t.Errorf("Finishing the commit should restore sanity, got %+d", result.SanityDelta)
+	}
+}
+
+func TestSanityEntityEncounters(t *testing.T) {
+	engine := newEntityTestEngine(EntityBehavior{
+		Name:    "visitor",
+		Trigger: EntityTrigger{AfterCommand: "status"},
+		Actions: []EntityAction{{Kind: EntityAppend, File: "log.txt", Content: "boo"}},
+	})
+	engine.State.Awareness = 65
+
+	if result := engine.ProcessCommand("git status"); result.SanityDelta != -7 {
+		t.Errorf("Meeting a hostile entity should cost 7 sanity, got %+d", result.SanityDelta)
+	}
+}
+
+func TestIsDestructive(t *testing.T) {
+	cases := []struct {
+		command string
+		args    []string
+		want    bool
+	}{
+		{"reset", []string{"--hard", "HEAD~1"}, true},
+		{"reset", []string{"--soft", "HEAD~1"}, false},
+		{"clean", []string{"-fdx"}, true},
+		{"clean", []string{"-nf"}, false},
+		{"push", []string{"--force", "origin", "main"}, true},
+		{"branch", []string{"-D", "old"}, true},
+		{"branch", []string{"-d", "old"}, false},
+		{"stash", []string{"drop"}, true},
+		{"rm", []string{"-rf", "logs"}, true},
+		{"rm", []string{"notes.txt"}, false},
+	}
+	for _, c := range cases {
+		if got := isDestructive(c.command, c.args); got != c.want {
+			t.Errorf("isDestructive(%s %v) = %v, want %v", c.command, c.args, got, c.want)
+		}
+	}
+}
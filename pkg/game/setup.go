@@ -0,0 +1,306 @@
+package game
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A level can start in a repository with history already in it: commits
+// by earlier researchers, branches, tags, staged work and stash entries.
+// The repository is rebuilt the same way every time the level starts, with
+// commit IDs derived from the commits' contents.
+
+// RepositorySetup is the repository a level starts in
+type RepositorySetup struct {
+	Commits   []SetupCommit     `yaml:"commits"`   // in order
+	Branches  map[string]string `yaml:"branches"`  // extra branch -> commit label
+	Tags      map[string]string `yaml:"tags"`      // tag -> commit label
+	Head      string            `yaml:"head"`      // branch checked out; defaults to main
+	Staged    map[string]string `yaml:"staged"`    // file -> content in the index
+	Stash     []SetupStash      `yaml:"stash"`     // oldest first
+	Published []string          `yaml:"published"` // branches already pushed to origin
+}
+
+// SetupCommit is one commit of a level's starting history. A commit extends
+// its branch; the first commit on a new branch starts from another commit.
+type SetupCommit struct {
+	Label   string            `yaml:"label"`  // how branches, tags and later commits refer to it
+	Branch  string            `yaml:"branch"` // defaults to main
+	From    string            `yaml:"from"`   // commit label a new branch starts from
+	Merge   string            `yaml:"merge"`  // branch merged in, making this a merge commit
+	Author  string            `yaml:"author"`
+	Date    time.Time         `yaml:"date"`
+	Message string            `yaml:"message"`
+	Files   map[string]string `yaml:"files"` // file -> content
+}
+
+// SetupStash is a stash entry a level starts with
+type SetupStash struct {
+	Message string            `yaml:"message"`
+	Branch  string            `yaml:"branch"` // defaults to the checked-out branch
+	Files   map[string]string `yaml:"files"`  // file -> stashed working copy
+}
+
+// setupEpoch dates starting commits that give no date, an hour apart
+var setupEpoch = time.Date(2019, time.October, 31, 9, 0, 0, 0, time.UTC)
+
+// validate checks that every reference in the setup resolves
+func (s *RepositorySetup) validate() []string {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	labels := make(map[string]bool)
+	branches := make(map[string]bool)
+	for i, c := range s.Commits {
+		at := fmt.Sprintf("repository.commits[%d]", i)
+		branch := c.Branch
+		if branch == "" {
+			branch = "main"
+		}
+		if c.Message == "" && c.Merge == "" {
+			problem("%s.message: required", at)
+		}
+		if c.From != "" && !labels[c.From] {
+			problem("%s.from: no earlier commit is labelled %q", at, c.From)
+		}
+		if c.From != "" && branches[branch] {
+			problem("%s.from: branch %s already exists", at, branch)
+		}
+		if !branches[branch] && c.From == "" && i > 0 {
+			problem("%s.from: required to start branch %s", at, branch)
+		}
+		if c.Merge != "" && (!branches[c.Merge] || c.Merge == branch) {
+			problem("%s.merge: %q is not another branch with commits", at, c.Merge)
+		}
+		if c.Label != "" {
+			if labels[c.Label] {
+				problem("%s.label: %q is used twice", at, c.Label)
+			}
+			labels[c.Label] = true
+		}
+		branches[branch] = true
+	}
+
+	for _, name := range sortedNames(s.Branches) {
+		if !labels[s.Branches[name]] {
+			problem("repository.branches.%s: no commit is labelled %q", name, s.Branches[name])
+		}
+		branches[name] = true
+	}
+	for _, name := range sortedNames(s.Tags) {
+		if !labels[s.Tags[name]] {
+			problem("repository.tags.%s: no commit is labelled %q", name, s.Tags[name])
+		}
+	}
+	if s.Head != "" && !branches[s.Head] {
+		problem("repository.head: no branch %q", s.Head)
+	}
+	for i, branch := range s.Published {
+		if !branches[branch] {
+			problem("repository.published[%d]: no branch %q", i, branch)
+		}
+	}
+	for i, entry := range s.Stash {
+		if len(entry.Files) == 0 {
+			problem("repository.stash[%d].files: at least one file is required", i)
+		}
+		if entry.Branch != "" && !branches[entry.Branch] {
+			problem("repository.stash[%d].branch: no branch %q", i, entry.Branch)
+		}
+	}
+	return problems
+}
+
+// setupCommitID derives a commit's ID from everything it records, so a
+// level's history has the same IDs every time
+func setupCommitID(commit Commit, parents []string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n", strings.Join(parents, " "), commit.Author, commit.Timestamp.Format(time.RFC3339), commit.Message)
+	files := make([]string, 0, len(commit.Files))
+	for filename := range commit.Files {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+	for _, filename := range files {
+		fmt.Fprintf(h, "%s %s\n", commit.Files[filename], filename)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// buildRepository replaces the repository with the one the setup
+// describes. The researcher's identity and config are kept.
+func buildRepository(state *GameState, setup *RepositorySetup) error {
+	if problems := setup.validate(); len(problems) > 0 {
+		return fmt.Errorf("invalid level repository: %s", strings.Join(problems, "; "))
+	}
+
+	state.IsInitialized = true
+	state.Branches = make(map[string][]string)
+	state.Commits = []Commit{}
+	state.CommitGraph = make(map[string][]string)
+	state.Objects = make(map[string]string)
+	state.Packed = make(map[string]bool)
+	state.Reflog = nil
+	state.Tags = make(map[string]Tag)
+	state.Notes = make(map[string]string)
+	state.Stash = nil
+	state.Worktrees = make(map[string]*Worktree)
+	state.Submodules = make(map[string]*Submodule)
+	state.Remotes = make(map[string]*Remote)
+	state.RemoteBranches = make(map[string][]string)
+	state.Upstreams = make(map[string]string)
+	state.Sparse = nil
+	state.Directories = make(map[string]bool)
+	state.PublishedRewrites = 0
+
+	labels := make(map[string]string)
+	for i, sc := range setup.Commits {
+		branch := sc.Branch
+		if branch == "" {
+			branch = "main"
+		}
+		history, exists := state.Branches[branch]
+		if !exists && sc.From != "" {
+			history = append([]string{}, commitHistory(state, labels[sc.From])...)
+		}
+
+		commit := Commit{
+			Message:   sc.Message,
+			Author:    sc.Author,
+			Timestamp: sc.Date,
+			Files:     make(map[string]string),
+			Branch:    branch,
+		}
+		if commit.Author == "" {
+			commit.Author = "Dr. ████████"
+		}
+		if commit.Timestamp.IsZero() {
+			commit.Timestamp = setupEpoch.Add(time.Duration(i) * time.Hour)
+		}
+
+		var parents []string
+		if len(history) > 0 {
+			parents = []string{history[len(history)-1]}
+		}
+		if sc.Merge != "" {
+			// The merge brings in the other branch's commits and its versions of the files
+			source := state.Branches[sc.Merge]
+			tip := source[len(source)-1]
+			current := make(map[string]string)
+			if len(parents) > 0 {
+				current = treeAt(state, parents[0])
+			}
+			for filename, hash := range treeAt(state, tip) {
+				if current[filename] != hash {
+					commit.Files[filename] = hash
+				}
+			}
+			for _, id := range source {
+				if !containsString(history, id) {
+					history = append(history, id)
+				}
+			}
+			parents = append(parents, tip)
+			if commit.Message == "" {
+				commit.Message = fmt.Sprintf("Merge branch '%s' into %s", sc.Merge, branch)
+			}
+		}
+		for filename, content := range sc.Files {
+			commit.Files[filename] = state.storeObject(content)
+		}
+
+		commit.ID = setupCommitID(commit, parents)
+		state.Commits = append(state.Commits, commit)
+		if len(parents) > 0 {
+			state.CommitGraph[commit.ID] = parents
+		}
+		state.Branches[branch] = append(history, commit.ID)
+		if sc.Label != "" {
+			labels[sc.Label] = commit.ID
+		}
+	}
+
+	for name, label := range setup.Branches {
+		state.Branches[name] = append([]string{}, commitHistory(state, labels[label])...)
+	}
+	for name, label := range setup.Tags {
+		commit, _ := state.findCommit(labels[label])
+		state.Tags[name] = Tag{Name: name, Commit: commit.ID, Timestamp: commit.Timestamp}
+	}
+
+	state.CurrentBranch = setup.Head
+	if state.CurrentBranch == "" {
+		state.CurrentBranch = "main"
+	}
+	if _, exists := state.Branches[state.CurrentBranch]; !exists {
+		state.Branches[state.CurrentBranch] = []string{}
+	}
+
+	// The working directory starts as a clean checkout of HEAD
+	state.WorkingDir = make(map[string]FileState)
+	state.StagingArea = make(map[string]FileState)
+	if head := headCommitID(state); head != "" {
+		state.WorkingDir = checkoutTree(state, head)
+	}
+
+	if len(setup.Published) > 0 {
+		origin := NewRemote("origin", DefaultRemoteURL)
+		for _, branch := range setup.Published {
+			history := state.Branches[branch]
+			for _, id := range history {
+				if commit, found := state.findCommit(id); found && !origin.hasCommit(id) {
+					origin.Commits = append(origin.Commits, commit)
+				}
+			}
+			origin.Branches[branch] = append([]string{}, history...)
+			state.RemoteBranches["origin/"+branch] = append([]string{}, history...)
+			state.Upstreams[branch] = "origin/" + branch
+		}
+		state.Remotes["origin"] = origin
+	}
+
+	for _, se := range setup.Stash {
+		branch := se.Branch
+		if branch == "" {
+			branch = state.CurrentBranch
+		}
+		entry := StashEntry{
+			Message: fmt.Sprintf("On %s: %s", branch, se.Message),
+			Branch:  branch,
+			Working: make(map[string]string),
+			Staged:  make(map[string]string),
+		}
+		if history := state.Branches[branch]; len(history) > 0 {
+			entry.Base = history[len(history)-1]
+		}
+		for filename, content := range se.Files {
+			entry.Working[filename] = content
+		}
+		state.Stash = append([]StashEntry{entry}, state.Stash...)
+	}
+	return nil
+}
+
+// applyStartingChanges lays a level's files over a built repository as
+// uncommitted changes, then stages the setup's index entries
+func applyStartingChanges(state *GameState, files map[string]string, staged map[string]string) {
+	tree := make(map[string]string)
+	if head := headCommitID(state); head != "" {
+		tree = treeAt(state, head)
+	}
+	for filename, content := range files {
+		hash := hashContent(content)
+		state.WorkingDir[filename] = FileState{Content: content, Hash: hash, Modified: tree[filename] != hash}
+	}
+	for filename, content := range staged {
+		hash := state.storeObject(content)
+		file := FileState{Content: content, Hash: hash, Modified: tree[filename] != hash, Staged: true}
+		state.WorkingDir[filename] = file
+		state.StagingArea[filename] = file
+	}
+}
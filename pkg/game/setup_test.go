@@ -0,0 +1,116 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+const setupLevelFile = `
+id: 9
+title: Night Shift
+description: d
+objective: o
+files:
+  survey.txt: "Sector 7 is not quiet"
+objectives:
+  - {description: look, check: used "git log"}
+repository:
+  commits:
+    - label: survey
+      author: Dr. Gears
+      date: 2019-10-28T09:14:00Z
+      message: Begin survey
+      files: {survey.txt: "Sector 7 quiet"}
+    - branch: night-shift
+      from: survey
+      author: SCP-████
+      message: Routine maintenance
+      files: {survey.txt: "Sector 7 is not quiet"}
+    - merge: night-shift
+  branches: {backup: survey}
+  tags: {v1.0: survey}
+  head: main
+  published: [main]
+  staged: {plan.txt: "draft"}
+  stash:
+    - message: unfinished analysis
+      files: {survey.txt: "It moved again"}
+`
+
+func TestLevelFileRepository(t *testing.T) {
+	level, err := ParseLevelFile("night.yaml", []byte(setupLevelFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(saved map[int]*Level) { loadedLevels = saved }(loadedLevels)
+	loadedLevels = map[int]*Level{9: level}
+
+	engine := NewEngine()
+	if err := engine.StartLevel(9); err != nil {
+		t.Fatal(err)
+	}
+	state := engine.State
+
+	main := state.Branches["main"]
+	if len(main) != 3 || len(state.Branches["night-shift"]) != 2 || len(state.Branches["backup"]) != 1 {
+		t.Fatalf("Unexpected branches %v", state.Branches)
+	}
+	merge, _ := state.findCommit(main[2])
+	if len(state.CommitGraph[merge.ID]) != 2 || merge.Message != "Merge branch 'night-shift' into main" {
+		t.Errorf("The last commit should merge night-shift, got %+v", merge)
+	}
+	if state.Tags["v1.0"].Commit != main[0] || state.RemoteBranches["origin/main"][2] != main[2] {
+		t.Error("Tags and published branches should point at the built commits")
+	}
+	if file := state.WorkingDir["survey.txt"]; file.Modified || file.Content != "Sector 7 is not quiet" {
+		t.Errorf("A level file matching HEAD should be unmodified, got %+v", file)
+	}
+	if _, staged := state.StagingArea["plan.txt"]; !staged {
+		t.Error("The setup's index entries should be staged")
+	}
+	if len(state.Stash) != 1 || state.Stash[0].Message != "On main: unfinished analysis" {
+		t.Errorf("Unexpected stash %+v", state.Stash)
+	}
+
+	// The same history every time the level starts
+	ids := append([]string{}, main...)
+	engine.ProcessCommand("git commit -m 'Interference'")
+	if err := engine.StartLevel(9); err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range engine.State.Branches["main"] {
+		if id != ids[i] {
+			t.Errorf("Commit %d changed ID between starts: %s != %s", i, id, ids[i])
+		}
+	}
+}
+
+func TestRepositorySetupValidation(t *testing.T) {
+	setup := &RepositorySetup{
+		Commits: []SetupCommit{
+			{Label: "a", Message: "first"},
+			{Branch: "side", Message: "orphan"},
+			{Label: "a", From: "missing", Branch: "other", Message: "again"},
+			{Merge: "main"},
+		},
+		Tags:  map[string]string{"v1": "nowhere"},
+		Head:  "ghost",
+		Stash: []SetupStash{{Message: "empty"}},
+	}
+	want := []string{
+		"repository.commits[1].from: required to start branch side",
+		`repository.commits[2].from: no earlier commit is labelled "missing"`,
+		`repository.commits[2].label: "a" is used twice`,
+		`repository.commits[3].merge: "main" is not another branch with commits`,
+		`repository.tags.v1: no commit is labelled "nowhere"`,
+		`repository.head: no branch "ghost"`,
+		"repository.stash[0].files: at least one file is required",
+	}
+	if got := setup.validate(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected problems:\n%s", strings.Join(got, "\n"))
+	}
+	if err := buildRepository(NewGameState(), setup); err == nil {
+		t.Error("An invalid setup should not be built")
+	}
+}
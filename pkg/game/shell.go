@@ -0,0 +1,534 @@
+package game
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Shell builtins work on the active worktree's files. Directories exist while
+// files live under them; mkdir records empty ones in GameState.Directories,
+// which git never sees.
+
+// ShellBuiltin is a terminal command other than git
+type ShellBuiltin func(args []string, state *GameState) CommandResult
+
+// ShellBuiltins maps builtin names to their implementations
+var ShellBuiltins = map[string]ShellBuiltin{
+	"ls":    listFiles,
+	"cat":   catFiles,
+	"echo":  echoText,
+	"touch": touchFiles,
+	"rm":    removeFiles,
+	"mv":    moveFile,
+	"mkdir": makeDirs,
+	"head":  func(args []string, state *GameState) CommandResult { return fileLines("head", args, state) },
+	"tail":  func(args []string, state *GameState) CommandResult { return fileLines("tail", args, state) },
+}
+
+// shellError is the failure result shared by the builtins
+func shellError(format string, args ...interface{}) CommandResult {
+	return CommandResult{
+		Success: false,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// splitFlags separates single-dash flags (as a set of letters) from operands.
+// "--" ends the flags.
+func splitFlags(args []string) (map[rune]bool, []string) {
+	flags := make(map[rune]bool)
+	var operands []string
+	for i, arg := range args {
+		if arg == "--" {
+			operands = append(operands, args[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			for _, flag := range arg[1:] {
+				flags[flag] = true
+			}
+			continue
+		}
+		operands = append(operands, arg)
+	}
+	return flags, operands
+}
+
+// isDirectory reports whether a path names a directory: the worktree root, a
+// directory holding files, an empty directory made with mkdir, or a submodule
+func isDirectory(state *GameState, dir string) bool {
+	if dir == "." || state.Directories[dir] {
+		return true
+	}
+	if _, isSubmodule := state.Submodules[dir]; isSubmodule {
+		return true
+	}
+	for filename := range state.WorkingDir {
+		if strings.HasPrefix(filename, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isFile reports whether a path names a working file
+func isFile(state *GameState, filename string) bool {
+	_, exists := state.WorkingDir[filename]
+	return exists && !isDirectory(state, filename)
+}
+
+// writablePath resolves the file a builtin writes to. Its directory must
+// already exist, and .git is off limits.
+func writablePath(state *GameState, cmd, p string) (string, error) {
+	filename, err := normalizePath(p)
+	if err != nil {
+		return "", err
+	}
+	if filename == ".git" || strings.HasPrefix(filename, ".git/") {
+		return "", fmt.Errorf("%s: %s: Permission denied (use 'edit %s' for hooks)", cmd, p, HookDir+"<hook>")
+	}
+	if isDirectory(state, filename) {
+		return "", fmt.Errorf("%s: %s: Is a directory", cmd, p)
+	}
+	if dir := path.Dir(filename); !isDirectory(state, dir) {
+		return "", fmt.Errorf("%s: %s: No such file or directory", cmd, p)
+	}
+	return filename, nil
+}
+
+// keepParentDirs records the directories of a removed file so they survive
+// as empty directories, as they would on disk
+func keepParentDirs(state *GameState, filename string) {
+	for _, dir := range parentDirs(filename) {
+		if !isDirectory(state, dir) {
+			if state.Directories == nil {
+				state.Directories = make(map[string]bool)
+			}
+			state.Directories[dir] = true
+		}
+	}
+}
+
+// writeFile replaces the content of a working file
+func writeFile(state *GameState, filename, content string) {
+	state.WorkingDir[filename] = FileState{Content: content, Hash: hashContent(content)}
+}
+
+// shellEntries lists every path ls can see, with directories that hold no
+// files marked by a trailing slash
+func shellEntries(state *GameState) []string {
+	var entries []string
+	for filename := range state.WorkingDir {
+		if _, isSubmodule := state.Submodules[filename]; isSubmodule {
+			filename += "/"
+		}
+		entries = append(entries, filename)
+	}
+	for dir := range state.Directories {
+		entries = append(entries, dir+"/")
+	}
+	return entries
+}
+
+// listFiles implements ls [-l] [-a] [path...]
+func listFiles(args []string, state *GameState) CommandResult {
+	flags, operands := splitFlags(args)
+	if len(operands) == 0 {
+		operands = []string{"."}
+	}
+
+	long := func(name, entry string) string {
+		if strings.HasSuffix(name, "/") {
+			return fmt.Sprintf("drwxr-xr-x 2 researcher foundation %6d %s", 4096, name)
+		}
+		return fmt.Sprintf("-rw-r--r-- 1 researcher foundation %6d %s", len(state.WorkingDir[entry].Content), name)
+	}
+
+	var sections []string
+	var failures []string
+	for _, operand := range operands {
+		target, err := normalizePath(operand)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("ls: cannot access '%s': No such file or directory", operand))
+			continue
+		}
+		if isFile(state, target) {
+			if flags['l'] {
+				sections = append(sections, long(operand, target))
+			} else {
+				sections = append(sections, operand)
+			}
+			continue
+		}
+		if !isDirectory(state, target) {
+			failures = append(failures, fmt.Sprintf("ls: cannot access '%s': No such file or directory", operand))
+			continue
+		}
+
+		var names []string
+		if flags['a'] {
+			names = append(names, "./", "../")
+			if target == "." {
+				names = append(names, ".git/")
+			}
+		}
+		for _, entry := range dirEntries(shellEntries(state), target) {
+			if entry == "" || (!flags['a'] && strings.HasPrefix(entry, ".")) {
+				continue
+			}
+			names = append(names, entry)
+		}
+
+		var listing string
+		if flags['l'] {
+			var lines []string
+			for _, name := range names {
+				lines = append(lines, long(name, path.Join(target, name)))
+			}
+			listing = strings.Join(lines, "\n")
+		} else {
+			listing = strings.Join(names, "  ")
+		}
+		if len(operands) > 1 {
+			listing = operand + ":\n" + listing
+		}
+		sections = append(sections, listing)
+	}
+
+	separator := "\n"
+	if len(operands) > 1 {
+		separator = "\n\n"
+	}
+	if len(sections) > 0 {
+		failures = append(failures, strings.Join(sections, separator))
+	}
+	return CommandResult{
+		Success: len(sections) == len(operands),
+		Message: strings.Join(failures, "\n"),
+	}
+}
+
+// catFiles prints working files or hook scripts
+func catFiles(args []string, state *GameState) CommandResult {
+	if len(args) == 0 {
+		return shellError("usage: cat <file>...")
+	}
+
+	var output strings.Builder
+	for _, arg := range args {
+		if strings.HasPrefix(arg, HookDir) {
+			content, exists := state.Hooks[strings.TrimPrefix(arg, HookDir)]
+			if !exists {
+				return shellError("cat: %s: No such file or directory", arg)
+			}
+			output.WriteString(content)
+			continue
+		}
+
+		filename, err := normalizePath(arg)
+		if err != nil {
+			return shellError("cat: %s: No such file or directory", arg)
+		}
+		if isDirectory(state, filename) {
+			return shellError("cat: %s: Is a directory", arg)
+		}
+		file, exists := state.WorkingDir[filename]
+		if !exists {
+			return shellError("cat: %s: No such file or directory", arg)
+		}
+		output.WriteString(file.Content)
+	}
+	return CommandResult{
+		Success: true,
+		Message: output.String(),
+	}
+}
+
+// echoText implements echo [-n] text... [> file | >> file]
+func echoText(args []string, state *GameState) CommandResult {
+	newline := "\n"
+	if len(args) > 0 && args[0] == "-n" {
+		newline = ""
+		args = args[1:]
+	}
+
+	var words []string
+	target, appendTo := "", false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		redirect := ""
+		switch {
+		case strings.HasPrefix(arg, ">>"):
+			redirect, appendTo = arg[2:], true
+		case strings.HasPrefix(arg, ">"):
+			redirect, appendTo = arg[1:], false
+		default:
+			words = append(words, arg)
+			continue
+		}
+		if redirect == "" {
+			if i+1 == len(args) {
+				return shellError("echo: syntax error near unexpected token `newline'")
+			}
+			i++
+			redirect = args[i]
+		}
+		target = redirect
+	}
+	text := strings.Join(words, " ") + newline
+
+	if target == "" {
+		return CommandResult{
+			Success: true,
+			Message: strings.TrimSuffix(text, "\n"),
+		}
+	}
+
+	filename, err := writablePath(state, "echo", target)
+	if err != nil {
+		return shellError("%s", err.Error())
+	}
+	if appendTo {
+		text = state.WorkingDir[filename].Content + text
+	}
+	writeFile(state, filename, text)
+	return CommandResult{
+		Success: true,
+		Message: "",
+	}
+}
+
+// touchFiles creates empty files; existing files are left as they are
+func touchFiles(args []string, state *GameState) CommandResult {
+	_, operands := splitFlags(args)
+	if len(operands) == 0 {
+		return shellError("touch: missing file operand")
+	}
+	for _, operand := range operands {
+		filename, err := normalizePath(operand)
+		if err == nil && isDirectory(state, filename) {
+			continue
+		}
+		filename, err = writablePath(state, "touch", operand)
+		if err != nil {
+			return shellError("%s", err.Error())
+		}
+		if _, exists := state.WorkingDir[filename]; !exists {
+			writeFile(state, filename, "")
+		}
+	}
+	return CommandResult{
+		Success: true,
+		Message: "",
+	}
+}
+
+// removeFiles implements rm [-r] [-f] path...
+func removeFiles(args []string, state *GameState) CommandResult {
+	flags, operands := splitFlags(args)
+	recursive := flags['r'] || flags['R']
+	if len(operands) == 0 {
+		return shellError("rm: missing operand")
+	}
+
+	for _, operand := range operands {
+		// Deleting a linked worktree's directory leaves git a record to prune
+		if wt, exists := linkedWorktreeAt(state, operand); exists {
+			if !recursive {
+				return shellError("rm: cannot remove '%s': Is a directory", operand)
+			}
+			wt.Missing = true
+			wt.WorkingDir = make(map[string]FileState)
+			wt.StagingArea = make(map[string]FileState)
+			wt.Directories = make(map[string]bool)
+			continue
+		}
+
+		target, err := normalizePath(operand)
+		if err != nil || target == "." || target == ".git" || strings.HasPrefix(target, ".git/") {
+			return shellError("rm: refusing to remove '%s'", operand)
+		}
+		if _, isSubmodule := state.Submodules[target]; isSubmodule {
+			return shellError("rm: cannot remove '%s': Is a submodule (use 'git rm')", operand)
+		}
+		switch {
+		case isFile(state, target):
+			delete(state.WorkingDir, target)
+			keepParentDirs(state, target)
+		case isDirectory(state, target):
+			if !recursive {
+				return shellError("rm: cannot remove '%s': Is a directory", operand)
+			}
+			removeEntry(state, target+"/")
+			for dir := range state.Directories {
+				if dir == target || strings.HasPrefix(dir, target+"/") {
+					delete(state.Directories, dir)
+				}
+			}
+			keepParentDirs(state, target)
+		case !flags['f']:
+			return shellError("rm: cannot remove '%s': No such file or directory", operand)
+		}
+	}
+	return CommandResult{
+		Success: true,
+		Message: "",
+	}
+}
+
+// moveFile implements mv <source> <destination>, renaming a file or a whole
+// directory, or moving it into an existing directory
+func moveFile(args []string, state *GameState) CommandResult {
+	_, operands := splitFlags(args)
+	if len(operands) != 2 {
+		return shellError("usage: mv <source> <destination>")
+	}
+	source, err := normalizePath(operands[0])
+	if err != nil || (!isFile(state, source) && !isDirectory(state, source)) || source == "." {
+		return shellError("mv: cannot stat '%s': No such file or directory", operands[0])
+	}
+	if _, isSubmodule := state.Submodules[source]; isSubmodule {
+		return shellError("mv: cannot move '%s': Is a submodule (use 'git mv')", operands[0])
+	}
+	dest, err := normalizePath(operands[1])
+	if err != nil {
+		return shellError("%s", err.Error())
+	}
+	if isDirectory(state, dest) {
+		dest = path.Join(dest, path.Base(source))
+	}
+	if dest == source || strings.HasPrefix(dest, source+"/") {
+		return shellError("mv: cannot move '%s' to a subdirectory of itself", operands[0])
+	}
+	if !isDirectory(state, path.Dir(dest)) {
+		return shellError("mv: cannot move '%s' to '%s': No such file or directory", operands[0], operands[1])
+	}
+	if dest == ".git" || strings.HasPrefix(dest, ".git/") {
+		return shellError("mv: cannot move '%s' to '%s': Permission denied", operands[0], operands[1])
+	}
+
+	if isFile(state, source) {
+		if isDirectory(state, dest) {
+			return shellError("mv: cannot overwrite directory '%s' with non-directory", operands[1])
+		}
+		state.WorkingDir[dest] = state.WorkingDir[source]
+		delete(state.WorkingDir, source)
+	} else {
+		if isFile(state, dest) {
+			return shellError("mv: cannot overwrite non-directory '%s' with directory '%s'", operands[1], operands[0])
+		}
+		for _, filename := range sortedKeys(state.WorkingDir) {
+			if strings.HasPrefix(filename, source+"/") {
+				state.WorkingDir[dest+strings.TrimPrefix(filename, source)] = state.WorkingDir[filename]
+				delete(state.WorkingDir, filename)
+			}
+		}
+		for dir := range state.Directories {
+			if dir == source || strings.HasPrefix(dir, source+"/") {
+				delete(state.Directories, dir)
+				state.Directories[dest+strings.TrimPrefix(dir, source)] = true
+			}
+		}
+	}
+	keepParentDirs(state, source)
+	return CommandResult{
+		Success: true,
+		Message: "",
+	}
+}
+
+// makeDirs implements mkdir [-p] dir...
+func makeDirs(args []string, state *GameState) CommandResult {
+	flags, operands := splitFlags(args)
+	if len(operands) == 0 {
+		return shellError("mkdir: missing operand")
+	}
+
+	for _, operand := range operands {
+		dir, err := normalizePath(operand)
+		if err != nil || dir == ".git" || strings.HasPrefix(dir, ".git/") {
+			return shellError("mkdir: cannot create directory '%s': Permission denied", operand)
+		}
+		if isFile(state, dir) || (isDirectory(state, dir) && !flags['p']) {
+			return shellError("mkdir: cannot create directory '%s': File exists", operand)
+		}
+		if !isDirectory(state, path.Dir(dir)) && !flags['p'] {
+			return shellError("mkdir: cannot create directory '%s': No such file or directory", operand)
+		}
+		if state.Directories == nil {
+			state.Directories = make(map[string]bool)
+		}
+		for _, parent := range append(parentDirs(dir), dir) {
+			if isFile(state, parent) {
+				return shellError("mkdir: cannot create directory '%s': Not a directory", operand)
+			}
+			if !isDirectory(state, parent) {
+				state.Directories[parent] = true
+			}
+		}
+	}
+	return CommandResult{
+		Success: true,
+		Message: "",
+	}
+}
+
+// fileLines implements head and tail: [-n N | -N] file...
+func fileLines(cmd string, args []string, state *GameState) CommandResult {
+	count := 10
+	var files []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := ""
+		switch {
+		case arg == "-n" && i+1 < len(args):
+			i++
+			value = args[i]
+		case strings.HasPrefix(arg, "-n"):
+			value = arg[2:]
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			value = arg[1:]
+		default:
+			files = append(files, arg)
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return shellError("%s: invalid number of lines: '%s'", cmd, value)
+		}
+		count = n
+	}
+	if len(files) == 0 {
+		return shellError("usage: %s [-n lines] <file>...", cmd)
+	}
+
+	var sections []string
+	for _, name := range files {
+		result := catFiles([]string{name}, state)
+		if !result.Success {
+			return shellError("%s", strings.Replace(result.Message, "cat:", cmd+":", 1))
+		}
+		lines := strings.Split(strings.TrimSuffix(result.Message, "\n"), "\n")
+		if result.Message == "" {
+			lines = nil
+		}
+		if len(lines) > count {
+			if cmd == "head" {
+				lines = lines[:count]
+			} else {
+				lines = lines[len(lines)-count:]
+			}
+		}
+		section := strings.Join(lines, "\n")
+		if len(files) > 1 {
+			section = fmt.Sprintf("==> %s <==\n%s", name, section)
+		}
+		sections = append(sections, section)
+	}
+	return CommandResult{
+		Success: true,
+		Message: strings.Join(sections, "\n\n"),
+	}
+}
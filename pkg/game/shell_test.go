@@ -0,0 +1,108 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShellBuiltinsEditFiles(t *testing.T) {
+	engine := NewEngine()
+	engine.ProcessCommand("git init")
+
+	steps := []struct {
+		input   string
+		success bool
+		message string
+	}{
+		{"echo hello anomaly", true, "hello anomaly"},
+		{"echo 'first line' > notes.txt", true, ""},
+		{"echo second line >> notes.txt", true, ""},
+		{"cat notes.txt", true, "first line\nsecond line\n"},
+		{"echo lost > logs/today.txt", false, "echo: logs/today.txt: No such file or directory"},
+		{"mkdir logs", true, ""},
+		{"mkdir logs", false, "mkdir: cannot create directory 'logs': File exists"},
+		{"mkdir -p reports/keter", true, ""},
+		{"touch logs/today.txt", true, ""},
+		{"ls", true, "logs/  notes.txt  reports/"},
+		{"ls reports", true, "keter/"},
+		{"ls missing", false, "ls: cannot access 'missing': No such file or directory"},
+		{"cat logs", false, "cat: logs: Is a directory"},
+		{"mv notes.txt logs", true, ""},
+		{"ls logs", true, "notes.txt  today.txt"},
+		{"rm logs", false, "rm: cannot remove 'logs': Is a directory"},
+		{"rm logs/today.txt", true, ""},
+		{"rm logs/today.txt", false, "rm: cannot remove 'logs/today.txt': No such file or directory"},
+		{"rm -f logs/today.txt", true, ""},
+		{"mv logs archive", true, ""},
+		{"ls", true, "archive/  reports/"},
+		{"rm -r reports", true, ""},
+		{"ls -a", true, "./  ../  .git/  archive/"},
+		{"echo x > .git/config", false, "echo: .git/config: Permission denied (use 'edit .git/hooks/<hook>' for hooks)"},
+	}
+	for _, step := range steps {
+		result := engine.ProcessCommand(step.input)
+		if result.Success != step.success || result.Message != step.message {
+			t.Errorf("%s: got (%v, %q), want (%v, %q)", step.input, result.Success, result.Message, step.success, step.message)
+		}
+	}
+
+	if _, exists := engine.State.WorkingDir["archive/notes.txt"]; !exists {
+		t.Error("Moving a directory should move the files inside it")
+	}
+	result := engine.ProcessCommand("git status")
+	if !strings.Contains(result.Message, "archive/") {
+		t.Errorf("Files made by the shell should show up as untracked, got %q", result.Message)
+	}
+}
+
+func TestShellHeadTailAndLongListing(t *testing.T) {
+	state := NewGameState()
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, strings.Repeat("#", i))
+	}
+	writeFile(state, "log.txt", strings.Join(lines, "\n")+"\n")
+
+	if result := ShellBuiltins["head"]([]string{"-n", "2", "log.txt"}, state); result.Message != "#\n##" {
+		t.Errorf("head -n 2 gave %q", result.Message)
+	}
+	if result := ShellBuiltins["tail"]([]string{"-1", "log.txt"}, state); result.Message != strings.Repeat("#", 12) {
+		t.Errorf("tail -1 gave %q", result.Message)
+	}
+	if result := ShellBuiltins["head"]([]string{"log.txt"}, state); strings.Count(result.Message, "\n") != 9 {
+		t.Errorf("head should default to 10 lines, got %q", result.Message)
+	}
+	if result := ShellBuiltins["tail"]([]string{"-n", "x", "log.txt"}, state); result.Success {
+		t.Error("An invalid line count should fail")
+	}
+
+	result := ShellBuiltins["ls"]([]string{"-la", "log.txt"}, state)
+	if result.Message != "-rw-r--r-- 1 researcher foundation     90 log.txt" {
+		t.Errorf("Unexpected long listing %q", result.Message)
+	}
+}
+
+func TestRemovingWorktreeDirectoryMakesItPrunable(t *testing.T) {
+	engine := NewEngine()
+	for _, command := range []string{"git init", "touch report.txt", "git add report.txt", "git commit -m 'Report'", "git worktree add ../audit"} {
+		engine.ProcessCommand(command)
+	}
+
+	if result := engine.ProcessCommand("rm ../audit"); result.Success {
+		t.Error("A worktree directory should need rm -r")
+	}
+	if result := engine.ProcessCommand("rm -r ../audit"); !result.Success {
+		t.Fatalf("rm -r should delete the worktree's directory: %s", result.Message)
+	}
+	if result := engine.ProcessCommand("git worktree list"); !strings.Contains(result.Message, "../audit") || !strings.Contains(result.Message, "prunable") {
+		t.Errorf("The deleted worktree should be listed as prunable, got %q", result.Message)
+	}
+	if result := engine.ProcessCommand("cd ../audit"); result.Success {
+		t.Error("A deleted worktree cannot be entered")
+	}
+
+	result := engine.ProcessCommand("git worktree prune")
+	if !strings.Contains(result.Message, "Removing worktrees/audit") || len(engine.State.Worktrees) != 0 {
+		t.Errorf("prune should forget the deleted worktree, got %q", result.Message)
+	}
+}
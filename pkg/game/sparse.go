@@ -0,0 +1,205 @@
+package game
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// SparseCheckout limits which directories of the tree are materialized in
+// a worktree. In cone mode the files at the top level are always present,
+// along with everything under each listed directory and the files directly
+// inside its parent directories.
+type SparseCheckout struct {
+	Dirs []string
+}
+
+// includes reports whether a tracked file belongs in a sparse worktree. A nil
+// sparse checkout includes everything.
+func (sc *SparseCheckout) includes(filename string) bool {
+	if sc == nil {
+		return true
+	}
+	dir := path.Dir(filename)
+	if dir == "." {
+		return true
+	}
+	for _, cone := range sc.Dirs {
+		if dir == cone || strings.HasPrefix(dir, cone+"/") || strings.HasPrefix(cone, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSparseDirs cleans cone directories, rejecting patterns
+func normalizeSparseDirs(args []string) ([]string, error) {
+	var dirs []string
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[!") {
+			return nil, fmt.Errorf("fatal: '%s' is not a directory; cone mode only accepts directories", arg)
+		}
+		dir := strings.Trim(path.Clean("/"+arg), "/")
+		if dir == "" {
+			continue
+		}
+		if !containsString(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// applySparseCheckout brings the working directory in line with the sparse
+// definition: files outside it are removed and files inside it restored from
+// HEAD. Files with local changes are never removed; their names are returned.
+func applySparseCheckout(state *GameState) []string {
+	head := headCommitID(state)
+	if head == "" {
+		return nil
+	}
+
+	var kept []string
+	tree := treeAt(state, head)
+	for filename, hash := range tree {
+		file, present := state.WorkingDir[filename]
+		switch {
+		case state.Sparse.includes(filename) && !present:
+			state.WorkingDir[filename] = FileState{Content: state.Objects[hash], Hash: hash}
+		case !state.Sparse.includes(filename) && present:
+			if _, staged := state.StagingArea[filename]; staged || file.Hash != hash {
+				kept = append(kept, filename)
+				continue
+			}
+			delete(state.WorkingDir, filename)
+		}
+	}
+	sort.Strings(kept)
+	return kept
+}
+
+// sparseSummary is the line git status prints inside a sparse checkout
+func sparseSummary(state *GameState) string {
+	head := headCommitID(state)
+	if state.Sparse == nil || head == "" {
+		return ""
+	}
+	tree := treeAt(state, head)
+	present := 0
+	for filename := range tree {
+		if _, exists := state.WorkingDir[filename]; exists {
+			present++
+		}
+	}
+	return fmt.Sprintf("You are in a sparse checkout with %d%% of tracked files present.\n", present*100/len(tree))
+}
+
+// SparseCheckoutCommand implements git sparse-checkout in cone mode
+type SparseCheckoutCommand struct{}
+
+func (c *SparseCheckoutCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+	if len(args) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git sparse-checkout (init | list | set | add | disable) [<directories>...]",
+			SCPEffect: "⚠️  WARNING: Specify a sector exposure operation",
+		}
+	}
+
+	subcommand, rest := args[0], args[1:]
+	var dirArgs []string
+	for _, arg := range rest {
+		if arg != "--cone" {
+			dirArgs = append(dirArgs, arg)
+		}
+	}
+	dirs, err := normalizeSparseDirs(dirArgs)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: Sector designations must be directories",
+			AnomalyDelta: 1,
+		}
+	}
+
+	switch subcommand {
+	case "list":
+		if state.Sparse == nil {
+			return CommandResult{
+				Success: false,
+				Message: "fatal: this worktree is not sparse",
+			}
+		}
+		return CommandResult{
+			Success:   true,
+			Message:   strings.Join(state.Sparse.Dirs, "\n"),
+			SCPEffect: fmt.Sprintf("📋 %d facility sectors exposed", len(state.Sparse.Dirs)),
+		}
+	case "init":
+		if state.Sparse == nil {
+			state.Sparse = &SparseCheckout{}
+		}
+	case "set":
+		state.Sparse = &SparseCheckout{Dirs: dirs}
+	case "add":
+		if state.Sparse == nil {
+			return CommandResult{
+				Success:      false,
+				Message:      "fatal: no sparse-checkout to add to",
+				SCPEffect:    "⚠️  WARNING: Run 'git sparse-checkout set' first",
+				AnomalyDelta: 1,
+			}
+		}
+		for _, dir := range dirs {
+			if !containsString(state.Sparse.Dirs, dir) {
+				state.Sparse.Dirs = append(state.Sparse.Dirs, dir)
+			}
+		}
+	case "disable":
+		state.Sparse = nil
+	default:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: unknown subcommand: `%s'", subcommand),
+			SCPEffect:    "🔴 ERROR: Unknown sector exposure operation",
+			AnomalyDelta: 1,
+		}
+	}
+
+	if state.Sparse != nil {
+		sort.Strings(state.Sparse.Dirs)
+	}
+	kept := applySparseCheckout(state)
+
+	result := CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: "🗺️  Full facility exposed in the working tree",
+	}
+	if state.Sparse != nil {
+		result.SCPEffect = fmt.Sprintf("🗺️  Exposure limited to %d facility sectors (plus top-level files)", len(state.Sparse.Dirs))
+	}
+	if len(kept) > 0 {
+		result.Message = "warning: The following paths are not up to date and were left despite sparse patterns:\n\t" + strings.Join(kept, "\n\t")
+		result.SCPEffect = "⚠️  WARNING: Modified files remain exposed outside the permitted sectors"
+	}
+	return result
+}
+
+func (c *SparseCheckoutCommand) Help() string {
+	return "Limit the working tree to chosen directories"
+}
+
+func (c *SparseCheckoutCommand) RequiredArgs() int {
+	return 1
+}
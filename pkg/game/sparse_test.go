@@ -0,0 +1,59 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSparseCheckoutCone(t *testing.T) {
+	state := newReportTestState(t)
+	commitFile(t, state, "sectors/safe/log.txt", "calm", "Safe sector log")
+	commitFile(t, state, "sectors/readme.txt", "index", "Sector index")
+	commitFile(t, state, "sectors/keter/entity.txt", "AWAKE", "Keter sector log")
+	cmd := &SparseCheckoutCommand{}
+
+	if result := cmd.Execute([]string{"list"}, state); result.Success {
+		t.Error("list should fail outside a sparse checkout")
+	}
+	if result := cmd.Execute([]string{"set", "sectors/safe/"}, state); !result.Success {
+		t.Fatalf("set failed: %s", result.Message)
+	}
+	for _, name := range []string{"a.txt", "sectors/readme.txt", "sectors/safe/log.txt"} {
+		if _, present := state.WorkingDir[name]; !present {
+			t.Errorf("%s should stay in the cone", name)
+		}
+	}
+	if _, present := state.WorkingDir["sectors/keter/entity.txt"]; present {
+		t.Error("Files outside the cone should be removed")
+	}
+	if result := cmd.Execute([]string{"list"}, state); result.Message != "sectors/safe" {
+		t.Errorf("Unexpected cone %q", result.Message)
+	}
+
+	result := (&StatusCommand{}).Execute(nil, state)
+	if !strings.Contains(result.Message, "sparse checkout with 80% of tracked files present") ||
+		!strings.Contains(result.Message, "working tree clean") {
+		t.Errorf("Status should report the sparse checkout as clean, got %q", result.Message)
+	}
+
+	if result = cmd.Execute([]string{"set", "sectors/*"}, state); result.Success {
+		t.Error("Cone mode should reject patterns")
+	}
+
+	cmd.Execute([]string{"add", "sectors/keter"}, state)
+	if _, present := state.WorkingDir["sectors/keter/entity.txt"]; !present {
+		t.Error("add should restore the new directory from HEAD")
+	}
+
+	// Modified files are never thrown away
+	state.WorkingDir["sectors/keter/entity.txt"] = FileState{Content: "notes", Hash: hashContent("notes")}
+	result = cmd.Execute([]string{"set", "sectors/safe"}, state)
+	if !strings.Contains(result.Message, "sectors/keter/entity.txt") {
+		t.Errorf("A modified file outside the cone should be kept and reported, got %q", result.Message)
+	}
+
+	cmd.Execute([]string{"disable"}, state)
+	if state.Sparse != nil || len(state.WorkingDir) != 5 {
+		t.Errorf("disable should restore the full tree, have %d files", len(state.WorkingDir))
+	}
+}
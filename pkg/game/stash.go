@@ -0,0 +1,283 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StashEntry is uncommitted work set aside with git stash
+type StashEntry struct {
+	Message string
+	Branch  string
+	Base    string            // commit the work was based on
+	Working map[string]string // file -> stashed working copy
+	Staged  map[string]string // file -> content that was staged
+}
+
+// StashCommand implements git stash: push, list, show, apply, pop, drop and clear.
+// Untracked files are left where they are.
+type StashCommand struct{}
+
+func (c *StashCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	subcommand := "push"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand, args = args[0], args[1:]
+	}
+
+	switch subcommand {
+	case "push", "save":
+		return c.push(args, state)
+	case "list":
+		return c.list(state)
+	case "show":
+		return c.show(args, state)
+	case "apply", "pop":
+		return c.apply(args, state, subcommand == "pop")
+	case "drop":
+		return c.drop(args, state)
+	case "clear":
+		state.Stash = nil
+		return CommandResult{
+			Success:   true,
+			SCPEffect: "⚠️  Every quarantined record was destroyed",
+		}
+	default:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: unknown subcommand: `%s'\nusage: git stash [push [-m <message>] | list | show | apply | pop | drop | clear] [<stash>]", subcommand),
+			SCPEffect:    "🔴 ERROR: Unknown quarantine procedure",
+			AnomalyDelta: 1,
+		}
+	}
+}
+
+// stashDescription is how an entry is described when no message is given:
+// "WIP on main: 1a2b3c4 Subject"
+func stashDescription(state *GameState, message string) string {
+	if message != "" {
+		return fmt.Sprintf("On %s: %s", state.CurrentBranch, message)
+	}
+	head := headCommitID(state)
+	if head == "" {
+		return fmt.Sprintf("WIP on %s: (no commits)", state.CurrentBranch)
+	}
+	commit, _ := state.findCommit(head)
+	return fmt.Sprintf("WIP on %s: %s %s", state.CurrentBranch, head[:7], strings.SplitN(commit.Message, "\n", 2)[0])
+}
+
+func (c *StashCommand) push(args []string, state *GameState) CommandResult {
+	message := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-m", "--message":
+			if i+1 >= len(args) {
+				return CommandResult{
+					Success:      false,
+					Message:      "error: switch `m' requires a value",
+					SCPEffect:    "⚠️  WARNING: Quarantine label missing",
+					AnomalyDelta: 1,
+				}
+			}
+			i++
+			message = args[i]
+		default:
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("error: unknown option `%s'", args[i]),
+				SCPEffect:    "🔴 ERROR: Unknown quarantine procedure",
+				AnomalyDelta: 1,
+			}
+		}
+	}
+
+	// Tracked files that differ from HEAD, and everything staged
+	tree := make(map[string]string)
+	if head := headCommitID(state); head != "" {
+		tree = treeAt(state, head)
+	}
+	entry := StashEntry{
+		Message: stashDescription(state, message),
+		Branch:  state.CurrentBranch,
+		Base:    headCommitID(state),
+		Working: make(map[string]string),
+		Staged:  make(map[string]string),
+	}
+	for filename, file := range state.StagingArea {
+		entry.Staged[filename] = file.Content
+	}
+	for filename, file := range state.WorkingDir {
+		_, staged := entry.Staged[filename]
+		if hash, tracked := tree[filename]; (tracked && hash != file.Hash) || staged {
+			entry.Working[filename] = file.Content
+		}
+	}
+	if len(entry.Working) == 0 && len(entry.Staged) == 0 {
+		return CommandResult{
+			Success: true,
+			Message: "No local changes to save",
+		}
+	}
+
+	// Put the stashed files back the way HEAD has them
+	for filename := range entry.Working {
+		if hash, tracked := tree[filename]; tracked {
+			content := state.Objects[hash]
+			state.WorkingDir[filename] = FileState{Content: content, Hash: hash}
+		} else {
+			delete(state.WorkingDir, filename)
+		}
+	}
+	state.StagingArea = make(map[string]FileState)
+	state.Stash = append([]StashEntry{entry}, state.Stash...)
+
+	return CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Saved working directory and index state %s", entry.Message),
+		SCPEffect: fmt.Sprintf("📦 %d records moved to quarantine (stash@{0})", len(entry.Working)),
+	}
+}
+
+// stashIndex resolves "stash@{N}" or "N", defaulting to the newest entry
+func stashIndex(args []string, state *GameState) (int, CommandResult, bool) {
+	ref := "stash@{0}"
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			ref = arg
+		}
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(ref, "stash@{"), "}"))
+	if len(state.Stash) == 0 {
+		return 0, CommandResult{
+			Success:      false,
+			Message:      "No stash entries found.",
+			SCPEffect:    "⚠️  The quarantine is empty",
+			AnomalyDelta: 1,
+		}, false
+	}
+	if err != nil || n < 0 || n >= len(state.Stash) {
+		return 0, CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: %s is not a valid reference", ref),
+			SCPEffect:    "⚠️  No such quarantined record",
+			AnomalyDelta: 1,
+		}, false
+	}
+	return n, CommandResult{}, true
+}
+
+func (c *StashCommand) list(state *GameState) CommandResult {
+	var lines []string
+	for i, entry := range state.Stash {
+		lines = append(lines, fmt.Sprintf("stash@{%d}: %s", i, entry.Message))
+	}
+	return CommandResult{
+		Success: true,
+		Message: strings.Join(lines, "\n"),
+	}
+}
+
+func (c *StashCommand) show(args []string, state *GameState) CommandResult {
+	n, failure, ok := stashIndex(args, state)
+	if !ok {
+		return failure
+	}
+	entry := state.Stash[n]
+	files := make([]string, 0, len(entry.Working))
+	for filename := range entry.Working {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+
+	var lines []string
+	for _, filename := range files {
+		lines = append(lines, " "+filename)
+	}
+	lines = append(lines, fmt.Sprintf(" %d files changed", len(files)))
+	return CommandResult{
+		Success: true,
+		Message: strings.Join(lines, "\n"),
+	}
+}
+
+func (c *StashCommand) apply(args []string, state *GameState, pop bool) CommandResult {
+	n, failure, ok := stashIndex(args, state)
+	if !ok {
+		return failure
+	}
+	entry := state.Stash[n]
+	restoreIndex := containsString(args, "--index")
+
+	// Refuse to overwrite work that is not committed
+	tree := make(map[string]string)
+	if head := headCommitID(state); head != "" {
+		tree = treeAt(state, head)
+	}
+	var conflicts []string
+	for filename := range entry.Working {
+		if file, exists := state.WorkingDir[filename]; exists && tree[filename] != file.Hash && file.Content != entry.Working[filename] {
+			conflicts = append(conflicts, filename)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: Your local changes to the following files would be overwritten by merge:\n\t%s\nPlease commit your changes or stash them before you merge.\nAborting", strings.Join(conflicts, "\n\t")),
+			SCPEffect:    "⚠️  Quarantined records collide with uncommitted work",
+			AnomalyDelta: 1,
+		}
+	}
+
+	for filename, content := range entry.Working {
+		hash := state.storeObject(content)
+		state.WorkingDir[filename] = FileState{Content: content, Hash: hash, Modified: tree[filename] != hash}
+	}
+	if restoreIndex {
+		for filename, content := range entry.Staged {
+			state.StagingArea[filename] = FileState{Content: content, Hash: state.storeObject(content), Staged: true}
+		}
+	}
+
+	result := CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Restored %d files from stash@{%d}", len(entry.Working), n),
+		SCPEffect: "📦 Quarantined records released into the containment area",
+	}
+	if pop {
+		state.Stash = append(state.Stash[:n:n], state.Stash[n+1:]...)
+		result.Message += fmt.Sprintf("\nDropped stash@{%d}", n)
+	}
+	return result
+}
+
+func (c *StashCommand) drop(args []string, state *GameState) CommandResult {
+	n, failure, ok := stashIndex(args, state)
+	if !ok {
+		return failure
+	}
+	state.Stash = append(state.Stash[:n:n], state.Stash[n+1:]...)
+	return CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Dropped stash@{%d}", n),
+		SCPEffect: "⚠️  A quarantined record was destroyed",
+	}
+}
+
+func (c *StashCommand) Help() string {
+	return "Set uncommitted work aside and restore it later"
+}
+
+func (c *StashCommand) RequiredArgs() int {
+	return 0
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStashPushAndPop(t *testing.T) {
+	state := newReportTestState(t)
+	writeFile(state, "a.txt", "3")
+	state.StagingArea["b.txt"] = FileState{Content: "2", Hash: state.storeObject("2"), Staged: true}
+	writeFile(state, "b.txt", "2")
+	writeFile(state, "untracked.txt", "stays")
+
+	cmd := &StashCommand{}
+	result := cmd.Execute([]string{"push", "-m", "half-done"}, state)
+	if !result.Success || !strings.Contains(result.Message, "On main: half-done") {
+		t.Fatalf("Unexpected push result %+v", result)
+	}
+	if state.WorkingDir["a.txt"].Content != "2" || len(state.StagingArea) != 0 {
+		t.Error("Stashing should restore HEAD's files and clear the index")
+	}
+	if _, exists := state.WorkingDir["untracked.txt"]; !exists {
+		t.Error("Untracked files should not be stashed")
+	}
+	if list := cmd.Execute([]string{"list"}, state); list.Message != "stash@{0}: On main: half-done" {
+		t.Errorf("Unexpected list %q", list.Message)
+	}
+
+	writeFile(state, "a.txt", "conflicting")
+	if result := cmd.Execute([]string{"pop"}, state); result.Success {
+		t.Error("Popping over uncommitted changes should fail")
+	}
+	writeFile(state, "a.txt", "2")
+
+	result = cmd.Execute([]string{"pop", "--index"}, state)
+	if !result.Success || len(state.Stash) != 0 {
+		t.Fatalf("Pop should apply and drop the entry, got %+v", result)
+	}
+	if !state.WorkingDir["a.txt"].Modified || state.WorkingDir["a.txt"].Content != "3" {
+		t.Error("The stashed working copy should be restored")
+	}
+	if _, staged := state.StagingArea["b.txt"]; !staged {
+		t.Error("--index should restore the staged files")
+	}
+	if result := cmd.Execute([]string{"drop"}, state); result.Success || result.Message != "No stash entries found." {
+		t.Errorf("Dropping from an empty stash should fail, got %q", result.Message)
+	}
+}
@@ -11,27 +11,88 @@ type GameState struct {
 	IsInitialized bool
 	CurrentBranch string
 	Branches      map[string][]string // branch -> commit IDs
+	Detached      []string            // commits of a detached HEAD, while CurrentBranch is DetachedHead
 
-	// Working directory and staging
+	// Working directory and staging of the active worktree
 	WorkingDir  map[string]FileState
 	StagingArea map[string]FileState
+	Sparse      *SparseCheckout // nil when every tracked file is present
+	Directories map[string]bool // empty directories made with mkdir; git never sees them
+
+	// Linked worktrees other than the active one
+	Worktrees       map[string]*Worktree // path -> parked worktree
+	CurrentWorktree string               // path of the active worktree
 
 	// Commits and history
 	Commits     []Commit
 	CommitGraph map[string][]string // commit -> parents
 
-	// Git config
+	// Object store, and the objects git gc has packed
+	Objects map[string]string // hash -> content
+	Packed  map[string]bool   // commit ID or blob hash -> packed
+
+	// Movements of HEAD, newest last
+	Reflog []ReflogEntry
+
+	// Tags marking notable commits
+	Tags map[string]Tag // tag name -> tag
+
+	// Notes attached to commits without rewriting them
+	Notes map[string]string // commit ID -> note text
+
+	// Work set aside with git stash, newest first (stash@{0})
+	Stash []StashEntry
+
+	// A merge stopped on conflicts; nil when none is in progress
+	Merge *MergeState
+
+	// A git am stopped on a patch that did not apply; nil when none is in progress
+	Am *AmSession
+
+	// Hook scripts run by commit and push
+	Hooks map[string]string // hook name -> rule script
+
+	// Repositories nested in the working tree, and the standalone ones on
+	// the Foundation network they are cloned from
+	Submodules   map[string]*Submodule // path -> submodule
+	Repositories map[string]*GameState // URL -> hosted repository
+
+	// Remotes and remote-tracking branches
+	Remotes        map[string]*Remote
+	RemoteBranches map[string][]string // "origin/main" -> commit IDs
+	Upstreams      map[string]string   // local branch -> "origin/main"
+
+	// History rewrites of commits other researchers already have
+	PublishedRewrites int
+
+	// Git config by scope, and the effective identity taken from it
+	Config      map[string]map[string][]string // scope -> key -> values
 	ConfigName  string
 	ConfigEmail string
 
 	// SCP-specific state
 	AnomalyLevel      int    // 0-100, increases with mistakes
 	ContainmentStatus string // "SECURE", "BREACH", "CRITICAL"
+	Sanity            int    // 0-100, the researcher collapses at 0
+	ErrorStreak       int    // failed commands in a row
+
+	// What the entity has learned by watching; kept across levels
+	Awareness       int             // 0-100, see AwarenessStages
+	LearnedFeatures map[string]bool // git features it has seen, e.g. "commit --amend"
 
 	// Progress tracking
 	CurrentLevel    int
 	CompletedLevels []int
 	Score           int
+
+	// Scripted actor bookkeeping
+	CommandCount   int            // git commands processed this level
+	ActorProgress  map[string]int // actor name -> commits already pushed
+	EntityProgress map[string]int // entity behavior name -> actions already taken
+
+	// Commands run this level, oldest first, and the objectives already scored
+	CommandHistory    []CommandRecord
+	AwardedObjectives map[string]bool // objective description -> points awarded
 }
 
 // FileState represents the state of a file in the working directory or staging area
@@ -60,13 +121,31 @@ func NewGameState() *GameState {
 		Branches:          make(map[string][]string),
 		WorkingDir:        make(map[string]FileState),
 		StagingArea:       make(map[string]FileState),
+		Directories:       make(map[string]bool),
+		Worktrees:         make(map[string]*Worktree),
+		CurrentWorktree:   MainWorktreePath,
 		Commits:           []Commit{},
 		CommitGraph:       make(map[string][]string),
+		Objects:           make(map[string]string),
+		Packed:            make(map[string]bool),
+		Tags:              make(map[string]Tag),
+		Notes:             make(map[string]string),
+		Hooks:             make(map[string]string),
+		Config:            make(map[string]map[string][]string),
+		Submodules:        make(map[string]*Submodule),
+		Repositories:      make(map[string]*GameState),
+		Remotes:           make(map[string]*Remote),
+		RemoteBranches:    make(map[string][]string),
+		Upstreams:         make(map[string]string),
 		AnomalyLevel:      0,
 		ContainmentStatus: "SECURE",
+		Sanity:            MaxSanity,
 		CurrentLevel:      1,
 		CompletedLevels:   []int{},
 		Score:             0,
+		ActorProgress:     make(map[string]int),
+		EntityProgress:    make(map[string]int),
+		LearnedFeatures:   make(map[string]bool),
 	}
 }
 
@@ -94,3 +173,37 @@ func (gs *GameState) IncreaseAnomaly(delta int) {
 	}
 	gs.UpdateContainmentStatus()
 }
+
+// AdjustSanity changes researcher sanity, keeping it between 0 and MaxSanity
+func (gs *GameState) AdjustSanity(delta int) {
+	gs.Sanity += delta
+	if gs.Sanity < 0 {
+		gs.Sanity = 0
+	}
+	if gs.Sanity > MaxSanity {
+		gs.Sanity = MaxSanity
+	}
+}
+
+// storeObject records content in the object store and returns its hash
+func (gs *GameState) storeObject(content string) string {
+	hash := hashContent(content)
+	gs.Objects[hash] = content
+	return hash
+}
+
+// findCommit returns the commit with the given ID
+func (gs *GameState) findCommit(id string) (Commit, bool) {
+	for _, commit := range gs.Commits {
+		if commit.ID == id {
+			return commit, true
+		}
+	}
+	return Commit{}, false
+}
+
+// hasCommit reports whether the commit ID is known to the local repository
+func (gs *GameState) hasCommit(id string) bool {
+	_, found := gs.findCommit(id)
+	return found
+}
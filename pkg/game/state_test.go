@@ -19,7 +19,9 @@ func TestNewGameState(t *testing.T) {
 		t.Errorf("Expected anomaly level 0, got %d", state.AnomalyLevel)
 	}
 
-	// Sanity system removed in v0.3.0
+	if state.Sanity != MaxSanity {
+		t.Errorf("Expected sanity %d, got %d", MaxSanity, state.Sanity)
+	}
 
 	if state.ContainmentStatus != "SECURE" {
 		t.Errorf("Expected containment status SECURE, got %s", state.ContainmentStatus)
@@ -69,4 +71,19 @@ func TestIncreaseAnomaly(t *testing.T) {
 	}
 }
 
-// TestIncreaseSanity removed - sanity system deprecated in v0.3.0
+func TestAdjustSanity(t *testing.T) {
+	state := NewGameState()
+
+	state.AdjustSanity(-30)
+	if state.Sanity != 70 {
+		t.Errorf("Expected sanity 70, got %d", state.Sanity)
+	}
+	state.AdjustSanity(50)
+	if state.Sanity != MaxSanity {
+		t.Errorf("Sanity should be capped at %d, got %d", MaxSanity, state.Sanity)
+	}
+	state.AdjustSanity(-200)
+	if state.Sanity != 0 {
+		t.Errorf("Sanity should not drop below 0, got %d", state.Sanity)
+	}
+}
@@ -0,0 +1,627 @@
+package game
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SubEntityURL is the archive holding the contained sub-entity's own history
+const SubEntityURL = "foundation://site-19/archive/scp-████-1.git"
+
+// DetachedHead is the CurrentBranch of a repository after checking out a
+// commit directly, as git submodule update does. Its history is kept in
+// GameState.Detached and is reachable only until the next checkout.
+const DetachedHead = "HEAD"
+
+// GitmodulesFile lists the submodules of a repository
+const GitmodulesFile = ".gitmodules"
+
+// hostedHistories seeds the repositories on the Foundation network that can
+// be added as submodules
+var hostedHistories = map[string][]ActorCommit{
+	SubEntityURL: {
+		{Message: "Isolate sub-entity", Files: map[string]string{
+			"entity.txt": "SCP-████-1: fragment recovered from the primary anomaly\nStatus: dormant\n",
+		}},
+		{Message: "Document containment cell", Files: map[string]string{
+			"cell.txt": "Cell 7, Sector B\nAccess: Level 3 clearance\n",
+		}},
+	},
+}
+
+// Submodule is a repository nested in the working tree, recorded in the
+// parent as a gitlink: a path whose content names the commit to check out
+type Submodule struct {
+	Name string
+	Path string
+	URL  string
+	Repo *GameState
+}
+
+// gitmodulesEntry is one [submodule] section of .gitmodules
+type gitmodulesEntry struct {
+	Name string
+	Path string
+	URL  string
+}
+
+var gitmodulesSection = regexp.MustCompile(`^\[submodule "(.+)"\]$`)
+
+// parseGitmodules reads the submodule sections of a .gitmodules file
+func parseGitmodules(content string) []gitmodulesEntry {
+	var entries []gitmodulesEntry
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if m := gitmodulesSection.FindStringSubmatch(line); m != nil {
+			entries = append(entries, gitmodulesEntry{Name: m[1], Path: m[1]})
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || len(entries) == 0 {
+			continue
+		}
+		entry := &entries[len(entries)-1]
+		switch strings.TrimSpace(key) {
+		case "path":
+			entry.Path = strings.TrimSpace(value)
+		case "url":
+			entry.URL = strings.TrimSpace(value)
+		}
+	}
+	return entries
+}
+
+// gitlinkContent is how the parent records the commit a submodule is at
+func gitlinkContent(id string) string {
+	return "Subproject commit " + id + "\n"
+}
+
+// gitlinkCommit returns the commit the parent's index records for a submodule
+func gitlinkCommit(state *GameState, subPath string) string {
+	content, _, tracked := indexVersion(state, subPath)
+	if !tracked {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(content, "Subproject commit "))
+}
+
+// syncGitlinks points each submodule's gitlink in the working tree at the
+// commit its repository has checked out, so status and diff see new commits
+func syncGitlinks(state *GameState) {
+	for subPath, sub := range state.Submodules {
+		head := headCommitID(sub.Repo)
+		if head == "" {
+			continue
+		}
+		content := gitlinkContent(head)
+		if state.WorkingDir[subPath].Content != content {
+			state.WorkingDir[subPath] = FileState{Content: content, Hash: hashContent(content)}
+		}
+	}
+}
+
+// submoduleChanges describes what changed in a modified submodule for git
+// status, e.g. " (new commits, modified content)"
+func submoduleChanges(state *GameState, subPath string) string {
+	sub, exists := state.Submodules[subPath]
+	if !exists {
+		return ""
+	}
+	var changes []string
+	if headCommitID(sub.Repo) != gitlinkCommit(state, subPath) {
+		changes = append(changes, "new commits")
+	}
+	if repositoryDirty(sub.Repo) {
+		changes = append(changes, "modified content")
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(changes, ", ") + ")"
+}
+
+// repositoryDirty reports whether a repository, such as a submodule, has
+// uncommitted changes in its active worktree
+func repositoryDirty(repo *GameState) bool {
+	return worktreeDirty(repo, &Worktree{Branch: repo.CurrentBranch, Detached: repo.Detached, WorkingDir: repo.WorkingDir, StagingArea: repo.StagingArea, Sparse: repo.Sparse})
+}
+
+// hostedRepository returns the repository published at url, materializing
+// the built-in ones on first use
+func hostedRepository(state *GameState, url string) (*GameState, bool) {
+	if repo, exists := state.Repositories[url]; exists {
+		return repo, true
+	}
+	history, exists := hostedHistories[url]
+	if !exists {
+		return nil, false
+	}
+
+	repo := NewGameState()
+	repo.IsInitialized = true
+	repo.CurrentBranch = "main"
+	repo.Branches["main"] = []string{}
+	for _, scripted := range history {
+		commit := Commit{
+			ID:        generateCommitID(),
+			Message:   scripted.Message,
+			Author:    "Dr. ████████",
+			Timestamp: time.Now(),
+			Files:     make(map[string]string),
+			Branch:    "main",
+		}
+		for filename, content := range scripted.Files {
+			commit.Files[filename] = repo.storeObject(content)
+		}
+		repo.Commits = append(repo.Commits, commit)
+		repo.Branches["main"] = append(repo.Branches["main"], commit.ID)
+	}
+	repo.WorkingDir = checkoutTree(repo, headCommitID(repo))
+	state.Repositories[url] = repo
+	return repo, true
+}
+
+// fetchHosted copies the commits and objects of a hosted repository into a
+// clone and updates its remote-tracking branches
+func fetchHosted(clone, source *GameState) {
+	for _, commit := range source.Commits {
+		if !clone.hasCommit(commit.ID) {
+			clone.Commits = append(clone.Commits, commit)
+		}
+	}
+	for hash, content := range source.Objects {
+		clone.Objects[hash] = content
+	}
+	for name, commits := range source.Branches {
+		clone.RemoteBranches["origin/"+name] = append([]string{}, commits...)
+	}
+}
+
+// cloneSubmodule creates the nested repository for a submodule, checked out
+// on the hosted repository's default branch
+func cloneSubmodule(state *GameState, entry gitmodulesEntry) (*Submodule, error) {
+	source, exists := hostedRepository(state, entry.URL)
+	if !exists {
+		return nil, fmt.Errorf("fatal: repository '%s' does not exist\nfatal: clone of '%s' into submodule path '%s' failed", entry.URL, entry.URL, entry.Path)
+	}
+
+	repo := NewGameState()
+	repo.IsInitialized = true
+	repo.CurrentWorktree = path.Join(state.CurrentWorktree, entry.Path)
+	repo.Remotes["origin"] = NewRemote("origin", entry.URL)
+	fetchHosted(repo, source)
+
+	// The identity and aliases of the researcher carry into the nested repository
+	for _, scope := range []string{"system", "global"} {
+		if state.Config[scope] == nil {
+			state.Config[scope] = make(map[string][]string)
+		}
+		repo.Config[scope] = state.Config[scope]
+	}
+	syncIdentity(repo)
+
+	repo.CurrentBranch = source.CurrentBranch
+	repo.Branches[repo.CurrentBranch] = append([]string{}, currentHistory(source)...)
+	repo.Upstreams[repo.CurrentBranch] = "origin/" + repo.CurrentBranch
+	repo.WorkingDir = checkoutTree(repo, headCommitID(repo))
+
+	sub := &Submodule{Name: entry.Name, Path: entry.Path, URL: entry.URL, Repo: repo}
+	state.Submodules[entry.Path] = sub
+	return sub, nil
+}
+
+// detachHead checks out a commit as a detached HEAD. It returns
+// a warning when commits made on the previous detached HEAD are left behind.
+func detachHead(state *GameState, id string) string {
+	history := append([]string{}, commitHistory(state, id)...)
+	warning := leaveDetachedHead(state, history)
+	state.Detached = history
+	state.CurrentBranch = DetachedHead
+	state.WorkingDir = checkoutTree(state, id)
+	state.StagingArea = make(map[string]FileState)
+	return warning
+}
+
+// leaveDetachedHead drops the detached HEAD's history. Commits that only it
+// reached, and that the next checkout does not keep, are reported the way
+// git warns about them on checkout.
+func leaveDetachedHead(state *GameState, kept []string) string {
+	detached := state.Detached
+	if detached == nil {
+		return ""
+	}
+	state.Detached = nil
+
+	var orphans []string
+	for _, id := range detached {
+		reachable := containsString(kept, id)
+		for _, name := range sortedBranchNames(state.Branches) {
+			if containsString(state.Branches[name], id) {
+				reachable = true
+				break
+			}
+		}
+		if !reachable {
+			orphans = append(orphans, id)
+		}
+	}
+	if len(orphans) == 0 {
+		return ""
+	}
+
+	var warning strings.Builder
+	warning.WriteString(fmt.Sprintf("Warning: you are leaving %d commits behind, not connected to\nany of your branches:\n\n", len(orphans)))
+	for _, id := range orphans {
+		commit, _ := state.findCommit(id)
+		warning.WriteString(fmt.Sprintf("  %s %s\n", id[:7], commitSubject(commit.Message)))
+	}
+	warning.WriteString("\nIf you want to keep them by creating a new branch, this may be a good time\nto do so with:\n\n git branch <new-branch-name> " + orphans[len(orphans)-1][:7])
+	return warning.String()
+}
+
+// SubmoduleCommand implements git submodule
+type SubmoduleCommand struct{}
+
+func (c *SubmoduleCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	subcommand := "status"
+	if len(args) > 0 {
+		subcommand, args = args[0], args[1:]
+	}
+
+	switch subcommand {
+	case "add":
+		return c.add(args, state)
+	case "init":
+		return c.init(args, state)
+	case "update":
+		return c.update(args, state)
+	case "status":
+		return c.status(args, state)
+	case "foreach":
+		return c.foreach(args, state)
+	default:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: unknown subcommand: `%s'\nusage: git submodule [add | init | update | status | foreach]", subcommand),
+			SCPEffect:    "🔴 ERROR: Unknown nested containment procedure",
+			AnomalyDelta: 1,
+		}
+	}
+}
+
+// entries returns the submodules .gitmodules declares, optionally limited to paths
+func (c *SubmoduleCommand) entries(state *GameState, paths []string) ([]gitmodulesEntry, error) {
+	var selected []gitmodulesEntry
+	for _, entry := range parseGitmodules(state.WorkingDir[GitmodulesFile].Content) {
+		if len(paths) == 0 || containsString(paths, entry.Path) {
+			selected = append(selected, entry)
+		}
+	}
+	for _, p := range paths {
+		found := false
+		for _, entry := range selected {
+			found = found || entry.Path == p
+		}
+		if !found {
+			return nil, fmt.Errorf("error: pathspec '%s' did not match any file(s) known to git", p)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Path < selected[j].Path })
+	return selected, nil
+}
+
+func (c *SubmoduleCommand) add(args []string, state *GameState) CommandResult {
+	var positional []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git submodule add <repository> [<path>]",
+			SCPEffect: "⚠️  WARNING: Specify the archive of the entity to nest",
+		}
+	}
+
+	url := positional[0]
+	subPath := strings.TrimSuffix(path.Base(url), ".git")
+	if len(positional) > 1 {
+		subPath = path.Clean(positional[1])
+	}
+	if _, _, tracked := indexVersion(state, subPath); tracked {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' already exists in the index", subPath),
+			SCPEffect:    "⚠️  WARNING: That location is already occupied",
+			AnomalyDelta: 1,
+		}
+	}
+	if _, exists := state.WorkingDir[subPath]; exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' already exists and is not a valid git repo", subPath),
+			SCPEffect:    "⚠️  WARNING: That location is already occupied",
+			AnomalyDelta: 1,
+		}
+	}
+
+	entry := gitmodulesEntry{Name: subPath, Path: subPath, URL: url}
+	sub, err := cloneSubmodule(state, entry)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: No such archive on the Foundation network",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// Record the submodule in .gitmodules, the local config and the index
+	gitmodules := state.WorkingDir[GitmodulesFile].Content
+	gitmodules += fmt.Sprintf("[submodule \"%s\"]\n\tpath = %s\n\turl = %s\n", entry.Name, entry.Path, entry.URL)
+	state.WorkingDir[GitmodulesFile] = FileState{Content: gitmodules, Hash: hashContent(gitmodules)}
+	(&ConfigCommand{}).Execute([]string{"--local", "submodule." + entry.Name + ".url", url}, state)
+	syncGitlinks(state)
+	(&AddCommand{}).Execute([]string{GitmodulesFile, subPath}, state)
+
+	return CommandResult{
+		Success:   true,
+		Message:   fmt.Sprintf("Cloning into '%s'...\ndone.", sub.Repo.CurrentWorktree),
+		SCPEffect: fmt.Sprintf("🧬 Sub-entity nested at %s - commit to record its containment", subPath),
+	}
+}
+
+func (c *SubmoduleCommand) init(args []string, state *GameState) CommandResult {
+	entries, err := c.entries(state, args)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: No nested entity at that location",
+			AnomalyDelta: 1,
+		}
+	}
+
+	var message strings.Builder
+	for _, entry := range entries {
+		key := "submodule." + entry.Name + ".url"
+		if _, registered := configValue(state, key); registered {
+			continue
+		}
+		(&ConfigCommand{}).Execute([]string{"--local", key, entry.URL}, state)
+		message.WriteString(fmt.Sprintf("Submodule '%s' (%s) registered for path '%s'\n", entry.Name, entry.URL, entry.Path))
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: "📋 Nested entities registered - run 'git submodule update' to materialize them",
+	}
+}
+
+func (c *SubmoduleCommand) update(args []string, state *GameState) CommandResult {
+	initialize, remote := false, false
+	var paths []string
+	for _, arg := range args {
+		switch arg {
+		case "--init":
+			initialize = true
+		case "--remote":
+			remote = true
+		case "--recursive":
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	var message strings.Builder
+	if initialize {
+		message.WriteString(c.init(paths, state).Message)
+	}
+	entries, err := c.entries(state, paths)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: No nested entity at that location",
+			AnomalyDelta: 1,
+		}
+	}
+
+	fail := func(text string) CommandResult {
+		return CommandResult{
+			Success:      false,
+			Message:      strings.TrimLeft(message.String()+"\n"+text, "\n"),
+			SCPEffect:    "🔴 ERROR: Nested containment could not be synchronized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	var warnings []string
+	for _, entry := range entries {
+		url, registered := configValue(state, "submodule."+entry.Name+".url")
+		if !registered {
+			continue
+		}
+		entry.URL = url
+
+		sub, cloned := state.Submodules[entry.Path]
+		if !cloned {
+			if sub, err = cloneSubmodule(state, entry); err != nil {
+				return fail(err.Error())
+			}
+			message.WriteString(fmt.Sprintf("\nCloning into '%s'...", sub.Repo.CurrentWorktree))
+		}
+		repo := sub.Repo
+
+		target := gitlinkCommit(state, entry.Path)
+		if source, exists := hostedRepository(state, entry.URL); exists && (remote || !repo.hasCommit(target)) {
+			fetchHosted(repo, source)
+		}
+		if remote {
+			tracked := repo.RemoteBranches["origin/main"]
+			if len(tracked) == 0 {
+				return fail(fmt.Sprintf("fatal: Unable to find current origin/main revision in submodule path '%s'", entry.Path))
+			}
+			target = tracked[len(tracked)-1]
+		}
+		if target == "" || !repo.hasCommit(target) {
+			return fail(fmt.Sprintf("error: Server does not allow request for unadvertised object %s\nfatal: Fetched in submodule path '%s', but it did not contain %s. Direct fetching of that commit failed.", target, entry.Path, target))
+		}
+		if cloned && headCommitID(repo) == target {
+			continue
+		}
+		if repositoryDirty(repo) {
+			return fail(fmt.Sprintf("error: Your local changes to the following files would be overwritten by checkout\nfatal: Unable to checkout '%s' in submodule path '%s'", target, entry.Path))
+		}
+
+		if warning := detachHead(repo, target); warning != "" {
+			warnings = append(warnings, warning)
+		}
+		message.WriteString(fmt.Sprintf("\nSubmodule path '%s': checked out '%s'", entry.Path, target))
+	}
+	syncGitlinks(state)
+
+	result := CommandResult{
+		Success:   true,
+		Message:   strings.TrimLeft(message.String(), "\n"),
+		SCPEffect: "🧬 Nested entities synchronized (HEAD detached at the recorded commit)",
+	}
+	if len(warnings) > 0 {
+		result.Message = strings.Join(warnings, "\n") + "\n" + result.Message
+		result.SCPEffect = "⚠️  WARNING: Sub-entity research made on a detached HEAD was abandoned"
+		result.AnomalyDelta = 2
+	}
+	return result
+}
+
+func (c *SubmoduleCommand) status(args []string, state *GameState) CommandResult {
+	entries, err := c.entries(state, args)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: No nested entity at that location",
+			AnomalyDelta: 1,
+		}
+	}
+
+	var list strings.Builder
+	for _, entry := range entries {
+		recorded := gitlinkCommit(state, entry.Path)
+		sub, cloned := state.Submodules[entry.Path]
+		if !cloned {
+			list.WriteString(fmt.Sprintf("-%s %s\n", recorded, entry.Path))
+			continue
+		}
+		head := headCommitID(sub.Repo)
+		prefix := " "
+		if head != recorded {
+			prefix = "+"
+		}
+		list.WriteString(fmt.Sprintf("%s%s %s (%s)\n", prefix, head, entry.Path, describeSubmoduleHead(sub.Repo)))
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(list.String(), "\n"),
+		SCPEffect: "📋 Nested containment status reported (- uninitialized, + differs from recorded commit)",
+	}
+}
+
+// describeSubmoduleHead names a submodule's checkout the way git describe --all does
+func describeSubmoduleHead(repo *GameState) string {
+	head := headCommitID(repo)
+	if repo.CurrentBranch != DetachedHead {
+		return "heads/" + repo.CurrentBranch
+	}
+	for _, name := range sortedBranchNames(repo.Branches) {
+		if commits := repo.Branches[name]; len(commits) > 0 && commits[len(commits)-1] == head {
+			return "heads/" + name
+		}
+	}
+	for _, name := range sortedBranchNames(repo.RemoteBranches) {
+		if commits := repo.RemoteBranches[name]; len(commits) > 0 && commits[len(commits)-1] == head {
+			return "remotes/" + name
+		}
+	}
+	return head[:7]
+}
+
+// foreach runs a git command in every checked-out submodule
+func (c *SubmoduleCommand) foreach(args []string, state *GameState) CommandResult {
+	if len(args) == 1 {
+		args = splitCommandLine(args[0])
+	}
+	if len(args) < 2 || args[0] != "git" {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git submodule foreach git <command>",
+			SCPEffect: "⚠️  Only git protocols may run inside nested containment",
+		}
+	}
+	cmd, exists := CommandRegistry[args[1]]
+	if !exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("git: '%s' is not a git command", args[1]),
+			SCPEffect:    "🔴 ERROR: Unknown containment protocol",
+			AnomalyDelta: 1,
+		}
+	}
+
+	paths := make([]string, 0, len(state.Submodules))
+	for subPath := range state.Submodules {
+		paths = append(paths, subPath)
+	}
+	sort.Strings(paths)
+
+	var message strings.Builder
+	for _, subPath := range paths {
+		message.WriteString(fmt.Sprintf("Entering '%s'\n", subPath))
+		result := cmd.Execute(args[2:], state.Submodules[subPath].Repo)
+		if result.Edit != nil || result.Prompt != nil {
+			result.Success, result.Message = false, "fatal: interactive commands cannot run under foreach"
+		}
+		if strings.TrimSpace(result.Message) != "" {
+			message.WriteString(strings.TrimRight(result.Message, "\n") + "\n")
+		}
+		if !result.Success {
+			message.WriteString(fmt.Sprintf("fatal: run_command returned non-zero status for %s", subPath))
+			return CommandResult{
+				Success:      false,
+				Message:      message.String(),
+				SCPEffect:    fmt.Sprintf("🔴 ERROR: Protocol failed inside nested entity %s", subPath),
+				AnomalyDelta: 1,
+			}
+		}
+	}
+	syncGitlinks(state)
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: fmt.Sprintf("🧬 Protocol applied to %d nested entities", len(paths)),
+	}
+}
+
+func (c *SubmoduleCommand) Help() string {
+	return "Manage nested repositories (add, init, update, status, foreach)"
+}
+
+func (c *SubmoduleCommand) RequiredArgs() int {
+	return 0
+}
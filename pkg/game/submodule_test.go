@@ -0,0 +1,90 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubmoduleAddAndStatus(t *testing.T) {
+	state := newReportTestState(t)
+	cmd := &SubmoduleCommand{}
+
+	result := cmd.Execute([]string{"add", SubEntityURL, "sub-entity"}, state)
+	if !result.Success {
+		t.Fatalf("submodule add failed: %s", result.Message)
+	}
+	sub := state.Submodules["sub-entity"]
+	if sub == nil || len(sub.Repo.Commits) != 2 {
+		t.Fatalf("The sub-entity's history should be cloned")
+	}
+	if _, staged := state.StagingArea["sub-entity"]; !staged {
+		t.Error("The gitlink should be staged")
+	}
+	if !strings.Contains(state.StagingArea[GitmodulesFile].Content, "url = "+SubEntityURL) {
+		t.Error(".gitmodules should record the submodule URL")
+	}
+	(&CommitCommand{}).Execute([]string{"-m", "Nest sub-entity"}, state)
+
+	head := headCommitID(sub.Repo)
+	if result = cmd.Execute([]string{"status"}, state); result.Message != " "+head+" sub-entity (heads/main)" {
+		t.Errorf("Unexpected status %q", result.Message)
+	}
+
+	// A commit inside the submodule shows up as new commits in the parent
+	engine := &Engine{State: state}
+	sub.Repo.WorkingDir["entity.txt"] = FileState{Content: "awake", Hash: hashContent("awake")}
+	engine.ProcessCommand("git -C sub-entity add entity.txt")
+	if result = engine.ProcessCommand("git -C sub-entity commit -m Awakened"); !result.Success {
+		t.Fatalf("commit inside the submodule failed: %s", result.Message)
+	}
+	if result = (&StatusCommand{}).Execute(nil, state); !strings.Contains(result.Message, "modified:   sub-entity (new commits)") {
+		t.Errorf("Parent status should report new commits, got %q", result.Message)
+	}
+	if result = cmd.Execute([]string{"status"}, state); !strings.HasPrefix(result.Message, "+") {
+		t.Errorf("Status should flag a submodule away from its recorded commit, got %q", result.Message)
+	}
+}
+
+func TestSubmoduleUpdateDetachesHead(t *testing.T) {
+	state := newReportTestState(t)
+	cmd := &SubmoduleCommand{}
+	cmd.Execute([]string{"add", SubEntityURL, "sub-entity"}, state)
+	(&CommitCommand{}).Execute([]string{"-m", "Nest sub-entity"}, state)
+	recorded := gitlinkCommit(state, "sub-entity")
+
+	// A fresh checkout knows the submodule but has not cloned it
+	delete(state.Submodules, "sub-entity")
+	delete(state.Config["local"], "submodule.sub-entity.url")
+	if result := cmd.Execute([]string{"status"}, state); result.Message != "-"+recorded+" sub-entity" {
+		t.Errorf("An uninitialized submodule should be marked '-', got %q", result.Message)
+	}
+	if result := cmd.Execute([]string{"update"}, state); state.Submodules["sub-entity"] != nil {
+		t.Errorf("update without init should skip the submodule, got %q", result.Message)
+	}
+
+	result := cmd.Execute([]string{"update", "--init"}, state)
+	if !strings.Contains(result.Message, "registered for path 'sub-entity'") {
+		t.Errorf("--init should register the submodule, got %q", result.Message)
+	}
+	repo := state.Submodules["sub-entity"].Repo
+	if repo.CurrentBranch != DetachedHead || headCommitID(repo) != recorded {
+		t.Fatalf("update should detach HEAD at the recorded commit")
+	}
+
+	// Work committed on the detached HEAD is abandoned by the next update
+	repo.WorkingDir["notes.txt"] = FileState{Content: "lost", Hash: hashContent("lost")}
+	(&AddCommand{}).Execute([]string{"notes.txt"}, repo)
+	result = (&CommitCommand{}).Execute([]string{"-m", "Detached work"}, repo)
+	if !strings.HasPrefix(result.Message, "[detached HEAD ") {
+		t.Errorf("Commit should report the detached HEAD, got %q", result.Message)
+	}
+	result = cmd.Execute([]string{"update"}, state)
+	if !strings.Contains(result.Message, "leaving 1 commits behind") || result.AnomalyDelta == 0 {
+		t.Errorf("update should warn about abandoned commits, got %q", result.Message)
+	}
+
+	result = cmd.Execute([]string{"foreach", "git status"}, state)
+	if !strings.Contains(result.Message, "Entering 'sub-entity'\nHEAD detached at "+recorded[:7]) {
+		t.Errorf("foreach should run the command in each submodule, got %q", result.Message)
+	}
+}
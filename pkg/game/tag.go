@@ -0,0 +1,194 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tag names a commit. Annotated tags also record who created them and why;
+// git describe only considers those unless --tags is given.
+type Tag struct {
+	Name      string
+	Commit    string
+	Annotated bool
+	Message   string
+	Tagger    string
+	Timestamp time.Time
+}
+
+// TagCommand implements git tag
+type TagCommand struct{}
+
+func (c *TagCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	annotated := false
+	deleting := false
+	message := ""
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-a", "--annotate":
+			annotated = true
+		case "-d", "--delete":
+			deleting = true
+		case "-l", "--list":
+			// Listing is the default without a name
+		case "-m", "--message":
+			if i+1 >= len(args) {
+				return CommandResult{
+					Success:      false,
+					Message:      "error: switch `m' requires a value",
+					SCPEffect:    "⚠️  WARNING: Tag annotation missing",
+					AnomalyDelta: 1,
+				}
+			}
+			i++
+			message = args[i]
+			annotated = true
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+
+	if deleting {
+		return c.delete(positional, state)
+	}
+	if len(positional) == 0 {
+		return c.list(state)
+	}
+
+	name := positional[0]
+	if _, exists := state.Tags[name]; exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: tag '%s' already exists", name),
+			SCPEffect:    "⚠️  WARNING: That designation is already assigned",
+			AnomalyDelta: 1,
+		}
+	}
+	if annotated && message == "" {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: no tag message given; use -m <msg>",
+			SCPEffect:    "⚠️  WARNING: Annotated designations require a rationale",
+			AnomalyDelta: 1,
+		}
+	}
+
+	rev := "HEAD"
+	if len(positional) > 1 {
+		rev = positional[1]
+	}
+	commitID, err := resolveRevision(state, rev)
+	if err != nil {
+		if rev == "HEAD" {
+			err = fmt.Errorf("fatal: failed to resolve 'HEAD' as a valid ref.")
+		}
+		return CommandResult{
+			Success:      false,
+			Message:      err.Error(),
+			SCPEffect:    "🔴 ERROR: No containment record to designate",
+			AnomalyDelta: 1,
+		}
+	}
+
+	tagger := "Dr. ████████"
+	if state.ConfigName != "" {
+		tagger = state.ConfigName
+	}
+	state.Tags[name] = Tag{
+		Name:      name,
+		Commit:    commitID,
+		Annotated: annotated,
+		Message:   message,
+		Tagger:    tagger,
+		Timestamp: time.Now(),
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: fmt.Sprintf("🏷️  Containment record %s designated '%s'", commitID[:7], name),
+	}
+}
+
+func (c *TagCommand) list(state *GameState) CommandResult {
+	names := sortedTagNames(state)
+	if len(names) == 0 {
+		return CommandResult{
+			Success:   true,
+			Message:   "",
+			SCPEffect: "📋 No containment records have been designated",
+		}
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.Join(names, "\n"),
+		SCPEffect: fmt.Sprintf("📋 %d designated containment records", len(names)),
+	}
+}
+
+func (c *TagCommand) delete(names []string, state *GameState) CommandResult {
+	if len(names) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git tag -d <tagname>...",
+			SCPEffect: "⚠️  WARNING: Specify the designation to revoke",
+		}
+	}
+
+	var message strings.Builder
+	missing := 0
+	for _, name := range names {
+		tag, exists := state.Tags[name]
+		if !exists {
+			message.WriteString(fmt.Sprintf("error: tag '%s' not found.\n", name))
+			missing++
+			continue
+		}
+		delete(state.Tags, name)
+		message.WriteString(fmt.Sprintf("Deleted tag '%s' (was %s)\n", name, tag.Commit[:7]))
+	}
+
+	if missing > 0 {
+		return CommandResult{
+			Success:      false,
+			Message:      strings.TrimRight(message.String(), "\n"),
+			SCPEffect:    "🔴 ERROR: Unknown designation",
+			AnomalyDelta: 1,
+		}
+	}
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: "✅ Designation revoked",
+	}
+}
+
+// sortedTagNames returns the tag names in lexical order
+func sortedTagNames(state *GameState) []string {
+	names := make([]string, 0, len(state.Tags))
+	for name := range state.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *TagCommand) Help() string {
+	return "Create, list or delete tags"
+}
+
+func (c *TagCommand) RequiredArgs() int {
+	return 0
+}
@@ -0,0 +1,180 @@
+package game
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Trailer is one "Key: value" line at the end of a commit message
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+
+// parseTrailers returns the trailers of a commit message: the final
+// paragraph, when it is not the subject and every line is "Key: value"
+func parseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var trailers []Trailer
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		m := trailerPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: m[2]})
+	}
+	return trailers
+}
+
+// appendTrailers adds trailers to a message, joining an existing trailer
+// block. A trailer identical to the last one is not repeated. An empty
+// message stays empty so the commit still aborts.
+func appendTrailers(message string, trailers []Trailer) string {
+	if message == "" || len(trailers) == 0 {
+		return message
+	}
+
+	existing := parseTrailers(message)
+	var added []string
+	for _, trailer := range trailers {
+		if len(existing) > 0 && existing[len(existing)-1] == trailer {
+			continue
+		}
+		existing = append(existing, trailer)
+		added = append(added, fmt.Sprintf("%s: %s", trailer.Key, trailer.Value))
+	}
+	if len(added) == 0 {
+		return message
+	}
+
+	separator := "\n\n"
+	if len(existing) > len(added) {
+		separator = "\n"
+	}
+	return message + separator + strings.Join(added, "\n")
+}
+
+// parseTrailerArg parses a --trailer value such as "SCP-Clearance: 4" or "Reviewed-by=Dr. Clef"
+func parseTrailerArg(arg string) (Trailer, error) {
+	cut := strings.IndexAny(arg, ":=")
+	if cut <= 0 {
+		return Trailer{}, fmt.Errorf("fatal: invalid trailer '%s'", arg)
+	}
+	key := strings.TrimSpace(arg[:cut])
+	if !trailerPattern.MatchString(key + ":") {
+		return Trailer{}, fmt.Errorf("fatal: invalid trailer '%s'", arg)
+	}
+	return Trailer{Key: key, Value: strings.TrimSpace(arg[cut+1:])}, nil
+}
+
+// signoffTrailer is the Signed-off-by line for the configured identity
+func signoffTrailer(state *GameState) Trailer {
+	name := "Dr. ████████"
+	if state.ConfigName != "" {
+		name = state.ConfigName
+	}
+	if state.ConfigEmail != "" {
+		name += fmt.Sprintf(" <%s>", state.ConfigEmail)
+	}
+	return Trailer{Key: "Signed-off-by", Value: name}
+}
+
+// formatPretty expands a --format string for one commit. It understands
+// %H %h %s %b %B %an %ad %N %n %% and %(trailers[:key=<K>][,valueonly]).
+func formatPretty(state *GameState, commit Commit, format string) string {
+	subject, body, _ := strings.Cut(commit.Message, "\n")
+	body = strings.TrimLeft(body, "\n")
+	if body != "" {
+		body += "\n"
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 >= len(format) {
+			out.WriteByte(format[i])
+			continue
+		}
+
+		rest := format[i+1:]
+		switch {
+		case strings.HasPrefix(rest, "("):
+			end := strings.Index(rest, ")")
+			if end < 0 {
+				out.WriteString(format[i:])
+				return out.String()
+			}
+			out.WriteString(expandTrailerPlaceholder(commit.Message, rest[1:end]))
+			i += end + 1
+		case strings.HasPrefix(rest, "an"):
+			out.WriteString(commit.Author)
+			i += 2
+		case strings.HasPrefix(rest, "ad"):
+			out.WriteString(commit.Timestamp.Format("Mon Jan 02 15:04:05 2006"))
+			i += 2
+		default:
+			switch rest[0] {
+			case 'H':
+				out.WriteString(commit.ID)
+			case 'h':
+				out.WriteString(commit.ID[:7])
+			case 's':
+				out.WriteString(subject)
+			case 'b':
+				out.WriteString(body)
+			case 'B':
+				out.WriteString(commit.Message + "\n")
+			case 'N':
+				if note, exists := state.Notes[commit.ID]; exists {
+					out.WriteString(note + "\n")
+				}
+			case 'n':
+				out.WriteString("\n")
+			case '%':
+				out.WriteString("%")
+			default:
+				out.WriteString("%" + string(rest[0]))
+			}
+			i++
+		}
+	}
+	return out.String()
+}
+
+// expandTrailerPlaceholder renders %(trailers...) for a commit message
+func expandTrailerPlaceholder(message, spec string) string {
+	name, options, _ := strings.Cut(spec, ":")
+	if name != "trailers" {
+		return "%(" + spec + ")"
+	}
+
+	key := ""
+	valueOnly := false
+	for _, option := range strings.Split(options, ",") {
+		switch {
+		case strings.HasPrefix(option, "key="):
+			key = strings.TrimPrefix(option, "key=")
+		case option == "valueonly" || option == "valueonly=true":
+			valueOnly = true
+		}
+	}
+
+	var out strings.Builder
+	for _, trailer := range parseTrailers(message) {
+		if key != "" && !strings.EqualFold(trailer.Key, key) {
+			continue
+		}
+		if valueOnly {
+			out.WriteString(trailer.Value + "\n")
+		} else {
+			out.WriteString(fmt.Sprintf("%s: %s\n", trailer.Key, trailer.Value))
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,448 @@
+package game
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// MainWorktreePath is where the primary containment checkout lives
+const MainWorktreePath = "/site-19/scp-████"
+
+// Worktree is a checkout that is not currently active. The active worktree
+// always lives in GameState.WorkingDir, StagingArea and CurrentBranch.
+type Worktree struct {
+	Path        string
+	Branch      string
+	Detached    []string // commits of its detached HEAD, when Branch is DetachedHead
+	WorkingDir  map[string]FileState
+	StagingArea map[string]FileState
+	Sparse      *SparseCheckout
+	Directories map[string]bool
+	Missing     bool // its directory vanished; git worktree prune forgets it
+}
+
+// worktreeBranches maps each checked-out branch to the worktree holding it
+func worktreeBranches(state *GameState) map[string]string {
+	branches := map[string]string{state.CurrentBranch: state.CurrentWorktree}
+	for wtPath, wt := range state.Worktrees {
+		branches[wt.Branch] = wtPath
+	}
+	return branches
+}
+
+// checkedOutElsewhere reports the path of another worktree that has the branch checked out
+func checkedOutElsewhere(state *GameState, branch string) (string, bool) {
+	for wtPath, wt := range state.Worktrees {
+		if wt.Branch == branch {
+			return wtPath, true
+		}
+	}
+	return "", false
+}
+
+// switchWorktree parks the active worktree and activates the one at wtPath
+func (gs *GameState) switchWorktree(wtPath string) error {
+	if wtPath == gs.CurrentWorktree {
+		return nil
+	}
+	target, exists := gs.Worktrees[wtPath]
+	if !exists {
+		return fmt.Errorf("cd: no such worktree: %s", wtPath)
+	}
+	if target.Missing {
+		return fmt.Errorf("cd: %s: No such file or directory", wtPath)
+	}
+
+	gs.Worktrees[gs.CurrentWorktree] = &Worktree{
+		Path:        gs.CurrentWorktree,
+		Branch:      gs.CurrentBranch,
+		Detached:    gs.Detached,
+		WorkingDir:  gs.WorkingDir,
+		StagingArea: gs.StagingArea,
+		Sparse:      gs.Sparse,
+		Directories: gs.Directories,
+	}
+	delete(gs.Worktrees, wtPath)
+
+	gs.CurrentWorktree = wtPath
+	gs.CurrentBranch = target.Branch
+	gs.Detached = target.Detached
+	gs.WorkingDir = target.WorkingDir
+	gs.StagingArea = target.StagingArea
+	gs.Sparse = target.Sparse
+	gs.Directories = target.Directories
+	return nil
+}
+
+// findWorktree matches a path or its final component against known worktrees
+func findWorktree(state *GameState, name string) (string, bool) {
+	name = strings.TrimSuffix(name, "/")
+	candidates := []string{state.CurrentWorktree}
+	for wtPath := range state.Worktrees {
+		candidates = append(candidates, wtPath)
+	}
+	for _, wtPath := range candidates {
+		if wtPath == name || path.Clean(wtPath) == path.Clean(name) {
+			return wtPath, true
+		}
+	}
+	for _, wtPath := range candidates {
+		if path.Base(wtPath) == path.Base(name) {
+			return wtPath, true
+		}
+	}
+	return "", false
+}
+
+// linkedWorktreeAt returns the parked linked worktree whose directory is
+// exactly the given path
+func linkedWorktreeAt(state *GameState, name string) (*Worktree, bool) {
+	for wtPath, wt := range state.Worktrees {
+		if wtPath != MainWorktreePath && path.Clean(wtPath) == path.Clean(name) {
+			return wt, true
+		}
+	}
+	return nil, false
+}
+
+// checkoutTree materializes the files at a commit into a fresh working directory
+func checkoutTree(state *GameState, commitID string) map[string]FileState {
+	files := make(map[string]FileState)
+	for filename, hash := range treeAt(state, commitID) {
+		files[filename] = FileState{
+			Content: state.Objects[hash],
+			Hash:    hash,
+		}
+	}
+	return files
+}
+
+// WorktreeCommand implements git worktree
+type WorktreeCommand struct{}
+
+func (c *WorktreeCommand) Execute(args []string, state *GameState) CommandResult {
+	if !state.IsInitialized {
+		return CommandResult{
+			Success:      false,
+			Message:      "fatal: not a git repository",
+			SCPEffect:    "🔴 ERROR: No containment protocols initialized",
+			AnomalyDelta: 1,
+		}
+	}
+
+	if len(args) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git worktree add [-b <new-branch>] <path> [<commit-ish>]\n   or: git worktree list\n   or: git worktree remove [--force] <worktree>\n   or: git worktree prune [-n]",
+			SCPEffect: "⚠️  WARNING: Specify a containment site operation",
+		}
+	}
+
+	switch args[0] {
+	case "add":
+		return c.add(args[1:], state)
+	case "list":
+		return c.list(state)
+	case "remove":
+		return c.remove(args[1:], state)
+	case "prune":
+		return c.prune(args[1:], state)
+	default:
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("error: unknown subcommand: `%s'", args[0]),
+			SCPEffect:    "🔴 ERROR: Unknown containment site operation",
+			AnomalyDelta: 1,
+		}
+	}
+}
+
+func (c *WorktreeCommand) add(args []string, state *GameState) CommandResult {
+	newBranch := ""
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-b" && i+1 < len(args) {
+			newBranch = args[i+1]
+			i++
+			continue
+		}
+		positional = append(positional, args[i])
+	}
+
+	if len(positional) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git worktree add [-b <new-branch>] <path> [<commit-ish>]",
+			SCPEffect: "⚠️  WARNING: Specify where to establish the containment site",
+		}
+	}
+
+	wtPath := positional[0]
+	if _, exists := state.Worktrees[wtPath]; exists || wtPath == state.CurrentWorktree {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' already exists", wtPath),
+			SCPEffect:    "⚠️  WARNING: A containment site already occupies that location",
+			AnomalyDelta: 1,
+		}
+	}
+
+	// Decide which branch the new worktree checks out
+	branch := newBranch
+	startPoint := "HEAD"
+	switch {
+	case newBranch != "" && len(positional) > 1:
+		startPoint = positional[1]
+	case newBranch == "" && len(positional) > 1:
+		branch = positional[1]
+		startPoint = positional[1]
+	case newBranch == "":
+		// Like git, default to a new branch named after the directory
+		branch = path.Base(wtPath)
+		if _, exists := state.Branches[branch]; !exists {
+			newBranch = branch
+		} else {
+			startPoint = branch
+		}
+	}
+
+	startID, err := resolveRevision(state, startPoint)
+	if err != nil {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: invalid reference: %s", startPoint),
+			SCPEffect:    "🔴 ERROR: No containment record to base the site on",
+			AnomalyDelta: 2,
+		}
+	}
+
+	if newBranch != "" {
+		if !validBranchName(newBranch) {
+			return invalidBranchName(newBranch)
+		}
+		if _, exists := state.Branches[newBranch]; exists {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("fatal: a branch named '%s' already exists", newBranch),
+				SCPEffect:    "⚠️  WARNING: Duplicate containment branch rejected",
+				AnomalyDelta: 1,
+			}
+		}
+		state.Branches[newBranch] = append([]string{}, commitHistory(state, startID)...)
+	} else {
+		if _, exists := state.Branches[branch]; !exists {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("fatal: invalid reference: %s", branch),
+				SCPEffect:    "🔴 ERROR: Unknown containment branch",
+				AnomalyDelta: 2,
+			}
+		}
+		if holder, busy := worktreeBranches(state)[branch]; busy {
+			return CommandResult{
+				Success:      false,
+				Message:      fmt.Sprintf("fatal: '%s' is already checked out at '%s'", branch, holder),
+				SCPEffect:    "🔴 ERROR: That strategy is already under test at another site",
+				AnomalyDelta: 2,
+			}
+		}
+	}
+
+	state.Worktrees[wtPath] = &Worktree{
+		Path:        wtPath,
+		Branch:      branch,
+		WorkingDir:  checkoutTree(state, startID),
+		StagingArea: make(map[string]FileState),
+	}
+
+	message := fmt.Sprintf("Preparing worktree (checking out '%s')\nHEAD is now at %s", branch, startID[:7])
+	if newBranch != "" {
+		message = fmt.Sprintf("Preparing worktree (new branch '%s')\nHEAD is now at %s", branch, startID[:7])
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   message,
+		SCPEffect: fmt.Sprintf("✅ Parallel containment site established at %s - use 'cd %s' to enter", wtPath, wtPath),
+	}
+}
+
+func (c *WorktreeCommand) list(state *GameState) CommandResult {
+	paths := []string{MainWorktreePath}
+	for wtPath := range state.Worktrees {
+		if wtPath != MainWorktreePath {
+			paths = append(paths, wtPath)
+		}
+	}
+	if state.CurrentWorktree != MainWorktreePath {
+		paths = append(paths, state.CurrentWorktree)
+	}
+	sort.Strings(paths[1:])
+
+	width := 0
+	for _, wtPath := range paths {
+		if len(wtPath) > width {
+			width = len(wtPath)
+		}
+	}
+
+	var list strings.Builder
+	for _, wtPath := range paths {
+		branch := state.CurrentBranch
+		missing := false
+		if wt, parked := state.Worktrees[wtPath]; parked {
+			branch = wt.Branch
+			missing = wt.Missing
+		}
+
+		tip := "0000000"
+		if history := state.Branches[branch]; len(history) > 0 {
+			tip = history[len(history)-1][:7]
+		}
+		list.WriteString(fmt.Sprintf("%-*s %s [%s]", width, wtPath, tip, branch))
+		if missing {
+			list.WriteString(" prunable")
+		}
+		list.WriteString("\n")
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(list.String(), "\n"),
+		SCPEffect: fmt.Sprintf("📋 %d containment sites active", len(paths)),
+	}
+}
+
+func (c *WorktreeCommand) remove(args []string, state *GameState) CommandResult {
+	force := false
+	var positional []string
+	for _, arg := range args {
+		if arg == "--force" || arg == "-f" {
+			force = true
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	if len(positional) == 0 {
+		return CommandResult{
+			Success:   false,
+			Message:   "usage: git worktree remove [--force] <worktree>",
+			SCPEffect: "⚠️  WARNING: Specify the containment site to dismantle",
+		}
+	}
+
+	wtPath, exists := findWorktree(state, positional[0])
+	if !exists {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' is not a working tree", positional[0]),
+			SCPEffect:    "🔴 ERROR: Unknown containment site",
+			AnomalyDelta: 1,
+		}
+	}
+	if wtPath == MainWorktreePath {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' is a main working tree", wtPath),
+			SCPEffect:    "🔴 ERROR: The primary containment site cannot be dismantled",
+			AnomalyDelta: 2,
+		}
+	}
+	if wtPath == state.CurrentWorktree {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' is the current working tree; cd to another worktree first", wtPath),
+			SCPEffect:    "⚠️  WARNING: Leave the containment site before dismantling it",
+			AnomalyDelta: 1,
+		}
+	}
+
+	wt := state.Worktrees[wtPath]
+	if !force && !wt.Missing && worktreeDirty(state, wt) {
+		return CommandResult{
+			Success:      false,
+			Message:      fmt.Sprintf("fatal: '%s' contains modified or untracked files, use --force to delete it", wtPath),
+			SCPEffect:    "⚠️  WARNING: Unsecured research remains at that site",
+			AnomalyDelta: 1,
+		}
+	}
+
+	delete(state.Worktrees, wtPath)
+	result := CommandResult{
+		Success:   true,
+		Message:   "",
+		SCPEffect: fmt.Sprintf("✅ Containment site %s dismantled", wtPath),
+	}
+	if force && worktreeDirty(state, wt) {
+		result.SCPEffect = fmt.Sprintf("⚠️  WARNING: Containment site %s dismantled with unsecured research inside", wtPath)
+		result.AnomalyDelta = 3
+	}
+	return result
+}
+
+func (c *WorktreeCommand) prune(args []string, state *GameState) CommandResult {
+	dryRun := len(args) > 0 && (args[0] == "-n" || args[0] == "--dry-run")
+
+	var message strings.Builder
+	for _, wtPath := range sortedWorktreePaths(state) {
+		if !state.Worktrees[wtPath].Missing {
+			continue
+		}
+		message.WriteString(fmt.Sprintf("Removing worktrees/%s: gitdir file points to non-existent location\n", path.Base(wtPath)))
+		if !dryRun {
+			delete(state.Worktrees, wtPath)
+		}
+	}
+
+	return CommandResult{
+		Success:   true,
+		Message:   strings.TrimRight(message.String(), "\n"),
+		SCPEffect: "📋 Abandoned containment site records pruned",
+	}
+}
+
+// worktreeDirty reports whether a parked worktree has staged or uncommitted changes
+func worktreeDirty(state *GameState, wt *Worktree) bool {
+	if len(wt.StagingArea) > 0 {
+		return true
+	}
+	tree := make(map[string]string)
+	history := state.Branches[wt.Branch]
+	if wt.Branch == DetachedHead {
+		history = wt.Detached
+	}
+	if len(history) > 0 {
+		tree = treeAt(state, history[len(history)-1])
+	}
+	for filename := range tree {
+		if _, present := wt.WorkingDir[filename]; !present && wt.Sparse.includes(filename) {
+			return true
+		}
+	}
+	for filename, file := range wt.WorkingDir {
+		if tree[filename] != file.Hash {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedWorktreePaths returns the parked worktree paths in lexical order
+func sortedWorktreePaths(state *GameState) []string {
+	var paths []string
+	for wtPath := range state.Worktrees {
+		paths = append(paths, wtPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (c *WorktreeCommand) Help() string {
+	return "Manage parallel containment sites (linked worktrees)"
+}
+
+func (c *WorktreeCommand) RequiredArgs() int {
+	return 1
+}
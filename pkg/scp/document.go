@@ -97,10 +97,10 @@ func FormatContainmentStatus(status string, anomalyLevel int, sanity int) string
 
 	return fmt.Sprintf(`
 ╔══════════════════════════════════════════════════════════╗
-║ CONTAINMENT STATUS: %s %s %-8s                      ║
+║ CONTAINMENT STATUS: %s %s %-8s                        ║
 ╠══════════════════════════════════════════════════════════╣
-║ Anomaly Level:  [%s] %3d%%                  ║
-║ Researcher Sanity: [%s] %3d%%                  ║
+║ Anomaly Level:     [%s] %3d%%           ║
+║ Researcher Sanity: [%s] %3d%%           ║
 ╚══════════════════════════════════════════════════════════╝`,
 		statusColor, statusIcon, status,
 		anomalyBar, anomalyLevel,
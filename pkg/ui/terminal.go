@@ -28,6 +28,11 @@ var (
 	CommandColor = color.New(color.FgWhite, color.Bold)
 	ErrorColor   = color.New(color.FgRed)
 	SuccessColor = color.New(color.FgGreen)
+
+	// The entity's voice, by awareness tone
+	EntityCryptic = color.New(color.FgMagenta)
+	EntityCurious = color.New(color.FgMagenta, color.Bold)
+	EntityHostile = color.New(color.FgRed, color.Bold, color.Underline)
 )
 
 // Terminal represents the game terminal UI
@@ -58,15 +63,15 @@ func (t *Terminal) DisplayWelcome() {
 
 // DisplayGameStatus shows the current game status
 func (t *Terminal) DisplayGameStatus(state *game.GameState) {
-	fmt.Println(strings.Repeat("═", 60))
-
-	// Containment status with color
+	// Containment status, anomaly level and researcher sanity
 	statusColor := getStatusColor(state.ContainmentStatus)
-	statusColor.Printf("CONTAINMENT STATUS: %s", state.ContainmentStatus)
+	statusColor.Println(strings.TrimPrefix(scp.FormatContainmentStatus(state.ContainmentStatus, state.AnomalyLevel, state.Sanity), "\n"))
 
 	// Current stats
-	fmt.Printf(" | Branch: %s | Anomaly: %d%%\n",
-		state.CurrentBranch, state.AnomalyLevel)
+	fmt.Printf("Branch: %s", state.CurrentBranch)
+	stage := game.CurrentAwarenessStage(state)
+	fmt.Print(" | Entity: ")
+	entityColor(stage.Tone).Printf("%s (%d)\n", stage.Name, state.Awareness)
 
 	// Working directory status
 	if len(state.StagingArea) > 0 {
@@ -102,6 +107,21 @@ func (t *Terminal) DisplayLevelIntro(level *game.Level) {
 	wrapText(level.Objective, 60)
 	fmt.Println()
 
+	if len(level.Objectives) > 0 {
+		SCPGray.Printf("%d objectives - type 'objectives' for the checklist\n", len(level.Objectives))
+		fmt.Println()
+	}
+
+	// History left behind by earlier researchers
+	if setup := level.Repository; setup != nil {
+		SCPGray.Printf("REPOSITORY: %d commits already recorded", len(setup.Commits))
+		if len(setup.Stash) > 0 {
+			SCPGray.Printf(", %d stashed", len(setup.Stash))
+		}
+		fmt.Println(" - start with 'git log'")
+		fmt.Println()
+	}
+
 	// Files in working directory
 	if len(level.InitialFiles) > 0 {
 		SCPGray.Println("FILES DETECTED:")
@@ -112,6 +132,44 @@ func (t *Terminal) DisplayLevelIntro(level *game.Level) {
 	}
 }
 
+// DisplayObjectives shows the level's live objective checklist, with the
+// hint for the next open objective
+func (t *Terminal) DisplayObjectives(level *game.Level, state *game.GameState) {
+	fmt.Println()
+	SCPWhite.Printf("CONTAINMENT OBJECTIVES - LEVEL %d: %s\n", level.ID, level.Title)
+	fmt.Println(strings.Repeat("─", 60))
+
+	checklist := level.Checklist(state)
+	if len(checklist) == 0 {
+		wrapText(level.Objective, 60)
+		fmt.Println()
+		return
+	}
+
+	met, earned, total := 0, 0, 0
+	hinted := false
+	for _, status := range checklist {
+		total += status.Points
+		if status.Awarded {
+			earned += status.Points
+		}
+		if status.Met {
+			met++
+			SuccessColor.Printf("  ✓ %-48s %+5d\n", status.Description, status.Points)
+			continue
+		}
+		ErrorColor.Printf("  ✗ %-48s %+5d\n", status.Description, status.Points)
+		if !hinted && status.Hint != "" {
+			SCPGray.Printf("      Hint: %s\n", status.Hint)
+			hinted = true
+		}
+	}
+
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Progress: %d/%d objectives | %d/%d points\n", met, len(checklist), earned, total)
+	fmt.Println()
+}
+
 // DisplayIncidentReport shows an incident report
 func (t *Terminal) DisplayIncidentReport(errorMsg string) {
 	if t.CurrentLevel != nil {
@@ -126,6 +184,17 @@ func (t *Terminal) DisplayCommandResult(result game.CommandResult) {
 		fmt.Println(result.Message)
 	}
 
+	// The entity's lines are set apart in its own voice
+	var effects, voice []string
+	for _, line := range strings.Split(result.SCPEffect, "\n") {
+		if strings.HasPrefix(line, "👁️") || strings.HasPrefix(line, "🧠") {
+			voice = append(voice, line)
+		} else if line != "" {
+			effects = append(effects, line)
+		}
+	}
+	result.SCPEffect = strings.Join(effects, "\n")
+
 	// Display SCP effect
 	if result.SCPEffect != "" {
 		fmt.Println()
@@ -141,12 +210,28 @@ func (t *Terminal) DisplayCommandResult(result game.CommandResult) {
 			ErrorColor.Println(result.SCPEffect)
 		}
 	}
+	for _, line := range voice {
+		switch {
+		case strings.HasPrefix(line, "👁️  ..."):
+			EntityCryptic.Println(line)
+		case line == strings.ToUpper(line):
+			EntityHostile.Println(line)
+		default:
+			EntityCurious.Println(line)
+		}
+	}
 
 	// Display stat changes
 	if result.AnomalyDelta != 0 {
 		fmt.Println()
 		SCPRed.Printf("⚠️  Anomaly Level +%d%%\n", result.AnomalyDelta)
 	}
+	switch {
+	case result.SanityDelta < 0:
+		SCPOrange.Printf("💭 Researcher Sanity %d%%\n", result.SanityDelta)
+	case result.SanityDelta > 0:
+		SuccessColor.Printf("💭 Researcher Sanity +%d%%\n", result.SanityDelta)
+	}
 }
 
 // DisplayPrompt shows the command prompt
@@ -172,21 +257,73 @@ func (t *Terminal) DisplayHelp() {
 		{"start", "Begin containment protocols"},
 		{"status", "Check containment status"},
 		{"brief/briefing", "Re-display current level briefing"},
+		{"objectives", "Show the level's objective checklist"},
 		{"git init", "Initialize containment repository"},
 		{"git config <key> <value>", "Configure researcher identity"},
+		{"git config --list", "Show configuration with --show-scope"},
+		{"git config alias.<a> <c>", "Define a command shorthand"},
 		{"git add <file>", "Stage files for containment"},
+		{"git add -p [file]", "Review and stage individual hunks"},
+		{"git add -i", "Interactive staging menu"},
 		{"git commit -m \"<msg>\"", "Secure files in containment"},
 		{"git commit -a -m \"<msg>\"", "Commit all tracked changes"},
+		{"git commit", "Write a multi-line message in the editor"},
+		{"git commit --amend", "Rewrite the latest commit"},
+		{"git commit -s -m \"<msg>\"", "Commit with a Signed-off-by trailer"},
 		{"git status", "View repository status"},
 		{"git diff", "Show file modifications"},
+		{"git diff --cached", "Show staged modifications"},
 		{"git log", "View containment history"},
 		{"git log -p", "View history with changes"},
 		{"git show [commit]", "Examine specific commit"},
+		{"git notes add -m <note>", "Annotate a commit without rewriting"},
+		{"git stash [-m <message>]", "Set uncommitted work aside"},
+		{"git stash list/show", "List or inspect stashed work"},
+		{"git stash pop/apply/drop", "Restore or discard stashed work"},
+		{"git grep <pattern> [rev]", "Search files or past revisions"},
+		{"git shortlog -sn", "Count commits per researcher"},
+		{"git describe [--tags]", "Name a commit by nearest tag"},
+		{"git show-branch", "Compare branch histories"},
+		{"git tag [-a] <name>", "Designate a commit with a tag"},
 		{"git branch [name]", "Create or list containment branches"},
+		{"git branch -d/-D <name>", "Decommission a branch (-D if unmerged)"},
+		{"git branch -m [old] <new>", "Rename a containment branch"},
+		{"git branch -vv", "List branches with tips and tracking state"},
 		{"git merge <branch>", "Merge containment strategies"},
+		{"git merge --no-ff <b>", "Always record a merge commit"},
+		{"git merge --abort", "Abandon a conflicted merge"},
 		{"git checkout <branch>", "Switch containment branches"},
 		{"git switch <branch>", "Switch to existing branch"},
 		{"git switch -c <branch>", "Create and switch to new branch"},
+		{"git clean -n / -f", "Preview or purge untracked files"},
+		{"git format-patch -1", "Export commits as patch files"},
+		{"git am <patch>", "Commit patches from a mailbox"},
+		{"git am --continue", "Resume am after resolving (--skip/--abort)"},
+		{"git apply [--check] <p>", "Apply a patch to the files"},
+		{"git remote add <n> <url>", "Link a shared Foundation archive"},
+		{"git push [-u] [remote]", "Transmit commits to the archive"},
+		{"git fetch [remote]", "Retrieve archive records"},
+		{"git pull [remote]", "Fetch and merge archive records"},
+		{"git worktree add <path>", "Open a parallel containment site"},
+		{"git worktree list", "List containment sites"},
+		{"git worktree remove <p>", "Dismantle a containment site"},
+		{"git submodule add <url>", "Nest a sub-entity repository"},
+		{"git submodule update", "Check out recorded sub-entity commits"},
+		{"git -C <path> <command>", "Run a command inside a submodule"},
+		{"git sparse-checkout set", "Expose only chosen directories"},
+		{"git ls-tree -r <rev>", "List the files of a commit's tree"},
+		{"git reflog", "Show where HEAD has been"},
+		{"git fsck", "Find dangling commits and blobs"},
+		{"git gc [--prune=now]", "Pack objects, destroy unreachable ones"},
+		{"git count-objects -v", "Audit loose and packed objects"},
+		{"cd <path>", "Move to another containment site"},
+		{"edit .git/hooks/<hook>", "Write a pre-commit/commit-msg/pre-push hook"},
+		{"git hook run <hook>", "Test a hook against current work"},
+		{"edit <file> / cat <file>", "Edit or print a containment file"},
+		{"ls [-la] [path]", "List containment files and directories"},
+		{"echo text > file", "Write a line to a file (>> appends)"},
+		{"touch/rm/mv/mkdir", "Create, delete and move files"},
+		{"head/tail [-n N] <file>", "Print the start or end of a file"},
 		{"quit", "Exit containment protocols (progress saved)"},
 	}
 
@@ -199,6 +336,23 @@ func (t *Terminal) DisplayHelp() {
 	fmt.Println()
 }
 
+// DisplayEditor shows the line editor's header and numbered buffer
+func (t *Terminal) DisplayEditor(title, listing string) {
+	fmt.Println()
+	SCPWhite.Printf("──── EDITING %s ────\n", title)
+	SCPGray.Println(listing)
+	SCPGray.Println("p prints, r N <text> replaces, i N <text> inserts, a <text> appends, d N deletes, w saves, q quits (h for help)")
+}
+
+// DisplayEditorOutput shows the response to a line editor command
+func (t *Terminal) DisplayEditorOutput(output string) {
+	if strings.HasPrefix(output, "?") {
+		ErrorColor.Println(output)
+		return
+	}
+	SCPGray.Println(output)
+}
+
 // DisplayError shows an error message
 func (t *Terminal) DisplayError(message string) {
 	ErrorColor.Printf("ERROR: %s\n", message)
@@ -217,6 +371,18 @@ func (t *Terminal) ClearScreen() {
 
 // Helper functions
 
+// entityColor is the color of the entity's voice in a tone
+func entityColor(tone game.AwarenessTone) *color.Color {
+	switch tone {
+	case game.ToneHostile:
+		return EntityHostile
+	case game.ToneCurious:
+		return EntityCurious
+	default:
+		return EntityCryptic
+	}
+}
+
 func getStatusColor(status string) *color.Color {
 	switch status {
 	case "SECURE":